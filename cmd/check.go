@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joyme123/thrift-ls/lsp"
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/diagnostic"
+	"github.com/joyme123/thrift-ls/lsp/mapper"
+	"github.com/joyme123/thrift-ls/lsp/memoize"
+	"github.com/joyme123/thrift-ls/lsp/types"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// output formats of check command
+const (
+	OutputText  = "text"
+	OutputJSON  = "json"
+	OutputSARIF = "sarif"
+)
+
+// Problem is a diagnostic reported by check command. Line and column are 1-based, column is counted
+// in bytes
+type Problem struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	Severity  string `json:"severity"`
+	Rule      string `json:"rule"`
+	Message   string `json:"message"`
+
+	severity protocol.DiagnosticSeverity
+}
+
+// RunCheck runs `thriftls check [flags] [path ...]` and returns exit code. All diagnostics of editor are
+// run over thrift files under paths. Exit code is 1 if any problem is as severe as -fail-on, and 2 if check
+// can't be done
+func RunCheck(args []string, stdout, stderr io.Writer) int {
+	var output, failOn string
+	var includeDirs stringsFlag
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&output, "format", OutputText, "output format: text, json or sarif")
+	flags.StringVar(&failOn, "fail-on", "error", "exit with 1 if any problem is as severe as it: error, warning, information or hint")
+	flags.Var(&includeDirs, "I", "add a directory to search for included files, can be repeated")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: thriftls check [flags] [path ...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	threshold, ok := diagnostic.ParseSeverity(failOn)
+	if !ok {
+		fmt.Fprintf(stderr, "invalid severity: %s\n", failOn)
+		return 2
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files := make([]string, 0)
+	for _, path := range paths {
+		res, err := thriftFiles(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		files = append(files, res...)
+	}
+
+	problems, err := check(context.Background(), files, includeDirs)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	switch output {
+	case OutputText:
+		for _, p := range problems {
+			fmt.Fprintf(stdout, "%s:%d:%d: %s: %s\n", p.File, p.Line, p.Column, p.Severity, p.Message)
+		}
+	case OutputJSON:
+		err = writeJSON(stdout, problems)
+	case OutputSARIF:
+		err = writeJSON(stdout, toSARIF(problems))
+	default:
+		err = fmt.Errorf("invalid output format: %s", output)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	for _, p := range problems {
+		// smaller value is more severe
+		if p.severity <= threshold {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// stringsFlag is a flag which can be repeated
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// check builds snapshots over files without lsp client, and runs diagnostics on them. files are checked with
+// the nearest project config of them like editors, and files without project config are checked in working
+// directory. include dirs are searched after include dirs in project config
+func check(ctx context.Context, files []string, includeDirs []string) ([]Problem, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	absIncludeDirs := make([]string, 0, len(includeDirs))
+	for _, dir := range includeDirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		absIncludeDirs = append(absIncludeDirs, abs)
+	}
+
+	// files are grouped by folders of their project config. folder is empty if project config isn't found
+	folders := make([]string, 0)
+	projectFiles := make(map[string][]string)
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, err
+		}
+		folder, _ := lsp.FindProjectFolder(filepath.Dir(abs))
+		if _, ok := projectFiles[folder]; !ok {
+			folders = append(folders, folder)
+		}
+		projectFiles[folder] = append(projectFiles[folder], abs)
+	}
+
+	problems := make([]Problem, 0)
+	for _, folder := range folders {
+		res, err := checkProject(ctx, wd, folder, projectFiles[folder], absIncludeDirs)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, res...)
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].File != problems[j].File {
+			return problems[i].File < problems[j].File
+		}
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+
+	// the same problem may be found from different files, such as cycle include
+	res := make([]Problem, 0, len(problems))
+	for i := range problems {
+		if i > 0 && problems[i] == problems[i-1] {
+			continue
+		}
+		res = append(res, problems[i])
+	}
+
+	return res, nil
+}
+
+// checkProject runs diagnostics on files of project in folder with its project config. files are checked in
+// working directory without project config if folder is empty
+func checkProject(ctx context.Context, wd string, folder string, files []string, includeDirs []string) ([]Problem, error) {
+	cfg := &lsp.ProjectConfig{}
+	if folder == "" {
+		folder = wd
+	} else if err := lsp.LoadProjectConfig(uri.File(folder), cfg); err != nil {
+		return nil, fmt.Errorf("load project config of %s failed: %w", folder, err)
+	}
+	rules, err := cfg.DiagnosticRules()
+	if err != nil {
+		return nil, fmt.Errorf("invalid project config of %s: %w", folder, err)
+	}
+	opts, err := cfg.ViewOptions(folder, includeDirs...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project config of %s: %w", folder, err)
+	}
+
+	store := &memoize.Store{}
+	view := cache.NewView("check", uri.File(folder), opts, cache.New(store), store)
+	ss, release := view.Snapshot()
+	defer release()
+
+	uris := make([]uri.URI, 0, len(files))
+	for _, file := range files {
+		if cfg.Excluded(folder, file) {
+			continue
+		}
+		uris = append(uris, uri.File(file))
+	}
+
+	problems := make([]Problem, 0)
+	for _, checker := range diagnostic.Checkers() {
+		// checkers are run one by one, so problems are reported with names of their rules
+		res, err := diagnostic.NewDiagnosticWithRules(checkerRules(rules, checker.Name())).Diagnostic(ctx, ss, uris)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", checker.Name(), err)
+		}
+		for fileURI, items := range res {
+			for _, item := range items {
+				problems = append(problems, toProblem(ctx, ss, wd, fileURI, checker.Name(), item))
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+// checkerRules returns rules which only run checker of name with its rule
+func checkerRules(rules map[string]diagnostic.Rule, name string) map[string]diagnostic.Rule {
+	res := make(map[string]diagnostic.Rule)
+	for _, checker := range diagnostic.Checkers() {
+		res[checker.Name()] = diagnostic.Rule{Disabled: true}
+	}
+	res[name] = rules[name]
+
+	return res
+}
+
+func toProblem(ctx context.Context, ss *cache.Snapshot, wd string, fileURI uri.URI, rule string, item protocol.Diagnostic) Problem {
+	file := fileURI.Filename()
+	if rel, err := filepath.Rel(wd, file); err == nil {
+		file = rel
+	}
+
+	// severity isn't set means error
+	severity := item.Severity
+	if severity == 0 {
+		severity = protocol.DiagnosticSeverityError
+	}
+
+	var mp *mapper.Mapper
+	if fh, err := ss.ReadFile(ctx, fileURI); err == nil {
+		if content, err := fh.Content(); err == nil {
+			mp = mapper.NewMapper(fileURI, content)
+		}
+	}
+	column := func(pos protocol.Position) int {
+		if mp != nil {
+			lineStart, err1 := mp.LSPPosToOffset(types.Position{Line: pos.Line})
+			offset, err2 := mp.LSPPosToOffset(types.Position{Line: pos.Line, Character: pos.Character})
+			if err1 == nil && err2 == nil {
+				return offset - lineStart + 1
+			}
+		}
+		return int(pos.Character) + 1
+	}
+
+	return Problem{
+		File:      file,
+		Line:      int(item.Range.Start.Line) + 1,
+		Column:    column(item.Range.Start),
+		EndLine:   int(item.Range.End.Line) + 1,
+		EndColumn: column(item.Range.End),
+		Severity:  diagnostic.SeverityName(severity),
+		Rule:      rule,
+		Message:   item.Message,
+		severity:  severity,
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+	}{
+		{
+			name:     "text",
+			args:     []string{"idl"},
+			wantCode: 1,
+			wantStdout: `idl/a.thrift:1:1: warning: cycle dependency in file://{dir}/idl/b.thrift
+idl/a.thrift:3:3: error: field id conflict
+idl/a.thrift:4:3: error: field id conflict
+idl/a.thrift:4:6: error: field type doesn't exist
+idl/b.thrift:1:1: warning: cycle dependency in file://{dir}/idl/a.thrift
+`,
+		},
+		{
+			name:     "fail on warning",
+			args:     []string{"-fail-on", "warning", "idl/b.thrift"},
+			wantCode: 1,
+			wantStdout: `idl/a.thrift:1:1: warning: cycle dependency in file://{dir}/idl/b.thrift
+idl/b.thrift:1:1: warning: cycle dependency in file://{dir}/idl/a.thrift
+`,
+		},
+		{
+			name: "warnings are not failures by default",
+			args: []string{"idl/b.thrift"},
+			wantStdout: `idl/a.thrift:1:1: warning: cycle dependency in file://{dir}/idl/b.thrift
+idl/b.thrift:1:1: warning: cycle dependency in file://{dir}/idl/a.thrift
+`,
+		},
+		{
+			name:     "invalid severity",
+			args:     []string{"-fail-on", "fatal", "idl"},
+			wantCode: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupCheckDir(t)
+
+			stdout, stderr := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+			code := RunCheck(tt.args, stdout, stderr)
+			assert.Equal(t, tt.wantCode, code, stderr.String())
+			assert.Equal(t, replaceDir(tt.wantStdout, dir), stdout.String())
+		})
+	}
+}
+
+func TestRunCheckSARIF(t *testing.T) {
+	setupCheckDir(t)
+
+	stdout, stderr := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	code := RunCheck([]string{"-format", "sarif", "idl/a.thrift"}, stdout, stderr)
+	assert.Equal(t, 1, code, stderr.String())
+
+	log := &sarifLog{}
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), log))
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Len(t, log.Runs, 1)
+	assert.Equal(t, []sarifRule{{ID: "CycleCheck"}, {ID: "FieldIDCheck"}, {ID: "SemanticAnalysis"}}, log.Runs[0].Tool.Driver.Rules)
+	assert.Len(t, log.Runs[0].Results, 5)
+	assert.Equal(t, sarifResult{
+		RuleID:  "SemanticAnalysis",
+		Level:   "error",
+		Message: sarifMessage{Text: "field type doesn't exist"},
+		Locations: []sarifLocation{
+			{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: "idl/a.thrift"},
+					Region:           sarifRegion{StartLine: 4, StartColumn: 6, EndLine: 4, EndColumn: 14},
+				},
+			},
+		},
+	}, log.Runs[0].Results[3])
+}
+
+func TestRunCheckIncludeDirs(t *testing.T) {
+	dir := setupCheckDir(t)
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "common"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "common", "base.thrift"), []byte("struct Base {}\n"), 0644))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "svc"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "svc", "c.thrift"), []byte(`include "base.thrift"
+struct C {
+  1: base.Base b
+}
+`), 0644))
+
+	stdout, stderr := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	code := RunCheck([]string{"svc"}, stdout, stderr)
+	assert.Equal(t, 1, code, stderr.String())
+	assert.Equal(t, "svc/c.thrift:3:6: error: field type doesn't exist\n", stdout.String())
+
+	// included file is found in include dir
+	stdout, stderr = bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	code = RunCheck([]string{"-I", "idl", "-I", "common", "svc"}, stdout, stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Empty(t, stdout.String())
+}
+
+func TestRunCheckProjectConfig(t *testing.T) {
+	dir := setupCheckDir(t)
+	config := `rules:
+  CycleCheck:
+    disabled: true
+  FieldIDCheck:
+    severity: warning
+exclude:
+  - "**/gen"
+includeDirs:
+  - common
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".thriftls.yaml"), []byte(config), 0644))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "common"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "common", "base.thrift"), []byte("struct Base {}\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "idl", "c.thrift"), []byte(`include "base.thrift"
+struct C {
+  1: base.Base b
+}
+`), 0644))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "idl", "gen"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "idl", "gen", "gen.thrift"), []byte("struct Gen {\n  1: Unknown u\n}\n"), 0644))
+
+	// config is found in parent of working directory
+	assert.NoError(t, os.Chdir(filepath.Join(dir, "idl")))
+	stdout, stderr := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	code := RunCheck([]string{"gen/gen.thrift"}, stdout, stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Empty(t, stdout.String())
+
+	stdout, stderr = bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	code = RunCheck(nil, stdout, stderr)
+	assert.Equal(t, 1, code, stderr.String())
+	assert.Equal(t, `a.thrift:3:3: warning: field id conflict
+a.thrift:4:3: warning: field id conflict
+a.thrift:4:6: error: field type doesn't exist
+`, stdout.String())
+
+	// invalid config can't be applied
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".thriftls.yaml"), []byte("dialect: proto\n"), 0644))
+	stdout, stderr = bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	code = RunCheck(nil, stdout, stderr)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr.String(), "unknown dialect proto")
+}
+
+func TestRunCheckProjectConfigOfPath(t *testing.T) {
+	dir := setupCheckDir(t)
+	other, err := filepath.EvalSymlinks(t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(other, ".thriftls.yaml"), []byte(`rules:
+  FieldIDCheck:
+    severity: warning
+`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(other, "o.thrift"), []byte(`struct O {
+  1: string a
+  1: string b
+}
+`), 0644))
+	rel, err := filepath.Rel(dir, filepath.Join(other, "o.thrift"))
+	assert.NoError(t, err)
+
+	// config of checked path is applied instead of config of working directory
+	stdout, stderr := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	code := RunCheck([]string{"-fail-on", "error", other}, stdout, stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, rel+":2:3: warning: field id conflict\n"+rel+":3:3: warning: field id conflict\n", stdout.String())
+
+	// files of each path are checked with their own config
+	stdout, stderr = bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	code = RunCheck([]string{"idl/a.thrift", other}, stdout, stderr)
+	assert.Equal(t, 1, code, stderr.String())
+	assert.Contains(t, stdout.String(), rel+":2:3: warning: field id conflict\n")
+	assert.Contains(t, stdout.String(), "idl/a.thrift:3:3: error: field id conflict\n")
+}
+
+// setupCheckDir creates thrift files with problems in a temp dir, and changes working dir to it
+func setupCheckDir(t *testing.T) string {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "idl"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "idl", "a.thrift"), []byte(`include "b.thrift"
+struct A {
+  1: string a
+  1: Unknown b
+}
+`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "idl", "b.thrift"), []byte(`include "a.thrift"
+struct B {}
+`), 0644))
+
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+
+	return dir
+}
+
+func replaceDir(s string, dir string) string {
+	return string(bytes.ReplaceAll([]byte(s), []byte("{dir}"), []byte(dir)))
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joyme123/thrift-ls/format"
+	"github.com/joyme123/thrift-ls/lsp"
+	"github.com/joyme123/thrift-ls/parser"
+	"go.lsp.dev/uri"
+)
+
+const stdinName = "<standard input>"
+
+type formatOptions struct {
+	write bool
+	diff  bool
+	list  bool
+}
+
+// RunFormat runs `thriftls format [flags] [path ...]` and returns exit code. Directories are walked for
+// thrift files, and stdin is formatted if no path is given. Exit code is 1 if -l finds unformatted files,
+// and 2 if any file can't be read or formatted
+func RunFormat(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	opts := &formatOptions{}
+	flags := flag.NewFlagSet("format", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.BoolVar(&opts.write, "w", false, "write result to source file instead of stdout")
+	flags.BoolVar(&opts.diff, "d", false, "display diffs instead of rewriting files")
+	flags.BoolVar(&opts.list, "l", false, "list files whose formatting differs from thriftls's and exit with 1")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: thriftls format [flags] [path ...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	exitCode := 0
+	report := func(err error) {
+		fmt.Fprintln(stderr, err)
+		exitCode = 2
+	}
+
+	if flags.NArg() == 0 {
+		if opts.write {
+			report(errors.New("cannot use -w with standard input"))
+			return exitCode
+		}
+		content, err := io.ReadAll(stdin)
+		if err != nil {
+			report(err)
+			return exitCode
+		}
+		changed, err := formatContent(stdinName, content, nil, opts, stdout)
+		if err != nil {
+			report(err)
+		} else if changed && opts.list {
+			exitCode = 1
+		}
+		return exitCode
+	}
+
+	for _, path := range flags.Args() {
+		files, err := thriftFiles(path)
+		if err != nil {
+			report(err)
+			continue
+		}
+		for _, file := range files {
+			changed, err := formatFile(file, opts, stdout)
+			if err != nil {
+				report(err)
+				continue
+			}
+			if changed && opts.list && exitCode == 0 {
+				exitCode = 1
+			}
+		}
+	}
+
+	return exitCode
+}
+
+// thriftFiles returns path itself if it's a file, or thrift files under path in lexical order
+func thriftFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	files := make([]string, 0)
+	err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(file, ".thrift") {
+			files = append(files, file)
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+func formatFile(file string, opts *formatOptions, stdout io.Writer) (bool, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+
+	write := func(formatted []byte) error {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(file, formatted, info.Mode().Perm())
+	}
+
+	return formatContent(file, content, write, opts, stdout)
+}
+
+// formatContent formats content of file and outputs result according to options. write is used to
+// rewrite source file with -w. It returns whether content is changed by formatter
+func formatContent(file string, content []byte, write func([]byte) error, opts *formatOptions, stdout io.Writer) (bool, error) {
+	ast, err := parser.Parse(file, content)
+	doc, ok := ast.(*parser.Document)
+	if !ok {
+		return false, err
+	}
+	// nodes with syntax error are kept as they are, other nodes are still formatted like editors do
+	if err != nil && !doc.ChildrenBadNode() {
+		return false, err
+	}
+
+	formatOpts, err := projectFormatOptions(file)
+	if err != nil {
+		return false, err
+	}
+	formatted, err := format.FormatDocumentWithOptions(doc, content, formatOpts)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", file, err)
+	}
+
+	changed := !bytes.Equal(content, []byte(formatted))
+	if changed {
+		if opts.list {
+			fmt.Fprintln(stdout, file)
+		}
+		if opts.write {
+			if err := write([]byte(formatted)); err != nil {
+				return changed, err
+			}
+		}
+		if opts.diff {
+			fmt.Fprint(stdout, format.UnifiedDiff(file+".orig", file, string(content), formatted))
+		}
+	}
+
+	if !opts.list && !opts.write && !opts.diff {
+		fmt.Fprint(stdout, formatted)
+	}
+
+	return changed, nil
+}
+
+// projectFormatOptions returns format options in the nearest project config file of file, like editors use
+// the config file at the root of workspace folder. standard input uses project config of working directory
+func projectFormatOptions(file string) (format.Options, error) {
+	dir := "."
+	if file != stdinName {
+		dir = filepath.Dir(file)
+	}
+
+	cfg := &lsp.ProjectConfig{Format: format.DefaultOptions()}
+	folder, ok := lsp.FindProjectFolder(dir)
+	if !ok {
+		return cfg.Format, nil
+	}
+	if err := lsp.LoadProjectConfig(uri.File(folder), cfg); err != nil {
+		return cfg.Format, fmt.Errorf("load project config of %s failed: %w", folder, err)
+	}
+
+	return cfg.Format, nil
+}
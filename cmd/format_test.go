@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	unformatted = "struct User {\n  1:   string name\n}\n"
+	formatted   = "struct User {\n    1: string name\n}\n"
+)
+
+func TestRunFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantCode   int
+		wantStdout string
+		wantFile   string
+	}{
+		{
+			name:       "print formatted",
+			args:       []string{"idl/a.thrift"},
+			wantStdout: formatted,
+			wantFile:   unformatted,
+		},
+		{
+			name:       "list unformatted files",
+			args:       []string{"-l", "idl"},
+			wantCode:   1,
+			wantStdout: "idl/a.thrift\n",
+			wantFile:   unformatted,
+		},
+		{
+			name:     "write",
+			args:     []string{"-w", "idl/a.thrift"},
+			wantFile: formatted,
+		},
+		{
+			name: "diff",
+			args: []string{"-d", "idl/a.thrift"},
+			wantStdout: `--- idl/a.thrift.orig
++++ idl/a.thrift
+@@ -1,3 +1,3 @@
+ struct User {
+-  1:   string name
++    1: string name
+ }
+`,
+			wantFile: unformatted,
+		},
+		{
+			name:       "stdin",
+			args:       []string{},
+			stdin:      unformatted,
+			wantStdout: formatted,
+			wantFile:   unformatted,
+		},
+		{
+			name:       "syntax error is kept",
+			args:       []string{"-l", "b.thrift"},
+			wantCode:   0,
+			wantStdout: "",
+			wantFile:   unformatted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			assert.NoError(t, os.Mkdir(filepath.Join(dir, "idl"), 0755))
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "idl", "a.thrift"), []byte(unformatted), 0644))
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "idl", "c.thrift"), []byte(formatted), 0644))
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.thrift"), []byte("strct A {}\n"), 0644))
+			wd, err := os.Getwd()
+			assert.NoError(t, err)
+			assert.NoError(t, os.Chdir(dir))
+			defer os.Chdir(wd)
+
+			stdout, stderr := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+			code := RunFormat(tt.args, strings.NewReader(tt.stdin), stdout, stderr)
+			assert.Equal(t, tt.wantCode, code, stderr.String())
+			assert.Equal(t, tt.wantStdout, stdout.String())
+
+			content, err := os.ReadFile(filepath.Join(dir, "idl", "a.thrift"))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantFile, string(content))
+		})
+	}
+}
+
+func TestRunFormatProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	config := "format:\n  useTabs: true\n  fieldSeparator: comma\n"
+	content := "struct User {\n  1:   string name\n  2: i64   id\n}\n\nstrct Bad {\n  1:   string a\n}\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".thriftls.yaml"), []byte(config), 0644))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "idl"), 0755))
+	file := filepath.Join(dir, "idl", "a.thrift")
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+
+	// options in the nearest config file are used, and definitions with syntax error are kept
+	stdout, stderr := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	code := RunFormat([]string{"-w", file}, strings.NewReader(""), stdout, stderr)
+	assert.Equal(t, 0, code, stderr.String())
+
+	got, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "struct User {\n\t1: string name,\n\t2: i64    id,\n}\n\nstrct Bad {\n  1:   string a\n}\n", string(got))
+
+	// invalid config file is reported
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".thriftls.yaml"), []byte("format: [\n"), 0644))
+	code = RunFormat([]string{"-l", file}, strings.NewReader(""), stdout, stderr)
+	assert.Equal(t, 2, code)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// sarif log of version 2.1.0, only properties used by check command are defined.
+// see https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// sarifLevels maps severity to sarif level. sarif has no level for hint, so it's reported as note
+var sarifLevels = map[string]string{
+	"error":       "error",
+	"warning":     "warning",
+	"information": "note",
+	"hint":        "note",
+}
+
+func toSARIF(problems []Problem) *sarifLog {
+	rules := make(map[string]struct{})
+	results := make([]sarifResult, 0, len(problems))
+	for _, p := range problems {
+		rules[p.Rule] = struct{}{}
+		results = append(results, sarifResult{
+			RuleID:  p.Rule,
+			Level:   sarifLevels[p.Severity],
+			Message: sarifMessage{Text: p.Message},
+			Locations: []sarifLocation{
+				{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(p.File)},
+						Region: sarifRegion{
+							StartLine:   p.Line,
+							StartColumn: p.Column,
+							EndLine:     p.EndLine,
+							EndColumn:   p.EndColumn,
+						},
+					},
+				},
+			},
+		})
+	}
+
+	driver := sarifDriver{
+		Name:           "thriftls",
+		InformationURI: "https://github.com/joyme123/thrift-ls",
+		Rules:          make([]sarifRule, 0, len(rules)),
+	}
+	for rule := range rules {
+		driver.Rules = append(driver.Rules, sarifRule{ID: rule})
+	}
+	sort.Slice(driver.Rules, func(i, j int) bool {
+		return driver.Rules[i].ID < driver.Rules[j].ID
+	})
+
+	return &sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool:    sarifTool{Driver: driver},
+				Results: results,
+			},
+		},
+	}
+}
@@ -3,17 +3,18 @@ package format
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/joyme123/thrift-ls/parser"
 )
 
-func MustFormatAnnotations(annotations *parser.Annotations) string {
+func (p *printer) formatAnnotations(annotations *parser.Annotations) string {
 	buf := bytes.NewBuffer(nil)
 
 	buf.WriteString(MustFormatKeyword(annotations.LParKeyword.Keyword))
 
 	for i, anno := range annotations.Annotations {
-		buf.WriteString(MustFormatAnnotation(anno, i == len(annotations.Annotations)-1))
+		buf.WriteString(p.formatAnnotation(anno, i == len(annotations.Annotations)-1))
 	}
 
 	buf.WriteString(MustFormatKeyword(annotations.RParKeyword.Keyword))
@@ -21,7 +22,7 @@ func MustFormatAnnotations(annotations *parser.Annotations) string {
 	return buf.String()
 }
 
-func MustFormatAnnotation(anno *parser.Annotation, isLast bool) string {
+func (p *printer) formatAnnotation(anno *parser.Annotation, isLast bool) string {
 	sep := ""
 	if !isLast {
 		sep = MustFormatKeyword(anno.ListSeparatorKeyword.Keyword)
@@ -33,5 +34,41 @@ func MustFormatAnnotation(anno *parser.Annotation, isLast bool) string {
 	}
 
 	// a = "xxxx",
-	return fmt.Sprintf("%s %s %s%s%s", MustFormatIdentifier(anno.Identifier), MustFormatKeyword(anno.EqualKeyword.Keyword), MustFormatLiteral(anno.Value), sep, space)
+	return fmt.Sprintf("%s %s %s%s%s", MustFormatIdentifier(anno.Identifier), MustFormatKeyword(anno.EqualKeyword.Keyword), p.formatLiteral(anno.Value), sep, space)
+}
+
+// wrapAnnotations puts each annotation on its own line if the line containing one line annotations
+// annos in text is longer than max line width
+func (p *printer) wrapAnnotations(text string, annos string, annotations *parser.Annotations, indent string) string {
+	if p.opts.MaxLineWidth <= 0 || annos == "" || annotations == nil || len(annotations.Annotations) == 0 {
+		return text
+	}
+
+	idx := strings.LastIndex(text, annos)
+	if idx == -1 {
+		return text
+	}
+	lineStart := strings.LastIndexByte(text[:idx], '\n') + 1
+	lineEnd := strings.IndexByte(text[idx:], '\n')
+	if lineEnd == -1 {
+		lineEnd = len(text)
+	} else {
+		lineEnd += idx
+	}
+	if p.lineWidth(text[lineStart:lineEnd]) <= p.opts.MaxLineWidth {
+		return text
+	}
+
+	buf := bytes.NewBufferString(" ")
+	buf.WriteString(MustFormatKeyword(annotations.LParKeyword.Keyword))
+	buf.WriteString("\n")
+	for i, anno := range annotations.Annotations {
+		buf.WriteString(indent + p.indent())
+		buf.WriteString(strings.TrimRight(p.formatAnnotation(anno, i == len(annotations.Annotations)-1), " "))
+		buf.WriteString("\n")
+	}
+	buf.WriteString(indent)
+	buf.WriteString(MustFormatKeyword(annotations.RParKeyword.Keyword))
+
+	return text[:idx] + buf.String() + text[idx+len(annos):]
 }
@@ -0,0 +1,92 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/joyme123/thrift-ls/parser"
+	"github.com/joyme123/thrift-ls/utils"
+)
+
+// hasSyntaxError reports whether node or its children contain syntax error
+func hasSyntaxError(node parser.Node) bool {
+	if node == nil || utils.IsNil(node) {
+		return false
+	}
+	return node.IsBadNode() || node.ChildrenBadNode()
+}
+
+// keepAsIs reports whether top level node should be printed as its original text. closed struct, union,
+// exception, enum and service are formatted if only their fields, values or functions contain syntax
+// error, and these members are printed as they are
+func keepAsIs(node parser.Node) bool {
+	if !hasSyntaxError(node) {
+		return false
+	}
+	if node.IsBadNode() {
+		return true
+	}
+
+	// definition isn't closed, its body may be mixed with following definitions. missing '}' is
+	// recovered as an empty literal by parser
+	rCur := rCurLiteral(node)
+	if rCur == nil || rCur.Pos().Offset == rCur.End().Offset {
+		return true
+	}
+
+	for _, child := range node.Children() {
+		switch child.(type) {
+		case *parser.Field, *parser.EnumValue, *parser.Function:
+			continue
+		}
+		if hasSyntaxError(child) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keepAsIs reports whether top level node is printed as its original text. original text is unknown without
+// content, then nodes with syntax error are formatted as they are parsed
+func (p *printer) keepAsIs(node parser.Node) bool {
+	return p.content != nil && keepAsIs(node)
+}
+
+// keepMember reports whether field, enum value or function is printed as its original text
+func (p *printer) keepMember(node parser.Node) bool {
+	return p.content != nil && hasSyntaxError(node)
+}
+
+// source returns original text of node without surrounding spaces. if node is the first one of its line,
+// indent of line is kept when keepIndent is true
+func (p *printer) source(node parser.Node, keepIndent bool) string {
+	if p.content == nil {
+		return ""
+	}
+
+	start, end := trimSpan(p.content, node.Pos().Offset, node.End().Offset)
+	if keepIndent {
+		lineStart := bytes.LastIndexByte(p.content[:start], '\n') + 1
+		if strings.TrimSpace(string(p.content[lineStart:start])) == "" {
+			start = lineStart
+		}
+	}
+
+	return string(p.content[start:end])
+}
+
+// hasEmptyLineBetween reports whether there are empty lines between preNode and curNode in original text
+func (p *printer) hasEmptyLineBetween(preNode, curNode parser.Node) bool {
+	if p.content == nil {
+		return false
+	}
+
+	_, preEnd := trimSpan(p.content, preNode.Pos().Offset, preNode.End().Offset)
+	curStart, _ := trimSpan(p.content, curNode.Pos().Offset, curNode.End().Offset)
+	if preEnd >= curStart {
+		return false
+	}
+
+	return strings.Count(string(p.content[preEnd:curStart]), "\n") > 1
+}
@@ -0,0 +1,82 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/joyme123/thrift-ls/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDocumentWithBadNode(t *testing.T) {
+	content := `incl "b.thrift"
+include   "a.thrift"
+
+struct User {
+  1:   string name,
+  2: i64
+  3:  i32   age
+}
+
+enum Status {
+  OK = ,
+  FAILED   = 2
+}
+
+service Api {
+  void   ping(1: )
+  User   get(1:i64 id)
+}
+
+strct Bad {
+  1: string a
+}
+
+const   i32   Max   =   1
+`
+
+	ast, _ := parser.Parse("test.thrift", []byte(content))
+	doc, ok := ast.(*parser.Document)
+	assert.True(t, ok)
+
+	got, err := FormatDocumentWithOptions(doc, []byte(content), DefaultOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, `incl "b.thrift"
+include "a.thrift" 
+
+struct User {
+    1: string name,
+    2: i64
+    3: i32 age
+}
+
+enum Status {
+    OK = ,
+    FAILED = 2
+}
+
+service Api {
+    void   ping(1: )
+    User get(1: i64 id)
+}
+
+strct Bad {
+  1: string a
+}
+
+const i32 Max = 1
+`, got)
+
+	// nodes with syntax error can't be kept without content
+	_, err = FormatDocument(doc)
+	assert.ErrorIs(t, err, BadNodeError)
+	_, err = FormatDocumentWithOptions(doc, nil, DefaultOptions())
+	assert.ErrorIs(t, err, BadNodeError)
+
+	// members with syntax error of node are kept
+	assert.Equal(t, `struct User {
+    1: string name,
+    2: i64
+    3: i32 age
+}
+`, FormatNodeWithOptions(doc.Structs[0], []byte(content), DefaultOptions()))
+}
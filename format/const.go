@@ -19,8 +19,8 @@ type ConstFormatter struct {
 	EndLineComments string
 }
 
-func MustFormatConst(cst *parser.Const) string {
-	comments, annos := formatCommentsAndAnnos(cst.Comments, cst.Annotations, "")
+func (p *printer) formatConst(cst *parser.Const) string {
+	comments, annos := p.formatCommentsAndAnnos(cst.Comments, cst.Annotations, "")
 	if len(cst.Comments) > 0 && lineDistance(cst.Comments[len(cst.Comments)-1], cst.ConstKeyword) > 1 {
 		comments = comments + "\n"
 	}
@@ -33,14 +33,14 @@ func MustFormatConst(cst *parser.Const) string {
 	f := &ConstFormatter{
 		Comments:        comments,
 		Const:           MustFormatKeyword(cst.ConstKeyword.Keyword),
-		Type:            MustFormatFieldType(cst.ConstType),
+		Type:            p.formatFieldType(cst.ConstType),
 		Name:            MustFormatIdentifier(cst.Name),
 		Annotations:     annos,
 		Equal:           MustFormatKeyword(cst.EqualKeyword.Keyword),
-		Value:           MustFormatConstValue(cst.Value),
+		Value:           p.formatConstValue(cst.Value),
 		ListSeparator:   sep,
 		EndLineComments: MustFormatEndLineComments(cst.EndLineComments, ""),
 	}
 
-	return MustFormat(constOneLineTpl, f)
+	return p.wrapAnnotations(MustFormat(constOneLineTpl, f), annos, cst.Annotations, "")
 }
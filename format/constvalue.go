@@ -7,10 +7,10 @@ import (
 	"github.com/joyme123/thrift-ls/parser"
 )
 
-func MustFormatConstValue(cv *parser.ConstValue) string {
+func (p *printer) formatConstValue(cv *parser.ConstValue) string {
 	buf := bytes.NewBuffer(nil)
 	if cv.Comments != nil {
-		buf.WriteString(MustFormatComments(cv.Comments, Indent))
+		buf.WriteString(MustFormatComments(cv.Comments, p.indent()))
 	}
 	sep := ""
 	if cv.ListSeparatorKeyword != nil {
@@ -22,31 +22,38 @@ func MustFormatConstValue(cv *parser.ConstValue) string {
 		values := cv.Value.([]*parser.ConstValue)
 		buf.WriteString(MustFormatKeyword(cv.LBrkKeyword.Keyword))
 		for i := range values {
-			buf.WriteString(MustFormatConstValue(values[i]))
+			buf.WriteString(p.formatConstValue(values[i]))
 		}
 		buf.WriteString(MustFormatKeyword(cv.RBrkKeyword.Keyword))
 	case "map":
 		values := cv.Value.([]*parser.ConstValue)
 		buf.WriteString(MustFormatKeyword(cv.LCurKeyword.Keyword))
 		for i := range values {
-			buf.WriteString(MustFormatConstValue(values[i]))
+			buf.WriteString(p.formatConstValue(values[i]))
 		}
 		buf.WriteString(MustFormatKeyword(cv.RCurKeyword.Keyword))
 	case "pair":
 		key := cv.Key.(*parser.ConstValue)
 		value := cv.Value.(*parser.ConstValue)
 
-		buf.WriteString(fmt.Sprintf("%s%s %s%s", MustFormatConstValue(key), MustFormatKeyword(cv.ColonKeyword.Keyword), MustFormatConstValue(value), sep))
+		buf.WriteString(fmt.Sprintf("%s%s %s%s", p.formatConstValue(key), MustFormatKeyword(cv.ColonKeyword.Keyword), p.formatConstValue(value), sep))
 	case "identifier":
 		buf.WriteString(fmt.Sprintf("%s%s", cv.Value.(string), sep))
 	case "string":
-		val := ""
-		if _, ok := cv.Value.(string); ok {
-			val = cv.Value.(string)
-		} else {
-			val = cv.Value.(*parser.Literal).Value.Text
+		quote, text := "\"", ""
+		if literal, ok := cv.Value.(*parser.Literal); ok {
+			quote, text = literal.Quote, literal.Value.Text
+		} else if val, ok := cv.Value.(string); ok {
+			text = val
 		}
-		buf.WriteString(fmt.Sprintf("%q%s", val, sep))
+		target := quote
+		switch p.opts.QuoteStyle {
+		case QuoteStyleDouble:
+			target = "\""
+		case QuoteStyleSingle:
+			target = "'"
+		}
+		buf.WriteString(requote(text, quote, target) + sep)
 	case "i64":
 		buf.WriteString(fmt.Sprintf("%s%s", cv.ValueInText, sep))
 	case "double":
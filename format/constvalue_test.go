@@ -195,3 +195,48 @@ func TestMustFormatConstValue(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatConstStringQuote(t *testing.T) {
+	content := `const string A = 'it\'s "ok"'
+const string B = "say \"hi\" it's"
+const string C = 'path\n'
+`
+	tests := []struct {
+		quoteStyle string
+		want       string
+	}{
+		{
+			quoteStyle: QuoteStylePreserve,
+			want: `const string A = 'it\'s "ok"'
+const string B = "say \"hi\" it's"
+const string C = 'path\n'
+`,
+		},
+		{
+			quoteStyle: QuoteStyleDouble,
+			want: `const string A = "it's \"ok\""
+const string B = "say \"hi\" it's"
+const string C = "path\n"
+`,
+		},
+		{
+			quoteStyle: QuoteStyleSingle,
+			want: `const string A = 'it\'s "ok"'
+const string B = 'say "hi" it\'s'
+const string C = 'path\n'
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.quoteStyle, func(t *testing.T) {
+			ast, err := parser.Parse("test.thrift", []byte(content))
+			assert.NoError(t, err)
+
+			opts := DefaultOptions()
+			opts.QuoteStyle = tt.quoteStyle
+			got, err := FormatDocumentWithOptions(ast.(*parser.Document), []byte(content), opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// maxDiffDistance limits the edit distance computed by line diff. if documents differ more than it,
+// all different lines are replaced by one edit
+const maxDiffDistance = 2000
+
+// DiffEdits returns edits which transform oldText to newText. Texts are compared by lines, then common
+// prefix and suffix of each changed hunk are excluded from edit
+func DiffEdits(oldText, newText string) []TextEdit {
+	a := strings.SplitAfter(oldText, "\n")
+	b := strings.SplitAfter(newText, "\n")
+
+	// offsets[i] is the byte offset of line i in oldText
+	offsets := make([]int, len(a)+1)
+	for i := range a {
+		offsets[i+1] = offsets[i] + len(a[i])
+	}
+
+	edits := make([]TextEdit, 0)
+	add := func(i1, i2, j1, j2 int) {
+		edit, ok := minimalEdit(strings.Join(a[i1:i2], ""), strings.Join(b[j1:j2], ""))
+		if !ok {
+			return
+		}
+		edit.Start += offsets[i1]
+		edit.End += offsets[i1]
+		edits = append(edits, edit)
+	}
+
+	for _, h := range diffLines(a, b) {
+		// lines are changed one by one, such as indent is changed
+		if h.i2-h.i1 == h.j2-h.j1 {
+			for i := 0; i < h.i2-h.i1; i++ {
+				add(h.i1+i, h.i1+i+1, h.j1+i, h.j1+i+1)
+			}
+			continue
+		}
+		add(h.i1, h.i2, h.j1, h.j2)
+	}
+
+	return edits
+}
+
+// hunk means lines a[i1:i2] are replaced by b[j1:j2]
+type hunk struct {
+	i1, i2 int
+	j1, j2 int
+}
+
+// diffLines returns changed hunks between a and b. It uses Myers' diff algorithm
+func diffLines(a, b []string) []hunk {
+	// common prefix and suffix are skipped to reduce work
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+
+	hunks := make([]hunk, 0)
+	equals, ok := myers(a, b)
+	if !ok {
+		return append(hunks, hunk{i1: prefix, i2: prefix + len(a), j1: prefix, j2: prefix + len(b)})
+	}
+
+	// lines between equal lines are changed
+	x, y := 0, 0
+	for _, eq := range append(equals, [2]int{len(a), len(b)}) {
+		if eq[0] > x || eq[1] > y {
+			hunks = append(hunks, hunk{i1: prefix + x, i2: prefix + eq[0], j1: prefix + y, j2: prefix + eq[1]})
+		}
+		x, y = eq[0]+1, eq[1]+1
+	}
+
+	return hunks
+}
+
+// myers returns index pairs of equal lines in a and b in order. It returns false if edit distance
+// exceeds maxDiffDistance
+func myers(a, b []string) ([][2]int, bool) {
+	n, m := len(a), len(b)
+	max := n + m
+	if max > maxDiffDistance {
+		max = maxDiffDistance
+	}
+
+	// v[k] is the furthest x of diagonal k, trace[d] is v before d-th step
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	trace := make([][]int, 0)
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func backtrack(trace [][]int, x, y int) [][2]int {
+	equals := make([][2]int, 0)
+	for d := len(trace) - 1; d >= 0; d-- {
+		// prev[d+k] is v[k] before d-th step
+		prev := trace[d]
+		k := x - y
+
+		prevX, prevY := 0, 0
+		if d > 0 {
+			prevK := k - 1
+			if k == -d || (k != d && prev[d+k-1] < prev[d+k+1]) {
+				prevK = k + 1
+			}
+			prevX = prev[d+prevK]
+			prevY = prevX - prevK
+		}
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			equals = append(equals, [2]int{x, y})
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(equals)-1; i < j; i, j = i+1, j-1 {
+		equals[i], equals[j] = equals[j], equals[i]
+	}
+
+	return equals
+}
+
+// diffContext is the number of unchanged lines around changes in unified diff
+const diffContext = 3
+
+// UnifiedDiff returns changes from oldText to newText in unified diff format. It returns empty string if
+// texts are equal
+func UnifiedDiff(oldName, newName, oldText, newText string) string {
+	a, b := splitLines(oldText), splitLines(newText)
+	hunks := diffLines(a, b)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "--- %s\n+++ %s\n", oldName, newName)
+
+	writeLines := func(prefix string, lines []string) {
+		for _, line := range lines {
+			buf.WriteString(prefix + line)
+			if !strings.HasSuffix(line, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	for len(hunks) > 0 {
+		// hunks whose contexts overlap are printed together
+		n := 1
+		for n < len(hunks) && hunks[n].i1-hunks[n-1].i2 <= 2*diffContext {
+			n++
+		}
+		group := hunks[:n]
+		hunks = hunks[n:]
+
+		first, last := group[0], group[len(group)-1]
+		i1, i2 := first.i1-diffContext, last.i2+diffContext
+		if i1 < 0 {
+			i1 = 0
+		}
+		if i2 > len(a) {
+			i2 = len(a)
+		}
+		j1 := first.j1 - (first.i1 - i1)
+		j2 := last.j2 + (i2 - last.i2)
+		fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(i1, i2), hunkRange(j1, j2))
+
+		pos := i1
+		for _, h := range group {
+			writeLines(" ", a[pos:h.i1])
+			writeLines("-", a[h.i1:h.i2])
+			writeLines("+", b[h.j1:h.j2])
+			pos = h.i2
+		}
+		writeLines(" ", a[pos:i2])
+	}
+
+	return buf.String()
+}
+
+// splitLines splits text into lines with line break
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// hunkRange formats lines [start, end) as range of unified diff. line number is 1-based
+func hunkRange(start, end int) string {
+	switch end - start {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, end-start)
+	}
+}
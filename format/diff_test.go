@@ -0,0 +1,141 @@
+package format
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffEdits(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    []TextEdit
+	}{
+		{
+			name:    "equal",
+			oldText: "struct A {\n}\n",
+			newText: "struct A {\n}\n",
+			want:    []TextEdit{},
+		},
+		{
+			name:    "changed lines",
+			oldText: "struct A {\n  1: i32 a\n  2: i32 b\n}\n\nstruct B {\n  1:i32 a\n}\n",
+			newText: "struct A {\n    1: i32 a\n    2: i32 b\n}\n\nstruct B {\n    1: i32 a\n}\n",
+			want: []TextEdit{
+				{Start: 13, End: 13, NewText: "  "},
+				{Start: 24, End: 24, NewText: "  "},
+				{Start: 49, End: 51, NewText: "  1: "},
+			},
+		},
+		{
+			name:    "insert and delete lines",
+			oldText: "a\nb\nc\n",
+			newText: "a\nc\nd\n",
+			want: []TextEdit{
+				{Start: 2, End: 4, NewText: ""},
+				{Start: 6, End: 6, NewText: "d\n"},
+			},
+		},
+		{
+			name:    "without newline at end",
+			oldText: "a\nb",
+			newText: "a\nb\n",
+			want: []TextEdit{
+				{Start: 3, End: 3, NewText: "\n"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffEdits(tt.oldText, tt.newText)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.newText, applyEdits(tt.oldText, got))
+		})
+	}
+}
+
+func TestDiffEditsRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	lines := []string{"a\n", "b\n", "c\n", "struct A {\n", "}\n", "\n"}
+	randomText := func() string {
+		buf := make([]string, r.Intn(30))
+		for i := range buf {
+			buf[i] = lines[r.Intn(len(lines))]
+		}
+		return strings.Join(buf, "")
+	}
+
+	for i := 0; i < 200; i++ {
+		oldText, newText := randomText(), randomText()
+		assert.Equal(t, newText, applyEdits(oldText, DiffEdits(oldText, newText)))
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    string
+	}{
+		{
+			name:    "equal",
+			oldText: "struct A {\n}\n",
+			newText: "struct A {\n}\n",
+			want:    "",
+		},
+		{
+			name:    "changed line",
+			oldText: "struct A {\n  1: i32 a\n}\n",
+			newText: "struct A {\n    1: i32 a\n}\n",
+			want: `--- a.thrift.orig
++++ a.thrift
+@@ -1,3 +1,3 @@
+ struct A {
+-  1: i32 a
++    1: i32 a
+ }
+`,
+		},
+		{
+			name:    "separated hunks",
+			oldText: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			newText: "0\n1\n2\n3\n4\n5\n6\n7\n8\n10\n",
+			want: `--- a.thrift.orig
++++ a.thrift
+@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -6,5 +7,4 @@
+ 6
+ 7
+ 8
+-9
+ 10
+`,
+		},
+		{
+			name:    "no newline at end of file",
+			oldText: "const i32 A = 1",
+			newText: "const i32 A = 1\n",
+			want: `--- a.thrift.orig
++++ a.thrift
+@@ -1 +1 @@
+-const i32 A = 1
+\ No newline at end of file
++const i32 A = 1
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, UnifiedDiff("a.thrift.orig", "a.thrift", tt.oldText, tt.newText))
+		})
+	}
+}
@@ -12,8 +12,8 @@ type fmtContext struct {
 	preNode parser.Node
 }
 
-func FormatDocument(doc *parser.Document) (string, error) {
-	if doc.ChildrenBadNode() {
+func (p *printer) formatDocument(doc *parser.Document) (string, error) {
+	if p.content == nil && doc.ChildrenBadNode() {
 		return "", BadNodeError
 	}
 
@@ -26,39 +26,17 @@ func FormatDocument(doc *parser.Document) (string, error) {
 			buf.WriteString("\n")
 		}
 
-		switch node.Type() {
-		case "Include":
-			buf.WriteString(MustFormatInclude(node.(*parser.Include)))
-		case "CPPInclude":
-			buf.WriteString(MustFormatCPPInclude(node.(*parser.CPPInclude)))
-		case "Namespace":
-			buf.WriteString(MustFormatNamespace(node.(*parser.Namespace)))
-		case "Struct":
-			buf.WriteString(MustFormatStruct(node.(*parser.Struct)))
-		case "Union":
-			buf.WriteString(MustFormatUnion(node.(*parser.Union)))
-		case "Exception":
-			buf.WriteString(MustFormatException(node.(*parser.Exception)))
-		case "Service":
-			buf.WriteString(MustFormatService(node.(*parser.Service)))
-		case "Typedef":
-			buf.WriteString(MustFormatTypedef(node.(*parser.Typedef)))
-		case "Const":
-			buf.WriteString(MustFormatConst(node.(*parser.Const)))
-		case "Enum":
-			buf.WriteString(MustFormatEnum(node.(*parser.Enum)))
-		}
-
+		buf.WriteString(p.formatNode(node))
 	}
 
 	for _, node := range doc.Nodes {
-		addtionalLine := needAddtionalLineInDocument(fmtCtx.preNode, node)
+		addtionalLine := p.needAddtionalLineInDocument(fmtCtx.preNode, node)
 		writeBuf(node, addtionalLine)
 		fmtCtx.preNode = node
 	}
 
 	if len(doc.Comments) > 0 {
-		addtionalLine := needAddtionalLineInDocument(fmtCtx.preNode, doc.Comments[0])
+		addtionalLine := p.needAddtionalLineInDocument(fmtCtx.preNode, doc.Comments[0])
 		if addtionalLine {
 			buf.WriteString("\n")
 		}
@@ -68,6 +46,38 @@ func FormatDocument(doc *parser.Document) (string, error) {
 	return buf.String(), nil
 }
 
+func (p *printer) formatNode(node parser.Node) string {
+	// nodes with syntax error are kept as they are
+	if p.keepAsIs(node) {
+		return p.source(node, true) + "\n"
+	}
+
+	switch node.Type() {
+	case "Include":
+		return p.formatInclude(node.(*parser.Include))
+	case "CPPInclude":
+		return p.formatCPPInclude(node.(*parser.CPPInclude))
+	case "Namespace":
+		return p.formatNamespace(node.(*parser.Namespace))
+	case "Struct":
+		return p.formatStruct(node.(*parser.Struct))
+	case "Union":
+		return p.formatUnion(node.(*parser.Union))
+	case "Exception":
+		return p.formatException(node.(*parser.Exception))
+	case "Service":
+		return p.formatService(node.(*parser.Service))
+	case "Typedef":
+		return p.formatTypedef(node.(*parser.Typedef))
+	case "Const":
+		return p.formatConst(node.(*parser.Const))
+	case "Enum":
+		return p.formatEnum(node.(*parser.Enum))
+	}
+
+	return ""
+}
+
 var (
 	header = map[string]struct{}{
 		"Include":    {},
@@ -104,11 +114,15 @@ func isMultiLineDefinition(node parser.Node) bool {
 	return ok
 }
 
-func needAddtionalLineInDocument(preNode parser.Node, currentNode parser.Node) bool {
+func (p *printer) needAddtionalLineInDocument(preNode parser.Node, currentNode parser.Node) bool {
 	if preNode == nil {
 		return false
 	}
 
+	if p.keepAsIs(preNode) || p.keepAsIs(currentNode) {
+		return p.hasEmptyLineBetween(preNode, currentNode)
+	}
+
 	if isHeader(preNode) && isHeader(currentNode) {
 		if preNode.Type() == currentNode.Type() {
 			if lineDistance(preNode, currentNode) > 1 {
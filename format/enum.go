@@ -24,8 +24,8 @@ type EnumFormatter struct {
 	EndLineComments string
 }
 
-func MustFormatEnum(enum *parser.Enum) string {
-	comments, annos := formatCommentsAndAnnos(enum.Comments, enum.Annotations, "")
+func (p *printer) formatEnum(enum *parser.Enum) string {
+	comments, annos := p.formatCommentsAndAnnos(enum.Comments, enum.Annotations, "")
 	if len(enum.Comments) > 0 && lineDistance(enum.Comments[len(enum.Comments)-1], enum.EnumKeyword) > 1 {
 		comments = comments + "\n"
 	}
@@ -35,15 +35,15 @@ func MustFormatEnum(enum *parser.Enum) string {
 		Enum:            MustFormatKeyword(enum.EnumKeyword.Keyword),
 		Identifier:      MustFormatIdentifier(enum.Name),
 		LCUR:            MustFormatKeyword(enum.LCurKeyword.Keyword),
-		EnumValues:      MustFormatEnumValues(enum.Values, Indent),
+		EnumValues:      p.formatEnumValues(enum.Values, p.indent()),
 		RCUR:            MustFormatKeyword(enum.RCurKeyword.Keyword),
 		Annotations:     annos,
 		EndLineComments: MustFormatEndLineComments(enum.EndLineComments, ""),
 	}
 
 	if len(enum.Values) > 0 {
-		return MustFormat(enumMultiLineTpl, f)
+		return p.wrapAnnotations(MustFormat(enumMultiLineTpl, f), annos, enum.Annotations, "")
 	}
 
-	return MustFormat(enumOneLineTpl, f)
+	return p.wrapAnnotations(MustFormat(enumOneLineTpl, f), annos, enum.Annotations, "")
 }
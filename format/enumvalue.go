@@ -7,16 +7,20 @@ import (
 	"github.com/joyme123/thrift-ls/parser"
 )
 
-func MustFormatEnumValues(values []*parser.EnumValue, indent string) string {
+func (p *printer) formatEnumValues(values []*parser.EnumValue, indent string) string {
 	buf := bytes.NewBuffer(nil)
 
 	fmtCtx := &fmtContext{}
 
 	for i, v := range values {
-		if needAddtionalLineForEnumValues(fmtCtx.preNode, values[i]) {
+		if p.needAddtionalLineForEnumValues(fmtCtx.preNode, values[i]) {
 			buf.WriteString("\n")
 		}
-		buf.WriteString(MustFormatEnumValue(v, indent))
+		if p.keepMember(v) {
+			buf.WriteString(indent + p.source(v, false))
+		} else {
+			buf.WriteString(p.formatEnumValue(v, indent))
+		}
 		if i < len(values)-1 {
 			buf.WriteString("\n")
 		}
@@ -27,8 +31,8 @@ func MustFormatEnumValues(values []*parser.EnumValue, indent string) string {
 }
 
 // TODO(jpf): comments
-func MustFormatEnumValue(enumValue *parser.EnumValue, indent string) string {
-	comments, annos := formatCommentsAndAnnos(enumValue.Comments, enumValue.Annotations, indent)
+func (p *printer) formatEnumValue(enumValue *parser.EnumValue, indent string) string {
+	comments, annos := p.formatCommentsAndAnnos(enumValue.Comments, enumValue.Annotations, indent)
 
 	if len(comments) > 0 && lineDistance(enumValue.Comments[len(enumValue.Comments)-1], enumValue.Name) > 1 {
 		comments = comments + "\n"
@@ -37,7 +41,7 @@ func MustFormatEnumValue(enumValue *parser.EnumValue, indent string) string {
 	buf := bytes.NewBufferString(comments)
 	buf.WriteString(indent + MustFormatIdentifier(enumValue.Name))
 	if enumValue.ValueNode != nil {
-		buf.WriteString(fmt.Sprintf(" %s %s", MustFormatKeyword(enumValue.EqualKeyword.Keyword), MustFormatConstValue(enumValue.ValueNode)))
+		buf.WriteString(fmt.Sprintf(" %s %s", MustFormatKeyword(enumValue.EqualKeyword.Keyword), p.formatConstValue(enumValue.ValueNode)))
 	}
 
 	buf.WriteString(annos)
@@ -48,14 +52,18 @@ func MustFormatEnumValue(enumValue *parser.EnumValue, indent string) string {
 
 	buf.WriteString(MustFormatEndLineComments(enumValue.EndLineComments, ""))
 
-	return buf.String()
+	return p.wrapAnnotations(buf.String(), annos, enumValue.Annotations, indent)
 }
 
-func needAddtionalLineForEnumValues(preNode, curNode parser.Node) bool {
+func (p *printer) needAddtionalLineForEnumValues(preNode, curNode parser.Node) bool {
 	if preNode == nil {
 		return false
 	}
 
+	if p.keepMember(preNode) || p.keepMember(curNode) {
+		return p.hasEmptyLineBetween(preNode, curNode)
+	}
+
 	curValue := curNode.(*parser.EnumValue)
 
 	var curStartLine int
@@ -23,8 +23,8 @@ type ExceptionFormatter struct {
 	EndLineComments string
 }
 
-func MustFormatException(excep *parser.Exception) string {
-	comments, annos := formatCommentsAndAnnos(excep.Comments, excep.Annotations, "")
+func (p *printer) formatException(excep *parser.Exception) string {
+	comments, annos := p.formatCommentsAndAnnos(excep.Comments, excep.Annotations, "")
 	if len(excep.Comments) > 0 && lineDistance(excep.Comments[len(excep.Comments)-1], excep.ExceptionKeyword) > 1 {
 		comments = comments + "\n"
 	}
@@ -33,15 +33,15 @@ func MustFormatException(excep *parser.Exception) string {
 		Exception:       MustFormatKeyword(excep.ExceptionKeyword.Keyword),
 		Identifier:      MustFormatIdentifier(excep.Name),
 		LCUR:            MustFormatKeyword(excep.LCurKeyword.Keyword),
-		Fields:          MustFormatFields(excep.Fields, Indent),
+		Fields:          p.formatFields(excep.Fields, p.indent()),
 		RCUR:            MustFormatKeyword(excep.RCurKeyword.Keyword),
 		Annotations:     annos,
 		EndLineComments: MustFormatEndLineComments(excep.EndLineComments, ""),
 	}
 
 	if len(excep.Fields) > 0 {
-		return MustFormat(exceptionMultiLineTpl, f)
+		return p.wrapAnnotations(MustFormat(exceptionMultiLineTpl, f), annos, excep.Annotations, "")
 	}
 
-	return MustFormat(exceptionOneLineTpl, f)
+	return p.wrapAnnotations(MustFormat(exceptionOneLineTpl, f), annos, excep.Annotations, "")
 }
@@ -9,9 +9,9 @@ import (
 	"github.com/joyme123/thrift-ls/parser"
 )
 
-type fieldGroup []string
+type fieldGroup []*parser.Field
 
-func MustFormatFields(fields []*parser.Field, indent string) string {
+func (p *printer) formatFields(fields []*parser.Field, indent string) string {
 	buf := bytes.NewBuffer(nil)
 
 	fmtCtx := &fmtContext{}
@@ -19,11 +19,11 @@ func MustFormatFields(fields []*parser.Field, indent string) string {
 	var fieldGroups []fieldGroup
 	var fg fieldGroup
 	for _, field := range fields {
-		if needAddtionalLineForFields(fmtCtx.preNode, field) {
+		if p.needAddtionalLineForFields(fmtCtx.preNode, field) {
 			fieldGroups = append(fieldGroups, fg)
 			fg = make(fieldGroup, 0)
 		}
-		fg = append(fg, MustFormatField(field, "\t", indent))
+		fg = append(fg, field)
 		fmtCtx.preNode = field
 	}
 
@@ -32,12 +32,7 @@ func MustFormatFields(fields []*parser.Field, indent string) string {
 	}
 
 	for i, fg := range fieldGroups {
-		w := new(tabwriter.Writer)
-		w.Init(buf, 1, 0, 1, ' ', 0)
-		for j := range fg {
-			fmt.Fprintln(w, fg[j])
-		}
-		w.Flush()
+		buf.WriteString(p.formatFieldGroup(fg, indent))
 
 		if i < len(fieldGroups)-1 {
 			buf.WriteString("\n")
@@ -47,10 +42,91 @@ func MustFormatFields(fields []*parser.Field, indent string) string {
 	return buf.String()
 }
 
-func MustFormatOneLineFields(fields []*parser.Field) string {
+// formatFieldGroup formats fields without empty line between them. fields with syntax error are printed as they
+// are, and split the group into aligned parts
+func (p *printer) formatFieldGroup(fg fieldGroup, indent string) string {
+	buf := bytes.NewBuffer(nil)
+	start := 0
+	for i, field := range fg {
+		if !p.keepMember(field) {
+			continue
+		}
+		buf.WriteString(p.formatAlignedFields(fg[start:i], indent))
+		buf.WriteString(indent + p.source(field, false) + "\n")
+		start = i + 1
+	}
+	buf.WriteString(p.formatAlignedFields(fg[start:], indent))
+
+	return buf.String()
+}
+
+// formatAlignedFields formats fields whose columns are aligned by tabwriter. indent is added after alignment,
+// otherwise tab indent is taken as an empty column by tabwriter
+func (p *printer) formatAlignedFields(fg fieldGroup, indent string) string {
+	if len(fg) == 0 {
+		return ""
+	}
+
+	space := " "
+	if p.opts.AlignFields {
+		space = "\t"
+	}
+
+	texts := make([]string, len(fg))
+	aligned := bytes.NewBuffer(nil)
+	w := new(tabwriter.Writer)
+	w.Init(aligned, 1, 0, 1, ' ', 0)
+	for i, field := range fg {
+		texts[i] = p.formatField(field, space, "", p.fieldSeparator(field.ListSeparatorKeyword))
+		fmt.Fprintln(w, texts[i])
+	}
+	w.Flush()
+
+	buf := bytes.NewBuffer(nil)
+	lines := strings.SplitAfter(aligned.String(), "\n")
+	for i, field := range fg {
+		n := strings.Count(texts[i], "\n") + 1
+		text := ""
+		for _, line := range lines[:n] {
+			if line != "\n" {
+				line = indent + line
+			}
+			text += line
+		}
+		lines = lines[n:]
+
+		if field.Annotations != nil && len(field.Annotations.Annotations) > 0 {
+			text = p.wrapAnnotations(text, " "+p.formatAnnotations(field.Annotations), field.Annotations, indent)
+		}
+		buf.WriteString(text)
+	}
+
+	return buf.String()
+}
+
+// fieldSeparator returns separator at end of struct field according to options. separator with comments
+// is kept as it is
+func (p *printer) fieldSeparator(sep *parser.ListSeparatorKeyword) string {
+	if sep != nil && len(sep.Comments) > 0 {
+		return formatListSeparator(sep)
+	}
+
+	switch p.opts.FieldSeparator {
+	case FieldSeparatorComma:
+		return ","
+	case FieldSeparatorSemicolon:
+		return ";"
+	case FieldSeparatorNone:
+		return ""
+	}
+
+	return formatListSeparator(sep)
+}
+
+func (p *printer) formatOneLineFields(fields []*parser.Field) string {
 	buf := bytes.NewBuffer(nil)
 	for i, field := range fields {
-		buf.WriteString(MustFormatField(field, " ", ""))
+		buf.WriteString(p.formatField(field, " ", "", formatListSeparator(field.ListSeparatorKeyword)))
 		if i < len(fields)-1 {
 			buf.WriteString(" ")
 		}
@@ -59,8 +135,8 @@ func MustFormatOneLineFields(fields []*parser.Field) string {
 	return buf.String()
 }
 
-func MustFormatField(field *parser.Field, space string, indent string) string {
-	comments, annos := formatCommentsAndAnnos(field.Comments, field.Annotations, indent)
+func (p *printer) formatField(field *parser.Field, space string, indent string, sep string) string {
+	comments, annos := p.formatCommentsAndAnnos(field.Comments, field.Annotations, indent)
 	if len(field.Comments) > 0 && lineDistance(field.Comments[len(field.Comments)-1], field.Index) > 1 {
 		comments = comments + "\n"
 	}
@@ -73,12 +149,12 @@ func MustFormatField(field *parser.Field, space string, indent string) string {
 
 	value := ""
 	if field.ConstValue != nil {
-		value = fmt.Sprintf("%s%s%s%s", space, MustFormatKeyword(field.EqualKeyword.Keyword), space, MustFormatConstValue(field.ConstValue))
+		value = fmt.Sprintf("%s%s%s%s", space, MustFormatKeyword(field.EqualKeyword.Keyword), space, p.formatConstValue(field.ConstValue))
 	}
-	str := fmt.Sprintf("%s%d:%s%s%s%s%s%s", indent, field.Index.Value, space, required, MustFormatFieldType(field.FieldType), space, field.Identifier.Name.Text, value)
+	str := fmt.Sprintf("%s%d:%s%s%s%s%s%s", indent, field.Index.Value, space, required, p.formatFieldType(field.FieldType), space, field.Identifier.Name.Text, value)
 	buf.WriteString(str)
 	buf.WriteString(annos)
-	buf.WriteString(formatListSeparator(field.ListSeparatorKeyword))
+	buf.WriteString(sep)
 	if len(field.EndLineComments) > 0 {
 		buf.WriteString(MustFormatEndLineComments(field.EndLineComments, ""))
 	}
@@ -87,10 +163,10 @@ func MustFormatField(field *parser.Field, space string, indent string) string {
 	return strings.TrimRight(buf.String(), " ")
 }
 
-func MustFormatFieldType(ft *parser.FieldType) string {
+func (p *printer) formatFieldType(ft *parser.FieldType) string {
 	annos := ""
 	if ft.Annotations != nil {
-		annos = MustFormatAnnotations(ft.Annotations)
+		annos = p.formatAnnotations(ft.Annotations)
 		if len(ft.Annotations.Annotations) > 0 {
 			annos = " " + annos
 		}
@@ -100,11 +176,11 @@ func MustFormatFieldType(ft *parser.FieldType) string {
 
 	switch ft.TypeName.Name {
 	case "map":
-		return fmt.Sprintf("%s<%s,%s>%s", tn, MustFormatFieldType(ft.KeyType), MustFormatFieldType(ft.ValueType), annos)
+		return fmt.Sprintf("%s<%s,%s>%s", tn, p.formatFieldType(ft.KeyType), p.formatFieldType(ft.ValueType), annos)
 	case "set":
-		return fmt.Sprintf("%s<%s>%s", tn, MustFormatFieldType(ft.KeyType), annos)
+		return fmt.Sprintf("%s<%s>%s", tn, p.formatFieldType(ft.KeyType), annos)
 	case "list":
-		return fmt.Sprintf("%s<%s>%s", tn, MustFormatFieldType(ft.KeyType), annos)
+		return fmt.Sprintf("%s<%s>%s", tn, p.formatFieldType(ft.KeyType), annos)
 	default:
 		return tn + annos
 	}
@@ -119,11 +195,15 @@ func MustFormatTypeName(tn *parser.TypeName) string {
 	return comments + tn.Name
 }
 
-func needAddtionalLineForFields(preNode, curNode parser.Node) bool {
+func (p *printer) needAddtionalLineForFields(preNode, curNode parser.Node) bool {
 	if preNode == nil {
 		return false
 	}
 
+	if p.keepMember(preNode) || p.keepMember(curNode) {
+		return p.hasEmptyLineBetween(preNode, curNode)
+	}
+
 	curField := curNode.(*parser.Field)
 
 	var curStartLine int
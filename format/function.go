@@ -2,18 +2,23 @@ package format
 
 import (
 	"bytes"
+	"strings"
 
 	"github.com/joyme123/thrift-ls/parser"
 )
 
-func MustFormatFunctions(fns []*parser.Function, indent string) string {
+func (p *printer) formatFunctions(fns []*parser.Function, indent string) string {
 	buf := bytes.NewBuffer(nil)
 	fmtCtx := &fmtContext{}
 	for i := range fns {
-		if needAddtionalLineForFuncs(fmtCtx.preNode, fns[i]) {
+		if p.needAddtionalLineForFuncs(fmtCtx.preNode, fns[i]) {
 			buf.WriteString("\n")
 		}
-		buf.WriteString(MustFormatFunction(fns[i], indent))
+		if p.keepMember(fns[i]) {
+			buf.WriteString(indent + p.source(fns[i], false))
+		} else {
+			buf.WriteString(p.formatFunction(fns[i], indent))
+		}
 		if i < len(fns)-1 {
 			buf.WriteString("\n")
 		}
@@ -38,8 +43,8 @@ type FunctionFormatter struct {
 	EndLineComments string
 }
 
-func MustFormatFunction(fn *parser.Function, indent string) string {
-	comments, annos := formatCommentsAndAnnos(fn.Comments, fn.Annotations, indent)
+func (p *printer) formatFunction(fn *parser.Function, indent string) string {
+	comments, annos := p.formatCommentsAndAnnos(fn.Comments, fn.Annotations, indent)
 	var firstNode parser.Node
 	if fn.Void != nil {
 		firstNode = fn.Void
@@ -56,14 +61,14 @@ func MustFormatFunction(fn *parser.Function, indent string) string {
 	}
 	args := ""
 	if len(fn.Arguments) > 0 {
-		args = MustFormatOneLineFields(fn.Arguments)
+		args = p.formatOneLineFields(fn.Arguments)
 	}
 
 	ft := ""
 	if fn.Void != nil {
 		ft = MustFormatKeyword(fn.Void.Keyword)
 	} else {
-		ft = MustFormatFieldType(fn.FunctionType)
+		ft = p.formatFieldType(fn.FunctionType)
 	}
 
 	sep := ""
@@ -71,7 +76,7 @@ func MustFormatFunction(fn *parser.Function, indent string) string {
 		sep = MustFormatKeyword(fn.ListSeparatorKeyword.Keyword)
 	}
 
-	throws := MustFormatThrows(fn.Throws)
+	throws := p.formatThrows(fn.Throws)
 	if fn.Throws != nil {
 		throws = " " + throws
 	}
@@ -89,10 +94,26 @@ func MustFormatFunction(fn *parser.Function, indent string) string {
 		EndLineComments: MustFormatEndLineComments(fn.EndLineComments, ""),
 	}
 
-	fnStr := MustFormat(functionTpl, f)
-	fnStr = comments + indent + fnStr
+	fnStr := indent + MustFormat(functionTpl, f)
+	// arguments are put on their own lines if function is too long
+	if p.opts.MaxLineWidth > 0 && len(fn.Arguments) > 0 && p.lineWidth(strings.SplitN(fnStr, "\n", 2)[0]) > p.opts.MaxLineWidth {
+		f.Args = p.formatWrappedArguments(fn.Arguments, indent)
+		fnStr = indent + MustFormat(functionTpl, f)
+	}
+	fnStr = comments + fnStr
+
+	return p.wrapAnnotations(fnStr, annos, fn.Annotations, indent)
+}
+
+func (p *printer) formatWrappedArguments(args []*parser.Field, indent string) string {
+	buf := bytes.NewBufferString("\n")
+	for _, arg := range args {
+		buf.WriteString(p.formatField(arg, " ", indent+p.indent(), formatListSeparator(arg.ListSeparatorKeyword)))
+		buf.WriteString("\n")
+	}
+	buf.WriteString(indent)
 
-	return fnStr
+	return buf.String()
 }
 
 const throwTpl = "{{.Throw}} {{.LPAR}}{{.Fields}}{{.RPAR}}"
@@ -104,14 +125,14 @@ type ThrowFormatter struct {
 	RPAR   string
 }
 
-func MustFormatThrows(throws *parser.Throws) string {
+func (p *printer) formatThrows(throws *parser.Throws) string {
 	if throws == nil {
 		return ""
 	}
 
 	args := ""
 	if len(throws.Fields) > 0 {
-		args = MustFormatOneLineFields(throws.Fields)
+		args = p.formatOneLineFields(throws.Fields)
 	}
 
 	f := &ThrowFormatter{
@@ -125,11 +146,15 @@ func MustFormatThrows(throws *parser.Throws) string {
 
 }
 
-func needAddtionalLineForFuncs(preNode, curNode parser.Node) bool {
+func (p *printer) needAddtionalLineForFuncs(preNode, curNode parser.Node) bool {
 	if preNode == nil {
 		return false
 	}
 
+	if p.keepMember(preNode) || p.keepMember(curNode) {
+		return p.hasEmptyLineBetween(preNode, curNode)
+	}
+
 	curFunc := curNode.(*parser.Function)
 
 	var curStartLine int
@@ -13,8 +13,8 @@ type IncludeFormatter struct {
 	EndLineComments string
 }
 
-func MustFormatInclude(inc *parser.Include) string {
-	comments, _ := formatCommentsAndAnnos(inc.Comments, nil, "")
+func (p *printer) formatInclude(inc *parser.Include) string {
+	comments, _ := p.formatCommentsAndAnnos(inc.Comments, nil, "")
 	if len(inc.Comments) > 0 && lineDistance(inc.Comments[len(inc.Comments)-1], inc.IncludeKeyword) > 1 {
 		comments = comments + "\n"
 	}
@@ -22,15 +22,15 @@ func MustFormatInclude(inc *parser.Include) string {
 	f := &IncludeFormatter{
 		Comments:        comments,
 		Include:         MustFormatKeyword(inc.IncludeKeyword.Keyword),
-		Path:            MustFormatLiteral(inc.Path),
+		Path:            p.formatLiteral(inc.Path),
 		EndLineComments: MustFormatComments(inc.EndLineComments, ""),
 	}
 
 	return MustFormat(includeTpl, f)
 }
 
-func MustFormatCPPInclude(inc *parser.CPPInclude) string {
-	comments, _ := formatCommentsAndAnnos(inc.Comments, nil, "")
+func (p *printer) formatCPPInclude(inc *parser.CPPInclude) string {
+	comments, _ := p.formatCommentsAndAnnos(inc.Comments, nil, "")
 	if len(inc.Comments) > 0 && lineDistance(inc.Comments[len(inc.Comments)-1], inc.CPPIncludeKeyword) > 1 {
 		comments = comments + "\n"
 	}
@@ -38,7 +38,7 @@ func MustFormatCPPInclude(inc *parser.CPPInclude) string {
 	f := &IncludeFormatter{
 		Comments:        comments,
 		Include:         MustFormatKeyword(inc.CPPIncludeKeyword.Keyword),
-		Path:            MustFormatLiteral(inc.Path),
+		Path:            p.formatLiteral(inc.Path),
 		EndLineComments: MustFormatComments(inc.EndLineComments, ""),
 	}
 
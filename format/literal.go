@@ -2,13 +2,63 @@ package format
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/joyme123/thrift-ls/parser"
 )
 
-func MustFormatLiteral(l *parser.Literal) string {
+func (p *printer) formatLiteral(l *parser.Literal) string {
+	quote := p.quote(l.Quote, l.Value.Text)
 	if len(l.Comments) > 0 {
-		return fmt.Sprintf("%s %s%s%s", MustFormatComments(l.Comments, ""), l.Quote, l.Value.Text, l.Quote)
+		return fmt.Sprintf("%s %s%s%s", MustFormatComments(l.Comments, ""), quote, l.Value.Text, quote)
 	}
-	return fmt.Sprintf("%s%s%s", l.Quote, l.Value.Text, l.Quote)
+	return fmt.Sprintf("%s%s%s", quote, l.Value.Text, quote)
+}
+
+// quote returns quote of literal text according to QuoteStyle option. original quote is kept if text
+// contains the target quote
+func (p *printer) quote(original string, text string) string {
+	target := original
+	switch p.opts.QuoteStyle {
+	case QuoteStyleDouble:
+		target = "\""
+	case QuoteStyleSingle:
+		target = "'"
+	}
+
+	if strings.Contains(text, target) {
+		return original
+	}
+
+	return target
+}
+
+// requote returns raw text of literal quoted by original as quoted by target. escaped quotes are converted,
+// so value of literal isn't changed
+func requote(text string, original string, target string) string {
+	if target == original {
+		return original + text + original
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString(target)
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		if ch == '\\' && i+1 < len(text) && (text[i+1] == '"' || text[i+1] == '\'') {
+			i++
+			ch = text[i]
+			if string(ch) == target {
+				buf.WriteByte('\\')
+			}
+			buf.WriteByte(ch)
+			continue
+		}
+		if string(ch) == target {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(ch)
+	}
+	buf.WriteString(target)
+
+	return buf.String()
 }
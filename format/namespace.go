@@ -15,8 +15,8 @@ type NamespaceFormatter struct {
 	EndLineComments string
 }
 
-func MustFormatNamespace(ns *parser.Namespace) string {
-	comments, annos := formatCommentsAndAnnos(ns.Comments, ns.Annotations, "")
+func (p *printer) formatNamespace(ns *parser.Namespace) string {
+	comments, annos := p.formatCommentsAndAnnos(ns.Comments, ns.Annotations, "")
 	if len(ns.Comments) > 0 && lineDistance(ns.Comments[len(ns.Comments)-1], ns.NamespaceKeyword) > 1 {
 		comments = comments + "\n"
 	}
@@ -30,5 +30,5 @@ func MustFormatNamespace(ns *parser.Namespace) string {
 		EndLineComments: MustFormatEndLineComments(ns.EndLineComments, ""),
 	}
 
-	return MustFormat(namespaceOneLineTpl, f)
+	return p.wrapAnnotations(MustFormat(namespaceOneLineTpl, f), annos, ns.Annotations, "")
 }
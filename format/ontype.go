@@ -0,0 +1,89 @@
+package format
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/joyme123/thrift-ls/parser"
+)
+
+// FormatOnTypeRCur formats the struct, enum, union, exception or service closed by '}' which ends at offset.
+// Only the changed part of definition is returned, so content around cursor is kept as much as possible
+func FormatOnTypeRCur(doc *parser.Document, content []byte, offset int, opts Options) ([]TextEdit, error) {
+	for _, node := range doc.Nodes {
+		rCur := rCurLiteral(node)
+		if rCur == nil || rCur.End().Offset != offset {
+			continue
+		}
+		// definition is being edited, it will be formatted after syntax error is fixed
+		if keepAsIs(node) {
+			return nil, nil
+		}
+
+		start, end := trimSpan(content, node.Pos().Offset, node.End().Offset)
+		formatted := strings.TrimRightFunc(FormatNodeWithOptions(node, content, opts), unicode.IsSpace)
+		edits := DiffEdits(string(content[start:end]), formatted)
+		for i := range edits {
+			edits[i].Start += start
+			edits[i].End += start
+		}
+
+		return edits, nil
+	}
+
+	return nil, nil
+}
+
+func rCurLiteral(node parser.Node) *parser.KeywordLiteral {
+	var rCur *parser.RCurKeyword
+	switch node := node.(type) {
+	case *parser.Struct:
+		rCur = node.RCurKeyword
+	case *parser.Enum:
+		rCur = node.RCurKeyword
+	case *parser.Union:
+		rCur = node.RCurKeyword
+	case *parser.Exception:
+		rCur = node.RCurKeyword
+	case *parser.Service:
+		rCur = node.RCurKeyword
+	}
+	if rCur == nil || rCur.BadNode {
+		return nil
+	}
+
+	return rCur.Literal
+}
+
+// minimalEdit returns edit which replaces oldText with newText. common prefix and suffix of them
+// are excluded from edit. It returns false if oldText equals newText
+func minimalEdit(oldText, newText string) (TextEdit, bool) {
+	if oldText == newText {
+		return TextEdit{}, false
+	}
+
+	prefix := 0
+	for prefix < len(oldText) && prefix < len(newText) && oldText[prefix] == newText[prefix] {
+		prefix++
+	}
+	// edit shouldn't split a multi bytes character
+	for prefix > 0 && prefix < len(oldText) && !utf8.RuneStart(oldText[prefix]) {
+		prefix--
+	}
+
+	suffix := 0
+	for suffix < len(oldText)-prefix && suffix < len(newText)-prefix &&
+		oldText[len(oldText)-1-suffix] == newText[len(newText)-1-suffix] {
+		suffix++
+	}
+	for suffix > 0 && !utf8.RuneStart(oldText[len(oldText)-suffix]) {
+		suffix--
+	}
+
+	return TextEdit{
+		Start:   prefix,
+		End:     len(oldText) - suffix,
+		NewText: newText[prefix : len(newText)-suffix],
+	}, true
+}
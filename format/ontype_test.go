@@ -0,0 +1,139 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatOnTypeRCur(t *testing.T) {
+	content := `const   i32   Max   =   1
+
+struct User {
+  1:   string name
+  2: i64 id
+}
+
+enum Status {
+  OK = 1,
+  ERROR=2
+}
+
+service Api {
+    User get(1: i64 id)
+}`
+
+	tests := []struct {
+		name  string
+		after string
+		want  string
+		edits []TextEdit
+	}{
+		{
+			name:  "struct",
+			after: "i64 id\n}",
+			want: `const   i32   Max   =   1
+
+struct User {
+    1: string name
+    2: i64    id
+}
+
+enum Status {
+  OK = 1,
+  ERROR=2
+}
+
+service Api {
+    User get(1: i64 id)
+}`,
+			edits: []TextEdit{
+				{Start: 43, End: 47, NewText: "  1:"},
+				{Start: 62, End: 68, NewText: "  2: i64   "},
+			},
+		},
+		{
+			name:  "enum",
+			after: "ERROR=2\n}",
+			want: `const   i32   Max   =   1
+
+struct User {
+  1:   string name
+  2: i64 id
+}
+
+enum Status {
+    OK = 1,
+    ERROR = 2
+}
+
+service Api {
+    User get(1: i64 id)
+}`,
+		},
+		{
+			name:  "formatted service",
+			after: "id)\n}",
+			want:  content,
+		},
+		{
+			name:  "not end of definition",
+			after: "struct User {",
+			want:  content,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := parser.Parse("test.thrift", []byte(content))
+			assert.NoError(t, err)
+
+			offset := strings.Index(content, tt.after) + len(tt.after)
+			edits, err := FormatOnTypeRCur(ast.(*parser.Document), []byte(content), offset, DefaultOptions())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, applyEdits(content, edits))
+			if tt.edits != nil {
+				assert.Equal(t, tt.edits, edits)
+			}
+		})
+	}
+}
+
+func Test_minimalEdit(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    TextEdit
+		ok      bool
+	}{
+		{
+			name:    "equal",
+			oldText: "struct A {}",
+			newText: "struct A {}",
+			ok:      false,
+		},
+		{
+			name:    "insert",
+			oldText: "1:string a",
+			newText: "1: string a",
+			want:    TextEdit{Start: 2, End: 2, NewText: " "},
+			ok:      true,
+		},
+		{
+			name:    "multi bytes character",
+			oldText: "a 名字",
+			newText: "a 名称",
+			want:    TextEdit{Start: 5, End: 8, NewText: "称"},
+			ok:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := minimalEdit(tt.oldText, tt.newText)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/joyme123/thrift-ls/parser"
+)
+
+// styles of separator at end of field
+const (
+	FieldSeparatorPreserve  = "preserve"
+	FieldSeparatorComma     = "comma"
+	FieldSeparatorSemicolon = "semicolon"
+	FieldSeparatorNone      = "none"
+)
+
+// styles of quote of string literal
+const (
+	QuoteStylePreserve = "preserve"
+	QuoteStyleDouble   = "double"
+	QuoteStyleSingle   = "single"
+)
+
+// Options controls the output of formatter
+type Options struct {
+	// IndentSize is the number of spaces of one indent level. It is ignored if UseTabs is true
+	IndentSize int  `yaml:"indentSize"`
+	UseTabs    bool `yaml:"useTabs"`
+
+	// FieldSeparator is the separator at end of struct, union and exception fields. one of
+	// preserve, comma, semicolon and none
+	FieldSeparator string `yaml:"fieldSeparator"`
+
+	// AlignFields aligns types and names of fields in columns
+	AlignFields bool `yaml:"alignFields"`
+
+	// QuoteStyle is the quote of string literals. one of preserve, double and single. quote of other
+	// literals isn't changed if they contain the target quote, and quotes in const strings are escaped
+	QuoteStyle string `yaml:"quoteStyle"`
+
+	// MaxLineWidth wraps annotation lists and function arguments exceeding it. 0 means no limit
+	MaxLineWidth int `yaml:"maxLineWidth"`
+}
+
+func DefaultOptions() Options {
+	return Options{
+		IndentSize:     len(Indent),
+		FieldSeparator: FieldSeparatorPreserve,
+		AlignFields:    true,
+		QuoteStyle:     QuoteStylePreserve,
+	}
+}
+
+// printer formats nodes with options
+type printer struct {
+	opts Options
+	// content is the original text of nodes. It's used to print nodes with syntax error as they are
+	content []byte
+}
+
+func newPrinter(content []byte, opts Options) *printer {
+	return &printer{opts: opts, content: content}
+}
+
+func (p *printer) indent() string {
+	if p.opts.UseTabs {
+		return "\t"
+	}
+	if p.opts.IndentSize <= 0 {
+		return Indent
+	}
+
+	return strings.Repeat(" ", p.opts.IndentSize)
+}
+
+// FormatDocumentWithOptions formats document parsed from content with options. Nodes with syntax error
+// are printed as their original text in content
+func FormatDocumentWithOptions(doc *parser.Document, content []byte, opts Options) (string, error) {
+	return newPrinter(content, opts).formatDocument(doc)
+}
+
+// FormatNodeWithOptions formats top level node of document parsed from content with options
+func FormatNodeWithOptions(node parser.Node, content []byte, opts Options) string {
+	return newPrinter(content, opts).formatNode(node)
+}
+
+// FormatDocument formats document with default options. Document with syntax error can't be formatted
+// without its content, use FormatDocumentWithOptions to keep nodes with syntax error as they are
+func FormatDocument(doc *parser.Document) (string, error) {
+	return FormatDocumentWithOptions(doc, nil, DefaultOptions())
+}
+
+// MustFormat* functions format nodes without their content, nodes must not contain syntax error. use
+// FormatNodeWithOptions to format nodes with syntax error
+
+func MustFormatAnnotations(annotations *parser.Annotations) string {
+	return newPrinter(nil, DefaultOptions()).formatAnnotations(annotations)
+}
+
+func MustFormatAnnotation(anno *parser.Annotation, isLast bool) string {
+	return newPrinter(nil, DefaultOptions()).formatAnnotation(anno, isLast)
+}
+
+func MustFormatLiteral(l *parser.Literal) string {
+	return newPrinter(nil, DefaultOptions()).formatLiteral(l)
+}
+
+func MustFormatConstValue(cv *parser.ConstValue) string {
+	return newPrinter(nil, DefaultOptions()).formatConstValue(cv)
+}
+
+func MustFormatConst(cst *parser.Const) string {
+	return newPrinter(nil, DefaultOptions()).formatConst(cst)
+}
+
+func MustFormatEnum(enum *parser.Enum) string {
+	return newPrinter(nil, DefaultOptions()).formatEnum(enum)
+}
+
+func MustFormatEnumValues(values []*parser.EnumValue, indent string) string {
+	return newPrinter(nil, DefaultOptions()).formatEnumValues(values, indent)
+}
+
+func MustFormatEnumValue(enumValue *parser.EnumValue, indent string) string {
+	return newPrinter(nil, DefaultOptions()).formatEnumValue(enumValue, indent)
+}
+
+func MustFormatException(excep *parser.Exception) string {
+	return newPrinter(nil, DefaultOptions()).formatException(excep)
+}
+
+func MustFormatFields(fields []*parser.Field, indent string) string {
+	return newPrinter(nil, DefaultOptions()).formatFields(fields, indent)
+}
+
+func MustFormatOneLineFields(fields []*parser.Field) string {
+	return newPrinter(nil, DefaultOptions()).formatOneLineFields(fields)
+}
+
+func MustFormatField(field *parser.Field, space string, indent string) string {
+	return newPrinter(nil, DefaultOptions()).formatField(field, space, indent, formatListSeparator(field.ListSeparatorKeyword))
+}
+
+func MustFormatFieldType(ft *parser.FieldType) string {
+	return newPrinter(nil, DefaultOptions()).formatFieldType(ft)
+}
+
+func MustFormatFunctions(fns []*parser.Function, indent string) string {
+	return newPrinter(nil, DefaultOptions()).formatFunctions(fns, indent)
+}
+
+func MustFormatFunction(fn *parser.Function, indent string) string {
+	return newPrinter(nil, DefaultOptions()).formatFunction(fn, indent)
+}
+
+func MustFormatThrows(throws *parser.Throws) string {
+	return newPrinter(nil, DefaultOptions()).formatThrows(throws)
+}
+
+func MustFormatInclude(inc *parser.Include) string {
+	return newPrinter(nil, DefaultOptions()).formatInclude(inc)
+}
+
+func MustFormatCPPInclude(inc *parser.CPPInclude) string {
+	return newPrinter(nil, DefaultOptions()).formatCPPInclude(inc)
+}
+
+func MustFormatNamespace(ns *parser.Namespace) string {
+	return newPrinter(nil, DefaultOptions()).formatNamespace(ns)
+}
+
+func MustFormatService(svc *parser.Service) string {
+	return newPrinter(nil, DefaultOptions()).formatService(svc)
+}
+
+func MustFormatStruct(st *parser.Struct) string {
+	return newPrinter(nil, DefaultOptions()).formatStruct(st)
+}
+
+func MustFormatTypedef(td *parser.Typedef) string {
+	return newPrinter(nil, DefaultOptions()).formatTypedef(td)
+}
+
+func MustFormatUnion(union *parser.Union) string {
+	return newPrinter(nil, DefaultOptions()).formatUnion(union)
+}
@@ -0,0 +1,159 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/joyme123/thrift-ls/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDocumentWithOptions(t *testing.T) {
+	content := `namespace go user (path = 'user', tag = "a'b")
+
+struct User {
+  1: required string name;
+  2: i64 id,
+  3: string email = 'a@b.com' (go.tag = 'json:"email"', validate = 'email', deprecated = 'true')
+}
+
+service UserService {
+  User getUser(1: i64 id, 2: string name, 3: string email) (path = "/user", method = "GET")
+}
+`
+
+	tests := []struct {
+		name string
+		opts func(opts *Options)
+		want string
+	}{
+		{
+			name: "default",
+			opts: func(opts *Options) {},
+			want: `namespace go user (path = 'user', tag = "a'b")
+
+struct User {
+    1: required string name;
+    2: i64      id,
+    3: string   email = 'a@b.com' (go.tag = 'json:"email"', validate = 'email', deprecated = 'true')
+}
+
+service UserService {
+    User getUser(1: i64 id, 2: string name, 3: string email) (path = "/user", method = "GET")
+}
+`,
+		},
+		{
+			name: "tabs without alignment",
+			opts: func(opts *Options) {
+				opts.UseTabs = true
+				opts.AlignFields = false
+			},
+			want: `namespace go user (path = 'user', tag = "a'b")
+
+struct User {
+	1: required string name;
+	2: i64 id,
+	3: string email = 'a@b.com' (go.tag = 'json:"email"', validate = 'email', deprecated = 'true')
+}
+
+service UserService {
+	User getUser(1: i64 id, 2: string name, 3: string email) (path = "/user", method = "GET")
+}
+`,
+		},
+		{
+			name: "indent size and separator",
+			opts: func(opts *Options) {
+				opts.IndentSize = 2
+				opts.FieldSeparator = FieldSeparatorComma
+			},
+			want: `namespace go user (path = 'user', tag = "a'b")
+
+struct User {
+  1: required string name,
+  2: i64      id,
+  3: string   email = 'a@b.com' (go.tag = 'json:"email"', validate = 'email', deprecated = 'true'),
+}
+
+service UserService {
+  User getUser(1: i64 id, 2: string name, 3: string email) (path = "/user", method = "GET")
+}
+`,
+		},
+		{
+			name: "no separator and double quote",
+			opts: func(opts *Options) {
+				opts.FieldSeparator = FieldSeparatorNone
+				opts.QuoteStyle = QuoteStyleDouble
+			},
+			want: `namespace go user (path = "user", tag = "a'b")
+
+struct User {
+    1: required string name
+    2: i64      id
+    3: string   email = "a@b.com" (go.tag = 'json:"email"', validate = "email", deprecated = "true")
+}
+
+service UserService {
+    User getUser(1: i64 id, 2: string name, 3: string email) (path = "/user", method = "GET")
+}
+`,
+		},
+		{
+			name: "single quote",
+			opts: func(opts *Options) {
+				opts.QuoteStyle = QuoteStyleSingle
+			},
+			want: `namespace go user (path = 'user', tag = "a'b")
+
+struct User {
+    1: required string name;
+    2: i64      id,
+    3: string   email = 'a@b.com' (go.tag = 'json:"email"', validate = 'email', deprecated = 'true')
+}
+
+service UserService {
+    User getUser(1: i64 id, 2: string name, 3: string email) (path = '/user', method = 'GET')
+}
+`,
+		},
+		{
+			name: "max line width",
+			opts: func(opts *Options) {
+				opts.MaxLineWidth = 60
+			},
+			want: `namespace go user (path = 'user', tag = "a'b")
+
+struct User {
+    1: required string name;
+    2: i64      id,
+    3: string   email = 'a@b.com' (
+        go.tag = 'json:"email"',
+        validate = 'email',
+        deprecated = 'true'
+    )
+}
+
+service UserService {
+    User getUser(
+        1: i64 id,
+        2: string name,
+        3: string email
+    ) (path = "/user", method = "GET")
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := parser.Parse("test.thrift", []byte(content))
+			assert.NoError(t, err)
+
+			opts := DefaultOptions()
+			tt.opts(&opts)
+			got, err := FormatDocumentWithOptions(ast.(*parser.Document), []byte(content), opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
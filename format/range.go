@@ -0,0 +1,135 @@
+package format
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/joyme123/thrift-ls/parser"
+)
+
+// TextEdit replaces content[Start:End] with NewText. Start and End are 0-based byte offsets
+type TextEdit struct {
+	Start   int
+	End     int
+	NewText string
+}
+
+// FormatRange formats top level definitions which overlap range [start, end] of content. If range is inside
+// the body of a struct, union or exception, only fields overlapping range are formatted. Nodes with syntax error
+// are skipped, and content outside of formatted nodes is kept unchanged
+func FormatRange(doc *parser.Document, content []byte, start, end int, opts Options) ([]TextEdit, error) {
+	p := newPrinter(content, opts)
+	if start > end {
+		start, end = end, start
+	}
+
+	edits := make([]TextEdit, 0)
+	for _, node := range doc.Nodes {
+		// comments at end of document aren't formatted alone
+		if keepAsIs(node) || node.Type() == "Comment" {
+			continue
+		}
+
+		nodeStart, nodeEnd := trimSpan(content, node.Pos().Offset, node.End().Offset)
+		if nodeStart >= nodeEnd || nodeStart > end || start > nodeEnd {
+			continue
+		}
+
+		edit, ok := p.formatFieldsInRange(node, content, start, end)
+		if !ok {
+			edit = TextEdit{
+				Start:   nodeStart,
+				End:     nodeEnd,
+				NewText: strings.TrimRightFunc(p.formatNode(node), unicode.IsSpace),
+			}
+		}
+
+		if edit.NewText != string(content[edit.Start:edit.End]) {
+			edits = append(edits, edit)
+		}
+	}
+
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Start < edits[j].Start
+	})
+
+	return edits, nil
+}
+
+// formatFieldsInRange formats fields overlapping range when range is inside the body of struct, union
+// or exception. It returns false if fields can't be formatted alone, such as multi fields in one line
+func (p *printer) formatFieldsInRange(node parser.Node, content []byte, start, end int) (TextEdit, bool) {
+	var (
+		fields []*parser.Field
+		lCur   *parser.KeywordLiteral
+		rCur   *parser.KeywordLiteral
+	)
+	switch node := node.(type) {
+	case *parser.Struct:
+		fields, lCur, rCur = node.Fields, node.LCurKeyword.Literal, node.RCurKeyword.Literal
+	case *parser.Union:
+		fields, lCur, rCur = node.Fields, node.LCurKeyword.Literal, node.RCurKeyword.Literal
+	case *parser.Exception:
+		fields, lCur, rCur = node.Fields, node.LCurKeyword.Literal, node.RCurKeyword.Literal
+	default:
+		return TextEdit{}, false
+	}
+
+	if lCur == nil || rCur == nil || start < lCur.End().Offset || end > rCur.Pos().Offset {
+		return TextEdit{}, false
+	}
+
+	selected := make([]*parser.Field, 0)
+	editStart, editEnd := -1, -1
+	for _, field := range fields {
+		fieldStart, fieldEnd := trimSpan(content, field.Pos().Offset, field.End().Offset)
+		if fieldStart > end || start > fieldEnd {
+			continue
+		}
+		if editStart == -1 {
+			editStart = fieldStart
+		}
+		editEnd = fieldEnd
+		selected = append(selected, field)
+	}
+	if len(selected) == 0 {
+		return TextEdit{}, false
+	}
+
+	// formatted fields contain indent, so the first field should be the first one of its line, and the
+	// last field should be the last one of its line
+	lineStart := bytes.LastIndexByte(content[:editStart], '\n') + 1
+	if strings.TrimSpace(string(content[lineStart:editStart])) != "" {
+		return TextEdit{}, false
+	}
+	lineEnd := strings.IndexByte(string(content[editEnd:]), '\n')
+	if lineEnd == -1 {
+		lineEnd = len(content) - editEnd
+	}
+	if strings.TrimSpace(string(content[editEnd:editEnd+lineEnd])) != "" {
+		return TextEdit{}, false
+	}
+
+	return TextEdit{
+		Start:   lineStart,
+		End:     editEnd,
+		NewText: strings.TrimRightFunc(p.formatFields(selected, p.indent()), unicode.IsSpace),
+	}, true
+}
+
+// trimSpan trims spaces at both sides of content[start:end]. location of node may contain leading spaces
+func trimSpan(content []byte, start, end int) (int, int) {
+	if end > len(content) {
+		end = len(content)
+	}
+	for start < end && unicode.IsSpace(rune(content[start])) {
+		start++
+	}
+	for end > start && unicode.IsSpace(rune(content[end-1])) {
+		end--
+	}
+
+	return start, end
+}
@@ -0,0 +1,160 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func applyEdits(content string, edits []TextEdit) string {
+	for i := len(edits) - 1; i >= 0; i-- {
+		content = content[:edits[i].Start] + edits[i].NewText + content[edits[i].End:]
+	}
+	return content
+}
+
+func TestFormatRange(t *testing.T) {
+	content := `include   "a.thrift"
+
+struct User {
+  1:   string name,
+  2: required   i64 id
+  3: i32 age, 4: string email
+}
+
+const   i32   Max   =   1
+
+service   Api {
+  User get(1:i64 id)
+}
+`
+
+	tests := []struct {
+		name  string
+		start string
+		end   string
+		want  string
+	}{
+		{
+			name:  "single definition",
+			start: "const",
+			end:   "const",
+			want: `include   "a.thrift"
+
+struct User {
+  1:   string name,
+  2: required   i64 id
+  3: i32 age, 4: string email
+}
+
+const i32 Max = 1
+
+service   Api {
+  User get(1:i64 id)
+}
+`,
+		},
+		{
+			name:  "multi definitions",
+			start: "include",
+			end:   "struct User",
+			want: `include "a.thrift"
+
+struct User {
+    1: string   name,
+    2: required i64 id
+    3: i32      age,
+    4: string   email
+}
+
+const   i32   Max   =   1
+
+service   Api {
+  User get(1:i64 id)
+}
+`,
+		},
+		{
+			name:  "fields in struct",
+			start: "1:   string",
+			end:   "required",
+			want: `include   "a.thrift"
+
+struct User {
+    1: string   name,
+    2: required i64 id
+  3: i32 age, 4: string email
+}
+
+const   i32   Max   =   1
+
+service   Api {
+  User get(1:i64 id)
+}
+`,
+		},
+		{
+			name:  "fields in one line",
+			start: "3: i32",
+			end:   "3: i32",
+			want: `include   "a.thrift"
+
+struct User {
+    1: string   name,
+    2: required i64 id
+    3: i32      age,
+    4: string   email
+}
+
+const   i32   Max   =   1
+
+service   Api {
+  User get(1:i64 id)
+}
+`,
+		},
+		{
+			name:  "between definitions",
+			start: "\nconst",
+			end:   "\nconst",
+			want:  content,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := parser.Parse("test.thrift", []byte(content))
+			assert.NoError(t, err)
+
+			start, end := strings.Index(content, tt.start), strings.Index(content, tt.end)
+			edits, err := FormatRange(ast.(*parser.Document), []byte(content), start, end, DefaultOptions())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, applyEdits(content, edits))
+		})
+	}
+}
+
+func TestFormatRangeBadNode(t *testing.T) {
+	content := `struct User {
+  1:   string name
+}
+
+struct Bad {
+  1: string
+`
+
+	ast, _ := parser.Parse("test.thrift", []byte(content))
+	doc, ok := ast.(*parser.Document)
+	assert.True(t, ok)
+
+	edits, err := FormatRange(doc, []byte(content), 0, len(content), DefaultOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, `struct User {
+    1: string name
+}
+
+struct Bad {
+  1: string
+`, applyEdits(content, edits))
+}
@@ -26,8 +26,8 @@ type ServiceFormatter struct {
 	ExtendServiceName string
 }
 
-func MustFormatService(svc *parser.Service) string {
-	comments, annos := formatCommentsAndAnnos(svc.Comments, svc.Annotations, "")
+func (p *printer) formatService(svc *parser.Service) string {
+	comments, annos := p.formatCommentsAndAnnos(svc.Comments, svc.Annotations, "")
 	if len(svc.Comments) > 0 && lineDistance(svc.Comments[len(svc.Comments)-1], svc.ServiceKeyword) > 1 {
 		comments = comments + "\n"
 	}
@@ -37,7 +37,7 @@ func MustFormatService(svc *parser.Service) string {
 		Service:         MustFormatKeyword(svc.ServiceKeyword.Keyword),
 		Identifier:      MustFormatIdentifier(svc.Name),
 		LCUR:            MustFormatKeyword(svc.LCurKeyword.Keyword),
-		Functions:       MustFormatFunctions(svc.Functions, Indent),
+		Functions:       p.formatFunctions(svc.Functions, p.indent()),
 		RCUR:            MustFormatKeyword(svc.RCurKeyword.Keyword),
 		Annotations:     annos,
 		EndLineComments: MustFormatEndLineComments(svc.EndLineComments, ""),
@@ -51,8 +51,8 @@ func MustFormatService(svc *parser.Service) string {
 	}
 
 	if len(svc.Functions) > 0 {
-		return MustFormat(serviceMultiLineTpl, f)
+		return p.wrapAnnotations(MustFormat(serviceMultiLineTpl, f), annos, svc.Annotations, "")
 	}
 
-	return MustFormat(serviceOneLineTpl, f)
+	return p.wrapAnnotations(MustFormat(serviceOneLineTpl, f), annos, svc.Annotations, "")
 }
@@ -23,8 +23,8 @@ type StructFormatter struct {
 	EndLineComments string
 }
 
-func MustFormatStruct(st *parser.Struct) string {
-	comments, annos := formatCommentsAndAnnos(st.Comments, st.Annotations, "")
+func (p *printer) formatStruct(st *parser.Struct) string {
+	comments, annos := p.formatCommentsAndAnnos(st.Comments, st.Annotations, "")
 
 	if len(st.Comments) > 0 && lineDistance(st.Comments[len(st.Comments)-1], st.StructKeyword) > 1 {
 		comments = comments + "\n"
@@ -35,15 +35,15 @@ func MustFormatStruct(st *parser.Struct) string {
 		Struct:          MustFormatKeyword(st.StructKeyword.Keyword),
 		Identifier:      MustFormatIdentifier(st.Identifier),
 		LCUR:            MustFormatKeyword(st.LCurKeyword.Keyword),
-		Fields:          MustFormatFields(st.Fields, Indent),
+		Fields:          p.formatFields(st.Fields, p.indent()),
 		RCUR:            MustFormatKeyword(st.RCurKeyword.Keyword),
 		Annotations:     annos,
 		EndLineComments: MustFormatEndLineComments(st.EndLineComments, ""),
 	}
 
 	if len(st.Fields) > 0 {
-		return MustFormat(structMultiLineTpl, f)
+		return p.wrapAnnotations(MustFormat(structMultiLineTpl, f), annos, st.Annotations, "")
 	}
 
-	return MustFormat(structOneLineTpl, f)
+	return p.wrapAnnotations(MustFormat(structOneLineTpl, f), annos, st.Annotations, "")
 }
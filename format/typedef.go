@@ -16,8 +16,8 @@ type TypedefFormatter struct {
 	EndLineComments string
 }
 
-func MustFormatTypedef(td *parser.Typedef) string {
-	comments, annos := formatCommentsAndAnnos(td.Comments, td.Annotations, "")
+func (p *printer) formatTypedef(td *parser.Typedef) string {
+	comments, annos := p.formatCommentsAndAnnos(td.Comments, td.Annotations, "")
 
 	if len(td.Comments) > 0 && lineDistance(td.Comments[len(td.Comments)-1], td.TypedefKeyword) > 1 {
 		comments = comments + "\n"
@@ -26,11 +26,11 @@ func MustFormatTypedef(td *parser.Typedef) string {
 	f := &TypedefFormatter{
 		Comments:        comments,
 		Typedef:         MustFormatKeyword(td.TypedefKeyword.Keyword),
-		Type:            MustFormatFieldType(td.T),
+		Type:            p.formatFieldType(td.T),
 		Name:            MustFormatIdentifier(td.Alias),
 		Annotations:     annos,
 		EndLineComments: MustFormatEndLineComments(td.EndLineComments, ""),
 	}
 
-	return MustFormat(typedefOneLineTpl, f)
+	return p.wrapAnnotations(MustFormat(typedefOneLineTpl, f), annos, td.Annotations, "")
 }
@@ -23,8 +23,8 @@ type UnionFormatter struct {
 	EndLineComments string
 }
 
-func MustFormatUnion(union *parser.Union) string {
-	comments, annos := formatCommentsAndAnnos(union.Comments, union.Annotations, "")
+func (p *printer) formatUnion(union *parser.Union) string {
+	comments, annos := p.formatCommentsAndAnnos(union.Comments, union.Annotations, "")
 
 	if len(union.Comments) > 0 && lineDistance(union.Comments[len(union.Comments)-1], union.UnionKeyword) > 1 {
 		comments = comments + "\n"
@@ -35,15 +35,15 @@ func MustFormatUnion(union *parser.Union) string {
 		Union:           MustFormatKeyword(union.UnionKeyword.Keyword),
 		Identifier:      MustFormatIdentifier(union.Name),
 		LCUR:            MustFormatKeyword(union.LCurKeyword.Keyword),
-		Fields:          MustFormatFields(union.Fields, Indent),
+		Fields:          p.formatFields(union.Fields, p.indent()),
 		RCUR:            MustFormatKeyword(union.RCurKeyword.Keyword),
 		Annotations:     annos,
 		EndLineComments: MustFormatEndLineComments(union.EndLineComments, ""),
 	}
 
 	if len(union.Fields) > 0 {
-		return MustFormat(unionMultiLineTpl, f)
+		return p.wrapAnnotations(MustFormat(unionMultiLineTpl, f), annos, union.Annotations, "")
 	}
 
-	return MustFormat(unionOneLineTpl, f)
+	return p.wrapAnnotations(MustFormat(unionOneLineTpl, f), annos, union.Annotations, "")
 }
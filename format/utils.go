@@ -28,14 +28,14 @@ func MustFormat(tplText string, formatter any) string {
 	return buf.String()
 }
 
-func formatCommentsAndAnnos(comments []*parser.Comment, annotations *parser.Annotations, indent string) (string, string) {
+func (p *printer) formatCommentsAndAnnos(comments []*parser.Comment, annotations *parser.Annotations, indent string) (string, string) {
 	commentsStr := ""
 	if len(comments) > 0 {
 		commentsStr = MustFormatComments(comments, indent) + "\n"
 	}
 	annos := ""
 	if annotations != nil && len(annotations.Annotations) > 0 {
-		annos = " " + MustFormatAnnotations(annotations)
+		annos = " " + p.formatAnnotations(annotations)
 	}
 
 	return commentsStr, annos
@@ -49,6 +49,25 @@ func formatListSeparator(sep *parser.ListSeparatorKeyword) string {
 	return MustFormatKeyword(sep.Keyword)
 }
 
+// lineWidth returns display width of line. tab is counted as the indent size
+func (p *printer) lineWidth(line string) int {
+	tabWidth := p.opts.IndentSize
+	if tabWidth <= 0 {
+		tabWidth = len(Indent)
+	}
+
+	width := 0
+	for _, r := range line {
+		if r == '\t' {
+			width += tabWidth
+			continue
+		}
+		width++
+	}
+
+	return width
+}
+
 func lineDistance(preNode parser.Node, currentNode parser.Node) int {
 	return currentNode.Pos().Line - preNode.End().Line
 }
@@ -8,6 +8,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/joyme123/thrift-ls/lsp/mapper"
+	"github.com/joyme123/thrift-ls/lsp/types"
 	"go.lsp.dev/protocol"
 	"go.lsp.dev/uri"
 )
@@ -164,6 +166,14 @@ const (
 	FileChangeTypeDidOpen    FileChangeType = "DidOpen"
 	FileChangeTypeDidChange  FileChangeType = "DidChange"
 	FileChangeTypeDidSave    FileChangeType = "DidSave"
+	// FileChangeTypeWatchedChange is a file created or changed on disk by other tools
+	FileChangeTypeWatchedChange FileChangeType = "WatchedChange"
+	// FileChangeTypeWatchedDelete is a file deleted on disk by other tools
+	FileChangeTypeWatchedDelete FileChangeType = "WatchedDelete"
+	// FileChangeTypeConfigChange is a file parsed again because config of project is changed
+	FileChangeTypeConfigChange FileChangeType = "ConfigChange"
+	// FileChangeTypeExclude is a file excluded by config of project. it's parsed again only if it's included
+	FileChangeTypeExclude FileChangeType = "Exclude"
 )
 
 type FileChange struct {
@@ -171,22 +181,67 @@ type FileChange struct {
 	Version int
 	Content []byte
 	From    FileChangeType
+
+	// Range is the utf16-based range replaced by Content. Content is full content of file if Range is nil
+	Range *protocol.Range
+}
+
+// FullContent returns content of file after change is applied to base
+func (f *FileChange) FullContent(base []byte) ([]byte, error) {
+	if f.Range == nil {
+		return f.Content, nil
+	}
+
+	mp := mapper.NewMapper(f.URI, base)
+	start, err := positionToOffset(mp, base, f.Range.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := positionToOffset(mp, base, f.Range.End)
+	if err != nil {
+		return nil, err
+	}
+	if start > end {
+		return nil, fmt.Errorf("invalid range of change: %v", f.Range)
+	}
+
+	content := make([]byte, 0, len(base)-(end-start)+len(f.Content))
+	content = append(content, base[:start]...)
+	content = append(content, f.Content...)
+	content = append(content, base[end:]...)
+
+	return content, nil
 }
 
-func (f *FileChange) FullContent(base []byte) []byte {
-	// only support full change now
-	return f.Content
+// positionToOffset converts lsp position to byte offset. position after the last line is converted to
+// end of content
+func positionToOffset(mp *mapper.Mapper, content []byte, pos protocol.Position) (int, error) {
+	if int(pos.Line) > bytes.Count(content, []byte("\n")) {
+		return len(content), nil
+	}
+
+	return mp.LSPPosToOffset(types.Position{
+		Line:      pos.Line,
+		Character: pos.Character,
+	})
 }
 
-func FileChangeFromLSPDidChange(params *protocol.DidChangeTextDocumentParams) []*FileChange {
+// FileChangeFromLSPDidChange converts content changes to file changes in order. hasRange reports whether
+// each content change has range, content change without range is full content of file
+func FileChangeFromLSPDidChange(params *protocol.DidChangeTextDocumentParams, hasRange []bool) []*FileChange {
 	changes := make([]*FileChange, 0, len(params.ContentChanges))
 	for i := range params.ContentChanges {
-		changes = append(changes, &FileChange{
+		change := &FileChange{
 			URI:     params.TextDocument.TextDocumentIdentifier.URI,
 			Version: int(params.TextDocument.Version),
 			Content: []byte(params.ContentChanges[i].Text),
 			From:    FileChangeTypeDidChange,
-		})
+		}
+		if i < len(hasRange) && hasRange[i] {
+			rng := params.ContentChanges[i].Range
+			change.Range = &rng
+		}
+		changes = append(changes, change)
 	}
 	return changes
 }
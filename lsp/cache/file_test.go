@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestFileChangeFullContent(t *testing.T) {
+	base := "struct User {\n    1: string 名字\n}\n"
+	rng := func(startLine, startChar, endLine, endChar uint32) *protocol.Range {
+		return &protocol.Range{
+			Start: protocol.Position{Line: startLine, Character: startChar},
+			End:   protocol.Position{Line: endLine, Character: endChar},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		rng     *protocol.Range
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "full content",
+			content: "struct A {}\n",
+			want:    "struct A {}\n",
+		},
+		{
+			name:    "insert at beginning",
+			rng:     rng(0, 0, 0, 0),
+			content: "// user\n",
+			want:    "// user\nstruct User {\n    1: string 名字\n}\n",
+		},
+		{
+			name:    "insert after non ascii characters",
+			rng:     rng(1, 16, 1, 16),
+			content: "name",
+			want:    "struct User {\n    1: string 名字name\n}\n",
+		},
+		{
+			name:    "replace non ascii characters",
+			rng:     rng(1, 14, 1, 16),
+			content: "name",
+			want:    "struct User {\n    1: string name\n}\n",
+		},
+		{
+			name:    "delete lines",
+			rng:     rng(0, 13, 2, 0),
+			content: "",
+			want:    "struct User {}\n",
+		},
+		{
+			name:    "append at end",
+			rng:     rng(3, 0, 3, 0),
+			content: "struct A {}\n",
+			want:    "struct User {\n    1: string 名字\n}\nstruct A {}\n",
+		},
+		{
+			name:    "invalid range",
+			rng:     rng(1, 2, 0, 0),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := &FileChange{
+				URI:     uri.New("file:///tmp/user.thrift"),
+				Content: []byte(tt.content),
+				From:    FileChangeTypeDidChange,
+				Range:   tt.rng,
+			}
+			got, err := change.FullContent([]byte(base))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
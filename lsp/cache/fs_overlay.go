@@ -60,7 +60,11 @@ func (fs *overlayFS) Update(ctx context.Context, changes []*FileChange) error {
 				return err
 			}
 		}
-		overlay := NewOverlay(change.URI, change.FullContent(base), int32(change.Version))
+		content, err := change.FullContent(base)
+		if err != nil {
+			return err
+		}
+		overlay := NewOverlay(change.URI, content, int32(change.Version))
 
 		log.Debug("new overlay content: ", string(overlay.content), "uri", change.URI)
 
@@ -71,6 +75,16 @@ func (fs *overlayFS) Update(ctx context.Context, changes []*FileChange) error {
 	return nil
 }
 
+// Forget removes overlays of files, their contents are read from delegate later
+func (fs *overlayFS) Forget(uris []uri.URI) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	for _, fileURI := range uris {
+		delete(fs.overlays, fileURI)
+	}
+}
+
 // An Overlay is a file open in the editor. It may have unsaved edits.
 // It implements the source.FileHandle interface.
 type Overlay struct {
@@ -54,7 +54,8 @@ func (g *IncludeGraph) Get(file uri.URI) *IncludeNode {
 	return g.mapper[file]
 }
 
-func (g *IncludeGraph) Set(file uri.URI, includes []*parser.Include) {
+// Set sets files included by file. includes are resolved with includeDirs
+func (g *IncludeGraph) Set(file uri.URI, includes []*parser.Include, includeDirs ...string) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	includeURIs := make([]uri.URI, 0, len(includes))
@@ -63,7 +64,7 @@ func (g *IncludeGraph) Set(file uri.URI, includes []*parser.Include) {
 			continue
 		}
 
-		includeURI := lsputils.IncludeURI(file, inc.Path.Value.Text)
+		includeURI := lsputils.IncludeURI(file, inc.Path.Value.Text, includeDirs...)
 		includeURIs = append(includeURIs, includeURI)
 	}
 	sort.SliceStable(includeURIs, func(i, j int) bool {
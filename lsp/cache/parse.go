@@ -3,7 +3,9 @@ package cache
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/joyme123/thrift-ls/lsp/mapper"
 	"github.com/joyme123/thrift-ls/parser"
@@ -14,50 +16,133 @@ import (
 type ParseCaches struct {
 	mu     sync.RWMutex
 	caches map[uri.URI]*ParsedFile
-	tokens map[string]struct{}
+	// outdated holds forgotten parsed files, they are used to parse changed files incrementally
+	outdated map[uri.URI]*ParsedFile
+	tokens   map[string]struct{}
 }
 
 func NewParseCaches() *ParseCaches {
 	return &ParseCaches{
-		caches: make(map[uri.URI]*ParsedFile),
+		caches:   make(map[uri.URI]*ParsedFile),
+		outdated: make(map[uri.URI]*ParsedFile),
 	}
 }
 
 func (c *ParseCaches) Set(filePath uri.URI, res *ParsedFile) {
 	c.mu.Lock()
+	res.hold()
+	c.caches[filePath].drop()
 	c.caches[filePath] = res
+	c.outdated[filePath].drop()
+	delete(c.outdated, filePath)
 	c.tokens = nil
 	c.mu.Unlock()
 }
 
+// TakeOutdated returns the last forgotten parsed file of filePath. owned reports whether it isn't held by
+// other snapshots, then it's removed from caches and its ast can be changed by caller
+func (c *ParseCaches) TakeOutdated(filePath uri.URI) (pf *ParsedFile, owned bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pf = c.outdated[filePath]
+	if pf == nil || atomic.LoadInt32(&pf.shares) != 1 {
+		return pf, false
+	}
+	delete(c.outdated, filePath)
+	pf.drop()
+
+	return pf, true
+}
+
 func (c *ParseCaches) Get(filePath uri.URI) *ParsedFile {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return c.caches[filePath]
 }
 
+// Files returns uris of all parsed files in lexical order
+func (c *ParseCaches) Files() []uri.URI {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	res := make([]uri.URI, 0, len(c.caches))
+	for file := range c.caches {
+		res = append(res, file)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i] < res[j]
+	})
+
+	return res
+}
+
 func (c *ParseCaches) Forget(filePath uri.URI) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if parsed, ok := c.caches[filePath]; ok {
+		c.outdated[filePath].drop()
+		c.outdated[filePath] = parsed
+	}
 	delete(c.caches, filePath)
 	c.tokens = nil
 }
 
-func (c *ParseCaches) Clone() *ParseCaches {
+// Remove is called when file is removed. its parsed file isn't kept for incremental parsing
+func (c *ParseCaches) Remove(filePath uri.URI) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.caches[filePath].drop()
+	delete(c.caches, filePath)
+	c.outdated[filePath].drop()
+	delete(c.outdated, filePath)
+	c.tokens = nil
+}
+
+// Clone returns caches of a new snapshot with files. outdated parsed files of files which aren't in files
+// are dropped
+func (c *ParseCaches) Clone(files *FilesMap) *ParseCaches {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	clone := make(map[uri.URI]*ParsedFile)
 	for i := range c.caches {
+		c.caches[i].hold()
 		clone[i] = c.caches[i]
 	}
+	outdated := make(map[uri.URI]*ParsedFile)
+	for i := range c.outdated {
+		if _, ok := files.Get(i); !ok {
+			continue
+		}
+		c.outdated[i].hold()
+		outdated[i] = c.outdated[i]
+	}
 	newCaches := &ParseCaches{
-		caches: clone,
+		caches:   clone,
+		outdated: outdated,
 	}
 	return newCaches
 }
 
+// Destroy is called when snapshot isn't used any more. parsed files are released, and caches are empty
+func (c *ParseCaches) Destroy() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, parsed := range c.caches {
+		parsed.drop()
+	}
+	for _, parsed := range c.outdated {
+		parsed.drop()
+	}
+	c.caches = make(map[uri.URI]*ParsedFile)
+	c.outdated = make(map[uri.URI]*ParsedFile)
+	c.tokens = nil
+}
+
 func (c *ParseCaches) Tokens() map[string]struct{} {
 	if len(c.tokens) > 0 {
 		return c.tokens
@@ -158,6 +243,21 @@ type ParsedFile struct {
 
 	// errs hold all ast parsing errors
 	errs []parser.ParserError
+
+	// shares counts parse caches of snapshots holding parsed file
+	shares int32
+}
+
+func (p *ParsedFile) hold() {
+	if p != nil {
+		atomic.AddInt32(&p.shares, 1)
+	}
+}
+
+func (p *ParsedFile) drop() {
+	if p != nil {
+		atomic.AddInt32(&p.shares, -1)
+	}
 }
 
 func (p *ParsedFile) Mapper() *mapper.Mapper {
@@ -168,6 +268,11 @@ func (p *ParsedFile) AST() *parser.Document {
 	return p.ast
 }
 
+// FileIdentity returns identity of parsed content
+func (p *ParsedFile) FileIdentity() FileIdentity {
+	return p.fh.FileIdentity()
+}
+
 func (p *ParsedFile) Errors() []parser.ParserError {
 	return p.errs
 }
@@ -191,6 +296,36 @@ func (p *ParsedFile) DumpAST() {
 
 // TODO(jpf): use promise
 func Parse(fh FileHandle) (*ParsedFile, error) {
+	return parse(fh, func(psr *parser.PEGParser, filename string, content []byte) (*parser.Document, []error) {
+		return psr.Parse(filename, content)
+	})
+}
+
+// ParseIncremental parses fh based on prev, which is parsed from an older content of the same file.
+// Only definitions overlapping changed text are parsed again. If prev is owned by caller, ast of prev is
+// reused and prev can't be used any more, otherwise it's kept unchanged
+func ParseIncremental(fh FileHandle, prev *ParsedFile, owned bool) (*ParsedFile, error) {
+	if prev == nil || prev.ast == nil {
+		return Parse(fh)
+	}
+	oldContent, err := prev.fh.Content()
+	if err != nil {
+		return Parse(fh)
+	}
+	oldErrs := make([]error, 0, len(prev.errs))
+	for i := range prev.errs {
+		oldErrs = append(oldErrs, prev.errs[i])
+	}
+
+	return parse(fh, func(psr *parser.PEGParser, filename string, content []byte) (*parser.Document, []error) {
+		if owned {
+			return psr.ReparseInPlace(filename, content, prev.ast, oldContent, oldErrs)
+		}
+		return psr.Reparse(filename, content, prev.ast, oldContent, oldErrs)
+	})
+}
+
+func parse(fh FileHandle, parseFn func(psr *parser.PEGParser, filename string, content []byte) (*parser.Document, []error)) (*ParsedFile, error) {
 	content, err := fh.Content()
 	if err != nil {
 		return nil, err
@@ -202,7 +337,7 @@ func Parse(fh FileHandle) (*ParsedFile, error) {
 
 	psr := &parser.PEGParser{}
 
-	ast, errs := psr.Parse(fh.URI().Filename(), content)
+	ast, errs := parseFn(psr, fh.URI().Filename(), content)
 	for i := range errs {
 		parserErr, ok := errs[i].(parser.ParserError)
 		if ok {
@@ -1,9 +1,12 @@
 package cache
 
 import (
+	"context"
 	"testing"
 
+	"github.com/joyme123/thrift-ls/lsp/memoize"
 	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/uri"
 )
 
 func TestParse(t *testing.T) {
@@ -67,3 +70,111 @@ struct Xtruct3
 		})
 	}
 }
+
+func TestParseIncremental(t *testing.T) {
+	oldContent := "struct User {\n    1: string name\n}\n\nenum Status {\n    OK = 1\n}\n"
+	content := "struct User {\n    1: string name\n    2: i64 id\n}\n\nenum Status {\n    OK = 1\n}\n"
+
+	prev, err := Parse(&Overlay{uri: "file:///tmp/user.thrift", content: []byte(oldContent)})
+	assert.NoError(t, err)
+
+	fh := &Overlay{uri: "file:///tmp/user.thrift", content: []byte(content), version: 1}
+	got, err := ParseIncremental(fh, prev, false)
+	assert.NoError(t, err)
+	want, err := Parse(fh)
+	assert.NoError(t, err)
+
+	assert.Equal(t, want.AST(), got.AST())
+	assert.Equal(t, want.Errors(), got.Errors())
+	assert.Equal(t, fh.FileIdentity(), got.FileIdentity())
+	// enum after changed struct is moved to next line, its location starts from its leading newline
+	assert.Equal(t, 5, got.AST().Enums[0].Location.StartPos.Line)
+}
+
+func TestParseIncrementalSharedAST(t *testing.T) {
+	ctx := context.TODO()
+	file := uri.URI("file:///tmp/user.thrift")
+	contents := []string{
+		"struct User {\n    1: string name\n}\n\nenum Status {\n    OK = 1\n}\n\nconst i32 Max = 1\n",
+		"struct User {\n    1: string name\n    2: i64 id\n}\n\nenum Status {\n    OK = 1\n}\n\nconst i32 Max = 1\n",
+		"struct User {\n    1: string name\n    2: i64 id\n    3: i64 age\n}\n\nenum Status {\n    OK = 1\n}\n\nconst i32 Max = 1\n",
+	}
+
+	store := &memoize.Store{}
+	fs := NewOverlayFS(New(store))
+	view := NewView("test", "file:///tmp", ViewOptions{}, fs, store)
+	change := func(version int) *ParsedFile {
+		from := FileChangeTypeDidChange
+		if version == 0 {
+			from = FileChangeTypeDidOpen
+		}
+		changes := []*FileChange{{URI: file, Version: version, Content: []byte(contents[version]), From: from}}
+		assert.NoError(t, fs.Update(ctx, changes))
+		view.FileChange(ctx, changes)
+
+		ss, release := view.Snapshot()
+		defer release()
+		pf, err := ss.Parse(ctx, file)
+		assert.NoError(t, err)
+
+		return pf
+	}
+	parse := func(version int) *ParsedFile {
+		pf, err := Parse(&Overlay{uri: file, content: []byte(contents[version]), version: int32(version)})
+		assert.NoError(t, err)
+		return pf
+	}
+
+	first := change(0)
+	// ast of snapshot in use isn't changed
+	_, release := view.Snapshot()
+	second := change(1)
+	assert.Equal(t, parse(0).AST(), first.AST())
+	assert.Equal(t, parse(1).AST(), second.AST())
+	assert.NotSame(t, first.AST().Consts[0], second.AST().Consts[0])
+	release()
+
+	// ast which isn't used by any snapshot is reused
+	third := change(2)
+	assert.Equal(t, parse(2).AST(), third.AST())
+	assert.Same(t, second.AST().Consts[0], third.AST().Consts[0])
+}
+
+func TestParseCachesOutdated(t *testing.T) {
+	user := uri.URI("file:///tmp/user.thrift")
+	deleted := uri.URI("file:///tmp/deleted.thrift")
+	unknown := uri.URI("file:///tmp/unknown.thrift")
+
+	files := &FilesMap{files: make(map[uri.URI]FileHandle), overlays: make(map[uri.URI]*Overlay)}
+	caches := NewParseCaches()
+	for _, file := range []uri.URI{user, deleted, unknown} {
+		fh := &Overlay{uri: file, content: []byte("struct User {\n    1: string name\n}\n")}
+		pf, err := Parse(fh)
+		assert.NoError(t, err)
+		caches.Set(file, pf)
+		caches.Forget(file)
+		files.Set(file, fh)
+	}
+
+	// parsed files of removed files aren't kept
+	caches.Remove(deleted)
+	pf, _ := caches.TakeOutdated(deleted)
+	assert.Nil(t, pf)
+
+	// outdated parsed files of files which aren't in snapshot any more aren't cloned
+	files.Forget(unknown)
+	clone := caches.Clone(files)
+	pf, _ = clone.TakeOutdated(unknown)
+	assert.Nil(t, pf)
+	pf, owned := clone.TakeOutdated(user)
+	assert.NotNil(t, pf)
+	assert.False(t, owned)
+
+	// parsed file is owned by the last snapshot holding it
+	caches.Destroy()
+	pf, owned = clone.TakeOutdated(user)
+	assert.NotNil(t, pf)
+	assert.True(t, owned)
+	pf, _ = clone.TakeOutdated(user)
+	assert.Nil(t, pf)
+}
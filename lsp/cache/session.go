@@ -51,9 +51,23 @@ func (s *Session) Initialize(fn func()) {
 	fn()
 }
 
-func (s *Session) CreateView(folder uri.URI) {
-	view := NewView(folder.Filename(), folder, s.overlayFS, s.cache.store)
+// CreateView creates view of folder with options from config of folder
+func (s *Session) CreateView(folder uri.URI, opts ViewOptions) {
+	view := NewView(folder.Filename(), folder, opts, s.overlayFS, s.cache.store)
+	s.viewMu.Lock()
 	s.views = append(s.views, view)
+	s.viewMu.Unlock()
+}
+
+// Views returns all views of session
+func (s *Session) Views() []*View {
+	s.viewMu.Lock()
+	defer s.viewMu.Unlock()
+
+	res := make([]*View, len(s.views))
+	copy(res, s.views)
+
+	return res
 }
 
 func (s *Session) ViewOf(fileURI uri.URI) (*View, error) {
@@ -88,3 +102,8 @@ func (s *Session) ViewOf(fileURI uri.URI) (*View, error) {
 func (s *Session) UpdateOverlayFS(ctx context.Context, changes []*FileChange) error {
 	return s.overlayFS.Update(ctx, changes)
 }
+
+// ForgetOverlayFS removes overlays of files changed on disk
+func (s *Session) ForgetOverlayFS(uris []uri.URI) {
+	s.overlayFS.Forget(uris)
+}
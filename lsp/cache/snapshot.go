@@ -4,6 +4,7 @@ import (
 	"context"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 
 	"github.com/joyme123/thrift-ls/lsp/memoize"
 	log "github.com/sirupsen/logrus"
@@ -18,7 +19,8 @@ type Snapshot struct {
 	// ctx is used to cancel background job
 	ctx context.Context
 
-	refCount sync.WaitGroup
+	// refs counts users of snapshot. snapshot is destroyed when it isn't used any more
+	refs int32
 
 	files *FilesMap
 
@@ -34,7 +36,6 @@ func NewSnapshot(view *View, store *memoize.Store) *Snapshot {
 		view:        view,
 		store:       store,
 		ctx:         context.Background(),
-		refCount:    sync.WaitGroup{},
 		graph:       NewIncludeGraph(),
 		parsedCache: NewParseCaches(),
 		files: &FilesMap{
@@ -47,8 +48,21 @@ func NewSnapshot(view *View, store *memoize.Store) *Snapshot {
 }
 
 func (s *Snapshot) Acquire() func() {
-	s.refCount.Add(1)
-	return s.refCount.Done
+	atomic.AddInt32(&s.refs, 1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if atomic.AddInt32(&s.refs, -1) == 0 {
+				s.destroy()
+			}
+		})
+	}
+}
+
+// destroy releases parsed files of snapshot, so parsed files which aren't shared by other snapshots can be
+// reused by incremental parsing
+func (s *Snapshot) destroy() {
+	s.parsedCache.Destroy()
 }
 
 func (s *Snapshot) Initialize(ctx context.Context) {
@@ -85,6 +99,14 @@ func (s *Snapshot) ForgetFile(uri uri.URI) {
 	s.parsedCache.Forget(uri)
 }
 
+// RemoveFile is called when file is deleted or excluded. different from ForgetFile, its parsed file isn't
+// kept for incremental parsing
+func (s *Snapshot) RemoveFile(uri uri.URI) {
+	s.files.Forget(uri)
+	s.graph.Remove(uri)
+	s.parsedCache.Remove(uri)
+}
+
 func (s *Snapshot) Parse(ctx context.Context, uri uri.URI) (*ParsedFile, error) {
 	if parsedFile := s.parsedCache.Get(uri); parsedFile != nil {
 		return parsedFile, nil
@@ -99,37 +121,59 @@ func (s *Snapshot) Parse(ctx context.Context, uri uri.URI) (*ParsedFile, error)
 	// content, _ := fh.Content()
 	// log.Debugln("parse content:", string(content))
 
-	pf, err := Parse(fh)
+	prev, owned := s.parsedCache.TakeOutdated(uri)
+	pf, err := ParseIncremental(fh, prev, owned)
 	if err != nil {
 		log.Debugf("snapshot parse err: %v", err)
 		return nil, err
 	}
 
 	if pf.AST() != nil {
-		s.graph.Set(uri, pf.AST().Includes)
+		s.graph.Set(uri, pf.AST().Includes, s.IncludeDirs()...)
 	}
 	s.parsedCache.Set(uri, pf)
 
 	return pf, nil
 }
 
+// IncludeDirs returns directories searched for included files, like `thrift -I`
+func (s *Snapshot) IncludeDirs() []string {
+	return s.view.IncludeDirs()
+}
+
+// Dialect returns the target thrift dialect
+func (s *Snapshot) Dialect() string {
+	return s.view.Dialect()
+}
+
+// Folder returns workspace folder of snapshot
+func (s *Snapshot) Folder() uri.URI {
+	return s.view.Folder()
+}
+
+// ParsedFiles returns all files parsed in snapshot, including files of workspace and their includes
+func (s *Snapshot) ParsedFiles() []uri.URI {
+	return s.parsedCache.Files()
+}
+
 func (s *Snapshot) Tokens() map[string]struct{} {
 	return s.parsedCache.Tokens()
 }
 
 func (s *Snapshot) clone() (*Snapshot, func()) {
+	files := s.files.Clone()
 	snap := &Snapshot{
 		id:   rand.Int63(),
 		view: s.view,
 		ctx:  context.Background(),
 		// TODO(jpf): file change 没有更新，导致读到旧的缓存
-		files: s.files.Clone(),
+		files: files,
 		// files: &FilesMap{
 		// 	files:    make(map[uri.URI]FileHandle),
 		// 	overlays: make(map[uri.URI]*Overlay),
 		// },
 		graph:       s.graph.Clone(),
-		parsedCache: s.parsedCache.Clone(),
+		parsedCache: s.parsedCache.Clone(files),
 	}
 
 	return snap, snap.Acquire()
@@ -141,7 +185,7 @@ func BuildSnapshotForTest(files []*FileChange) *Snapshot {
 	fs := NewOverlayFS(c)
 	fs.Update(context.TODO(), files)
 
-	view := NewView("test", "file:///tmp", fs, store)
+	view := NewView("test", "file:///tmp", ViewOptions{}, fs, store)
 	ss := NewSnapshot(view, store)
 
 	for _, f := range files {
@@ -21,6 +21,9 @@ type View struct {
 	// workspace folder
 	folder uri.URI
 
+	optionsMu sync.RWMutex
+	options   ViewOptions
+
 	fs FileSource
 
 	knownFilesMu sync.Mutex
@@ -39,11 +42,28 @@ type View struct {
 	snapshotRelease func()
 }
 
-func NewView(name string, folder uri.URI, fs FileSource, store *memoize.Store) *View {
+// Thrift dialects. Default dialect is DialectApache
+const (
+	// DialectApache is Apache Thrift, uuid is a base type since 0.19
+	DialectApache = "apache"
+	// DialectThriftgo is thriftgo of cloudwego
+	DialectThriftgo = "thriftgo"
+)
+
+// ViewOptions are options of view from project config
+type ViewOptions struct {
+	// IncludeDirs are absolute directories searched for included files
+	IncludeDirs []string
+	// Dialect is the target thrift dialect
+	Dialect string
+}
+
+func NewView(name string, folder uri.URI, opts ViewOptions, fs FileSource, store *memoize.Store) *View {
 	view := &View{
 		id:         rand.Int63(),
 		name:       name,
 		folder:     folder,
+		options:    opts,
 		fs:         fs,
 		knownFiles: make(map[uri.URI]bool),
 	}
@@ -65,6 +85,38 @@ func NewView(name string, folder uri.URI, fs FileSource, store *memoize.Store) *
 	return view
 }
 
+// Folder returns workspace folder of view
+func (v *View) Folder() uri.URI {
+	return v.folder
+}
+
+// Options returns options of view
+func (v *View) Options() ViewOptions {
+	v.optionsMu.RLock()
+	defer v.optionsMu.RUnlock()
+	return v.options
+}
+
+// SetOptions changes options of view. files parsed with previous options should be changed to be parsed again
+func (v *View) SetOptions(opts ViewOptions) {
+	v.optionsMu.Lock()
+	defer v.optionsMu.Unlock()
+	v.options = opts
+}
+
+// IncludeDirs returns directories searched for included files
+func (v *View) IncludeDirs() []string {
+	return v.Options().IncludeDirs
+}
+
+// Dialect returns the target thrift dialect
+func (v *View) Dialect() string {
+	if dialect := v.Options().Dialect; dialect != "" {
+		return dialect
+	}
+	return DialectApache
+}
+
 func (v *View) ContainsFile(uri uri.URI) bool {
 	// folder: file:///workdir/
 	// file: file:///workdir/file.idl
@@ -111,14 +163,18 @@ func (v *View) FileChange(ctx context.Context, changes []*FileChange, postFns ..
 
 	// snapshot clone
 	newSnapshot, release := v.snapshot.clone()
-	// release previous snapshot
-	v.snapshotRelease()
 	v.snapshotMu.Lock()
 	v.snapshot = newSnapshot
 	for _, change := range changes {
+		if change.From == FileChangeTypeWatchedDelete || change.From == FileChangeTypeExclude {
+			v.snapshot.RemoveFile(change.URI)
+			continue
+		}
 		v.snapshot.ForgetFile(change.URI)
 	}
 	v.snapshotMu.Unlock()
+	// release previous snapshot after it's replaced, so it can't be acquired again
+	v.snapshotRelease()
 	v.snapshotRelease = release
 
 	asyncRelease := v.snapshot.Acquire()
@@ -129,6 +185,11 @@ func (v *View) FileChange(ctx context.Context, changes []*FileChange, postFns ..
 	defer asyncRelease()
 	uris := make(map[uri.URI]struct{})
 	for _, change := range changes {
+		// deleted files can't be parsed, and excluded files are parsed only if they're included
+		if change.From == FileChangeTypeWatchedDelete || change.From == FileChangeTypeExclude {
+			delete(uris, change.URI)
+			continue
+		}
 		uris[change.URI] = struct{}{}
 	}
 	for uri := range uris {
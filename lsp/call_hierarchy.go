@@ -0,0 +1,42 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/joyme123/thrift-ls/lsp/callhierarchy"
+	"go.lsp.dev/protocol"
+)
+
+func (s *Server) prepareCallHierarchy(ctx context.Context, params *protocol.CallHierarchyPrepareParams) ([]protocol.CallHierarchyItem, error) {
+	file := params.TextDocument.URI
+	view, err := s.session.ViewOf(file)
+	if err != nil {
+		return nil, err
+	}
+	ss, release := view.Snapshot()
+	defer release()
+
+	return callhierarchy.PrepareCallHierarchy(ctx, ss, file, params.Position)
+}
+
+func (s *Server) incomingCalls(ctx context.Context, params *protocol.CallHierarchyIncomingCallsParams) ([]protocol.CallHierarchyIncomingCall, error) {
+	view, err := s.session.ViewOf(params.Item.URI)
+	if err != nil {
+		return nil, err
+	}
+	ss, release := view.Snapshot()
+	defer release()
+
+	return callhierarchy.IncomingCalls(ctx, ss, params.Item)
+}
+
+func (s *Server) outgoingCalls(ctx context.Context, params *protocol.CallHierarchyOutgoingCallsParams) ([]protocol.CallHierarchyOutgoingCall, error) {
+	view, err := s.session.ViewOf(params.Item.URI)
+	if err != nil {
+		return nil, err
+	}
+	ss, release := view.Snapshot()
+	defer release()
+
+	return callhierarchy.OutgoingCalls(ctx, ss, params.Item)
+}
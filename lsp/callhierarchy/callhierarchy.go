@@ -0,0 +1,301 @@
+package callhierarchy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/codejump"
+	"github.com/joyme123/thrift-ls/lsp/lsputils"
+	"github.com/joyme123/thrift-ls/lsp/types"
+	"github.com/joyme123/thrift-ls/parser"
+	"github.com/joyme123/thrift-ls/utils"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// types of call hierarchy item. Relationships of thrift are modeled as calls:
+//   - service is called by services which extend it
+//   - function calls structs, unions and exceptions used by its arguments, return type and throws
+//   - struct, union and exception are called by functions and structs which use them
+const (
+	typeService   = "Service"
+	typeFunction  = "Function"
+	typeStruct    = "Struct"
+	typeUnion     = "Union"
+	typeException = "Exception"
+)
+
+// itemData is data of call hierarchy item. It is sent back by client in incoming and outgoing
+// calls requests, and used to find definition of item
+type itemData struct {
+	Type string `json:"type"`
+	// Service is the service name of function
+	Service string `json:"service,omitempty"`
+}
+
+// PrepareCallHierarchy returns item of definition under cursor. Cursor can be on the name of definition,
+// or on a reference of service or struct
+func PrepareCallHierarchy(ctx context.Context, ss *cache.Snapshot, file uri.URI, pos protocol.Position) ([]protocol.CallHierarchyItem, error) {
+	pf, err := ss.Parse(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	if pf.AST() == nil {
+		return nil, errors.New("parse ast failed")
+	}
+
+	astPos, err := pf.Mapper().LSPPosToParserPosition(types.Position{Line: pos.Line, Character: pos.Character})
+	if err != nil {
+		return nil, err
+	}
+	nodePath := parser.SearchNodePathByPosition(pf.AST(), astPos)
+	targetNode := nodePath[len(nodePath)-1]
+
+	switch targetNode.Type() {
+	case "TypeName":
+		dstFile, id, kind, err := codejump.TypeNameDefinitionIdentifier(ctx, ss, file, pf.AST(), targetNode)
+		if err != nil || id == nil {
+			return nil, err
+		}
+		return definitionItems(ctx, ss, dstFile, itemData{Type: kind}, id.Name.Text)
+	case "IdentifierName":
+		if len(nodePath) < 3 {
+			return nil, nil
+		}
+		identifier := nodePath[len(nodePath)-2]
+		switch definition := nodePath[len(nodePath)-3].(type) {
+		case *parser.Service:
+			if definition.Extends != nil && parser.Node(definition.Extends) == identifier {
+				dstFile, id, _, err := codejump.ServiceDefinitionIdentifier(ctx, ss, file, pf.AST(), targetNode)
+				if err != nil || id == nil {
+					return nil, err
+				}
+				return definitionItems(ctx, ss, dstFile, itemData{Type: typeService}, id.Name.Text)
+			}
+			return validItems(newItem(file, definition, nil))
+		case *parser.Function:
+			svc, ok := nodePath[len(nodePath)-4].(*parser.Service)
+			if !ok {
+				return nil, nil
+			}
+			return validItems(newItem(file, definition, svc))
+		case *parser.Struct, *parser.Union, *parser.Exception:
+			return validItems(newItem(file, definition, nil))
+		}
+	}
+
+	return nil, nil
+}
+
+func definitionItems(ctx context.Context, ss *cache.Snapshot, file uri.URI, data itemData, name string) ([]protocol.CallHierarchyItem, error) {
+	node, svc, err := lookup(ctx, ss, file, data, name)
+	if err != nil || node == nil {
+		return nil, err
+	}
+
+	return validItems(newItem(file, node, svc))
+}
+
+func validItems(item *protocol.CallHierarchyItem) ([]protocol.CallHierarchyItem, error) {
+	if item == nil {
+		return nil, nil
+	}
+	return []protocol.CallHierarchyItem{*item}, nil
+}
+
+// lookup returns definition of item in file. Service of function is returned too
+func lookup(ctx context.Context, ss *cache.Snapshot, file uri.URI, data itemData, name string) (parser.Node, *parser.Service, error) {
+	pf, err := ss.Parse(ctx, file)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pf.AST() == nil {
+		return nil, nil, errors.New("parse ast failed")
+	}
+	ast := pf.AST()
+
+	switch data.Type {
+	case typeService:
+		if svc := codejump.GetServiceNode(ast, name); svc != nil {
+			return svc, nil, nil
+		}
+	case typeFunction:
+		svc := codejump.GetServiceNode(ast, data.Service)
+		if svc == nil {
+			return nil, nil, nil
+		}
+		for _, fn := range svc.Functions {
+			if fn != nil && !fn.BadNode && validIdentifier(fn.Name) && fn.Name.Name.Text == name {
+				return fn, svc, nil
+			}
+		}
+	case typeStruct:
+		if st := codejump.GetStructNode(ast, name); st != nil {
+			return st, nil, nil
+		}
+	case typeUnion:
+		if union := codejump.GetUnionNode(ast, name); union != nil {
+			return union, nil, nil
+		}
+	case typeException:
+		if excep := codejump.GetExceptionNode(ast, name); excep != nil {
+			return excep, nil, nil
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// newItem returns call hierarchy item of definition. svc is required for function
+func newItem(file uri.URI, node parser.Node, svc *parser.Service) *protocol.CallHierarchyItem {
+	var (
+		id     *parser.Identifier
+		kind   protocol.SymbolKind
+		detail = lsputils.GetIncludeName(file)
+		data   itemData
+	)
+
+	switch node := node.(type) {
+	case *parser.Service:
+		id, kind, data = node.Name, protocol.SymbolKindInterface, itemData{Type: typeService}
+	case *parser.Function:
+		if svc == nil || !validIdentifier(svc.Name) {
+			return nil
+		}
+		id, kind, data = node.Name, protocol.SymbolKindMethod, itemData{Type: typeFunction, Service: svc.Name.Name.Text}
+		detail = detail + "." + svc.Name.Name.Text
+	case *parser.Struct:
+		id, kind, data = node.Identifier, protocol.SymbolKindStruct, itemData{Type: typeStruct}
+	case *parser.Union:
+		id, kind, data = node.Name, protocol.SymbolKindStruct, itemData{Type: typeUnion}
+	case *parser.Exception:
+		id, kind, data = node.Name, protocol.SymbolKindClass, itemData{Type: typeException}
+	default:
+		return nil
+	}
+
+	if node.IsBadNode() || !validIdentifier(id) {
+		return nil
+	}
+
+	return &protocol.CallHierarchyItem{
+		Name:           id.Name.Text,
+		Kind:           kind,
+		Detail:         detail,
+		URI:            file,
+		Range:          definitionRange(node),
+		SelectionRange: lsputils.ASTNodeToRange(id.Name),
+		Data:           data,
+	}
+}
+
+// definitionRange returns range of definition from its leading comments to the closing bracket.
+// location of definition node contains surrounding whitespaces, so it isn't used directly
+func definitionRange(node parser.Node) protocol.Range {
+	var (
+		comments []*parser.Comment
+		start    *parser.KeywordLiteral
+		end      *parser.KeywordLiteral
+	)
+	switch node := node.(type) {
+	case *parser.Service:
+		comments = node.Comments
+		start, end = keywordLiteral(node.ServiceKeyword), keywordLiteral(node.RCurKeyword)
+	case *parser.Struct:
+		comments = node.Comments
+		start, end = keywordLiteral(node.StructKeyword), keywordLiteral(node.RCurKeyword)
+	case *parser.Union:
+		comments = node.Comments
+		start, end = keywordLiteral(node.UnionKeyword), keywordLiteral(node.RCurKeyword)
+	case *parser.Exception:
+		comments = node.Comments
+		start, end = keywordLiteral(node.ExceptionKeyword), keywordLiteral(node.RCurKeyword)
+	case *parser.Function:
+		return functionRange(node)
+	}
+	if start == nil || end == nil {
+		return lsputils.ASTNodeToRange(node)
+	}
+
+	startPos := start.Pos()
+	if len(comments) > 0 && comments[0] != nil {
+		startPos = comments[0].Pos()
+	}
+
+	return positionsToRange(startPos, end.End())
+}
+
+func functionRange(fn *parser.Function) protocol.Range {
+	var startPos parser.Position
+	switch {
+	case fn.Oneway != nil && fn.Oneway.Literal != nil:
+		startPos = fn.Oneway.Literal.Pos()
+	case fn.Void != nil && fn.Void.Literal != nil:
+		startPos = fn.Void.Literal.Pos()
+	case fn.FunctionType != nil && fn.FunctionType.TypeName != nil:
+		startPos = fn.FunctionType.TypeName.Pos()
+	default:
+		return lsputils.ASTNodeToRange(fn)
+	}
+	if len(fn.Comments) > 0 && fn.Comments[0] != nil {
+		startPos = fn.Comments[0].Pos()
+	}
+
+	end := keywordLiteral(fn.RParKeyword)
+	if fn.Throws != nil && keywordLiteral(fn.Throws.RParKeyword) != nil {
+		end = keywordLiteral(fn.Throws.RParKeyword)
+	}
+	if end == nil {
+		return lsputils.ASTNodeToRange(fn)
+	}
+
+	return positionsToRange(startPos, end.End())
+}
+
+// keywordLiteral returns literal of keyword, it doesn't contain comments of keyword
+func keywordLiteral(node interface{ GetKeyword() *parser.Keyword }) *parser.KeywordLiteral {
+	if node == nil || utils.IsNil(node) {
+		return nil
+	}
+	keyword := node.GetKeyword()
+	if keyword == nil || keyword.BadNode {
+		return nil
+	}
+	return keyword.Literal
+}
+
+func positionsToRange(start, end parser.Position) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{
+			Line:      uint32(start.Line - 1),
+			Character: uint32(start.Col - 1),
+		},
+		End: protocol.Position{
+			Line:      uint32(end.Line - 1),
+			Character: uint32(end.Col - 1),
+		},
+	}
+}
+
+// dataOf decodes data of item. data is decoded from json if it is sent back by client
+func dataOf(item protocol.CallHierarchyItem) (itemData, error) {
+	if data, ok := item.Data.(itemData); ok {
+		return data, nil
+	}
+
+	var data itemData
+	raw, err := json.Marshal(item.Data)
+	if err != nil {
+		return data, err
+	}
+	err = json.Unmarshal(raw, &data)
+
+	return data, err
+}
+
+func validIdentifier(id *parser.Identifier) bool {
+	return id != nil && !id.BadNode && id.Name != nil && id.Name.Text != ""
+}
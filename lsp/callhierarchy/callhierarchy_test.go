@@ -0,0 +1,215 @@
+package callhierarchy
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func buildSnapshot() *cache.Snapshot {
+	baseFile := `struct User {
+  1: string name
+}
+
+exception NotFound {}
+
+service BaseService {}`
+
+	apiFile := `include "base.thrift"
+
+struct Group {
+  1: list<base.User> users
+}
+
+service Api extends base.BaseService {
+  base.User Get(1: i64 id, 2: base.User user) throws (1: base.NotFound e)
+  void Ping()
+}`
+
+	return cache.BuildSnapshotForTest([]*cache.FileChange{
+		{
+			URI:     "file:///tmp/base.thrift",
+			Version: 0,
+			Content: []byte(baseFile),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+		{
+			URI:     "file:///tmp/api.thrift",
+			Version: 0,
+			Content: []byte(apiFile),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	})
+}
+
+func newRange(startLine, startChar, endLine, endChar uint32) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: startLine, Character: startChar},
+		End:   protocol.Position{Line: endLine, Character: endChar},
+	}
+}
+
+func TestPrepareCallHierarchy(t *testing.T) {
+	ss := buildSnapshot()
+
+	userItem := protocol.CallHierarchyItem{
+		Name:           "User",
+		Kind:           protocol.SymbolKindStruct,
+		Detail:         "base",
+		URI:            "file:///tmp/base.thrift",
+		Range:          newRange(0, 0, 2, 1),
+		SelectionRange: newRange(0, 7, 0, 11),
+		Data:           itemData{Type: typeStruct},
+	}
+
+	tests := []struct {
+		name string
+		file uri.URI
+		pos  protocol.Position
+		want []protocol.CallHierarchyItem
+	}{
+		{
+			name: "struct definition",
+			file: "file:///tmp/base.thrift",
+			pos:  protocol.Position{Line: 0, Character: 8},
+			want: []protocol.CallHierarchyItem{userItem},
+		},
+		{
+			name: "struct reference",
+			file: "file:///tmp/api.thrift",
+			pos:  protocol.Position{Line: 7, Character: 4},
+			want: []protocol.CallHierarchyItem{userItem},
+		},
+		{
+			name: "extended service",
+			file: "file:///tmp/api.thrift",
+			pos:  protocol.Position{Line: 6, Character: 25},
+			want: []protocol.CallHierarchyItem{
+				{
+					Name:           "BaseService",
+					Kind:           protocol.SymbolKindInterface,
+					Detail:         "base",
+					URI:            "file:///tmp/base.thrift",
+					Range:          newRange(6, 0, 6, 22),
+					SelectionRange: newRange(6, 8, 6, 19),
+					Data:           itemData{Type: typeService},
+				},
+			},
+		},
+		{
+			name: "function",
+			file: "file:///tmp/api.thrift",
+			pos:  protocol.Position{Line: 7, Character: 13},
+			want: []protocol.CallHierarchyItem{
+				{
+					Name:           "Get",
+					Kind:           protocol.SymbolKindMethod,
+					Detail:         "api.Api",
+					URI:            "file:///tmp/api.thrift",
+					Range:          newRange(7, 2, 7, 73),
+					SelectionRange: newRange(7, 12, 7, 15),
+					Data:           itemData{Type: typeFunction, Service: "Api"},
+				},
+			},
+		},
+		{
+			name: "basic type",
+			file: "file:///tmp/api.thrift",
+			pos:  protocol.Position{Line: 7, Character: 20},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PrepareCallHierarchy(context.TODO(), ss, tt.file, tt.pos)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCalls(t *testing.T) {
+	ss := buildSnapshot()
+
+	type call struct {
+		name   string
+		ranges []protocol.Range
+	}
+
+	prepare := func(file uri.URI, line, character uint32) protocol.CallHierarchyItem {
+		items, err := PrepareCallHierarchy(context.TODO(), ss, file, protocol.Position{Line: line, Character: character})
+		assert.NoError(t, err)
+		assert.Len(t, items, 1)
+
+		// data is sent back by client as json
+		data, err := json.Marshal(items[0])
+		assert.NoError(t, err)
+		var item protocol.CallHierarchyItem
+		assert.NoError(t, json.Unmarshal(data, &item))
+		return item
+	}
+
+	tests := []struct {
+		name     string
+		item     protocol.CallHierarchyItem
+		incoming []call
+		outgoing []call
+	}{
+		{
+			name: "struct",
+			item: prepare("file:///tmp/base.thrift", 0, 8),
+			incoming: []call{
+				{name: "Group", ranges: []protocol.Range{newRange(3, 10, 3, 19)}},
+				{name: "Get", ranges: []protocol.Range{newRange(7, 2, 7, 11), newRange(7, 30, 7, 39)}},
+			},
+			outgoing: []call{},
+		},
+		{
+			name: "service",
+			item: prepare("file:///tmp/base.thrift", 6, 10),
+			incoming: []call{
+				{name: "Api", ranges: []protocol.Range{newRange(6, 20, 6, 36)}},
+			},
+			outgoing: []call{},
+		},
+		{
+			name:     "function",
+			item:     prepare("file:///tmp/api.thrift", 7, 13),
+			incoming: []call{},
+			outgoing: []call{
+				{name: "User", ranges: []protocol.Range{newRange(7, 2, 7, 11), newRange(7, 30, 7, 39)}},
+				{name: "NotFound", ranges: []protocol.Range{newRange(7, 57, 7, 70)}},
+			},
+		},
+		{
+			name:     "function without types",
+			item:     prepare("file:///tmp/api.thrift", 8, 8),
+			incoming: []call{},
+			outgoing: []call{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			incoming, err := IncomingCalls(context.TODO(), ss, tt.item)
+			assert.NoError(t, err)
+			gotIncoming := make([]call, 0)
+			for _, item := range incoming {
+				gotIncoming = append(gotIncoming, call{name: item.From.Name, ranges: item.FromRanges})
+			}
+			assert.Equal(t, tt.incoming, gotIncoming)
+
+			outgoing, err := OutgoingCalls(context.TODO(), ss, tt.item)
+			assert.NoError(t, err)
+			gotOutgoing := make([]call, 0)
+			for _, item := range outgoing {
+				gotOutgoing = append(gotOutgoing, call{name: item.To.Name, ranges: item.FromRanges})
+			}
+			assert.Equal(t, tt.outgoing, gotOutgoing)
+		})
+	}
+}
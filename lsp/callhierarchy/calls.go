@@ -0,0 +1,276 @@
+package callhierarchy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/codejump"
+	"github.com/joyme123/thrift-ls/lsp/lsputils"
+	"github.com/joyme123/thrift-ls/parser"
+	utilerrors "github.com/joyme123/thrift-ls/utils/errors"
+	log "github.com/sirupsen/logrus"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// IncomingCalls returns services which extend service, or functions and structs which use struct
+func IncomingCalls(ctx context.Context, ss *cache.Snapshot, item protocol.CallHierarchyItem) ([]protocol.CallHierarchyIncomingCall, error) {
+	res := make([]protocol.CallHierarchyIncomingCall, 0)
+	data, err := dataOf(item)
+	if err != nil {
+		return res, err
+	}
+
+	var search func(file uri.URI, ast *parser.Document, name string) []protocol.CallHierarchyIncomingCall
+	switch data.Type {
+	case typeService:
+		search = serviceIncomingCalls
+	case typeStruct, typeUnion, typeException:
+		search = typeIncomingCalls
+	default:
+		return res, nil
+	}
+
+	var errs []error
+	for _, file := range referenceFiles(ss, item.URI) {
+		pf, err := ss.Parse(ctx, file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if pf.AST() == nil {
+			continue
+		}
+
+		// definitions in other files are referenced by include name
+		name := item.Name
+		if file != item.URI {
+			name = fmt.Sprintf("%s.%s", lsputils.GetIncludeName(item.URI), item.Name)
+		}
+		res = append(res, search(file, pf.AST(), name)...)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].From.URI != res[j].From.URI {
+			return res[i].From.URI < res[j].From.URI
+		}
+		return lessPosition(res[i].From.Range.Start, res[j].From.Range.Start)
+	})
+
+	if len(errs) > 0 {
+		err = utilerrors.NewAggregate(errs)
+	}
+
+	return res, err
+}
+
+// OutgoingCalls returns structs, unions and exceptions used by function
+func OutgoingCalls(ctx context.Context, ss *cache.Snapshot, item protocol.CallHierarchyItem) ([]protocol.CallHierarchyOutgoingCall, error) {
+	res := make([]protocol.CallHierarchyOutgoingCall, 0)
+	data, err := dataOf(item)
+	if err != nil || data.Type != typeFunction {
+		return res, err
+	}
+
+	node, _, err := lookup(ctx, ss, item.URI, data, item.Name)
+	if err != nil || node == nil {
+		return res, err
+	}
+	fn := node.(*parser.Function)
+
+	pf, err := ss.Parse(ctx, item.URI)
+	if err != nil {
+		return res, err
+	}
+
+	// calls to the same definition are merged
+	indexes := make(map[string]int)
+	var errs []error
+	var visit func(ft *parser.FieldType)
+	visit = func(ft *parser.FieldType) {
+		if ft == nil || ft.BadNode {
+			return
+		}
+		visit(ft.KeyType)
+		visit(ft.ValueType)
+		if ft.TypeName == nil || ft.TypeName.BadNode || codejump.IsBasicType(ft.TypeName.Name) {
+			return
+		}
+
+		dstFile, id, kind, err := codejump.TypeNameDefinitionIdentifier(ctx, ss, item.URI, pf.AST(), ft.TypeName)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		if id == nil {
+			return
+		}
+
+		rng := lsputils.ASTNodeToRange(ft.TypeName)
+		key := fmt.Sprintf("%s#%s", dstFile, id.Name.Text)
+		if i, ok := indexes[key]; ok {
+			res[i].FromRanges = append(res[i].FromRanges, rng)
+			return
+		}
+
+		to, err := definitionItems(ctx, ss, dstFile, itemData{Type: kind}, id.Name.Text)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		if len(to) == 0 {
+			// enum or typedef
+			return
+		}
+		indexes[key] = len(res)
+		res = append(res, protocol.CallHierarchyOutgoingCall{
+			To:         to[0],
+			FromRanges: []protocol.Range{rng},
+		})
+	}
+
+	if fn.Void == nil {
+		visit(fn.FunctionType)
+	}
+	for _, field := range fn.Arguments {
+		visit(fieldType(field))
+	}
+	if fn.Throws != nil {
+		for _, field := range fn.Throws.Fields {
+			visit(fieldType(field))
+		}
+	}
+
+	if len(errs) > 0 {
+		err = utilerrors.NewAggregate(errs)
+	}
+
+	return res, err
+}
+
+func serviceIncomingCalls(file uri.URI, ast *parser.Document, name string) []protocol.CallHierarchyIncomingCall {
+	res := make([]protocol.CallHierarchyIncomingCall, 0)
+	for _, svc := range ast.Services {
+		if svc == nil || svc.Extends == nil || svc.Extends.BadNode || svc.Extends.Name == nil {
+			continue
+		}
+		if svc.Extends.Name.Text != name {
+			continue
+		}
+		from := newItem(file, svc, nil)
+		if from == nil {
+			continue
+		}
+		res = append(res, protocol.CallHierarchyIncomingCall{
+			From:       *from,
+			FromRanges: []protocol.Range{lsputils.ASTNodeToRange(svc.Extends.Name)},
+		})
+	}
+
+	return res
+}
+
+func typeIncomingCalls(file uri.URI, ast *parser.Document, name string) []protocol.CallHierarchyIncomingCall {
+	res := make([]protocol.CallHierarchyIncomingCall, 0)
+	add := func(node parser.Node, svc *parser.Service, ranges []protocol.Range) {
+		if len(ranges) == 0 {
+			return
+		}
+		from := newItem(file, node, svc)
+		if from == nil {
+			return
+		}
+		res = append(res, protocol.CallHierarchyIncomingCall{
+			From:       *from,
+			FromRanges: ranges,
+		})
+	}
+
+	for _, svc := range ast.Services {
+		if svc == nil {
+			continue
+		}
+		for _, fn := range svc.Functions {
+			if fn == nil || fn.BadNode {
+				continue
+			}
+			var ranges []protocol.Range
+			if fn.Void == nil {
+				ranges = append(ranges, typeRanges(fn.FunctionType, name)...)
+			}
+			ranges = append(ranges, fieldsTypeRanges(fn.Arguments, name)...)
+			if fn.Throws != nil {
+				ranges = append(ranges, fieldsTypeRanges(fn.Throws.Fields, name)...)
+			}
+			add(fn, svc, ranges)
+		}
+	}
+
+	for _, st := range ast.Structs {
+		add(st, nil, fieldsTypeRanges(st.Fields, name))
+	}
+	for _, union := range ast.Unions {
+		add(union, nil, fieldsTypeRanges(union.Fields, name))
+	}
+	for _, excep := range ast.Exceptions {
+		add(excep, nil, fieldsTypeRanges(excep.Fields, name))
+	}
+
+	return res
+}
+
+func fieldsTypeRanges(fields []*parser.Field, name string) []protocol.Range {
+	var res []protocol.Range
+	for _, field := range fields {
+		res = append(res, typeRanges(fieldType(field), name)...)
+	}
+	return res
+}
+
+// typeRanges returns ranges of type name in field type, including key and value type of container
+func typeRanges(ft *parser.FieldType, name string) []protocol.Range {
+	if ft == nil || ft.BadNode {
+		return nil
+	}
+	var res []protocol.Range
+	res = append(res, typeRanges(ft.KeyType, name)...)
+	res = append(res, typeRanges(ft.ValueType, name)...)
+	if ft.TypeName != nil && !ft.TypeName.BadNode && ft.TypeName.Name == name {
+		res = append(res, lsputils.ASTNodeToRange(ft.TypeName))
+	}
+
+	return res
+}
+
+func fieldType(field *parser.Field) *parser.FieldType {
+	if field == nil || field.BadNode {
+		return nil
+	}
+	return field.FieldType
+}
+
+// referenceFiles returns file and files which include it
+func referenceFiles(ss *cache.Snapshot, file uri.URI) []uri.URI {
+	res := []uri.URI{file}
+	includeNode := ss.Graph().Get(file)
+	if includeNode == nil {
+		log.Debugln("include node not found:", file)
+		return res
+	}
+	for _, referenceFile := range includeNode.InDegree() {
+		if referenceFile != file {
+			res = append(res, referenceFile)
+		}
+	}
+
+	return res
+}
+
+func lessPosition(a, b protocol.Position) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Character < b.Character
+}
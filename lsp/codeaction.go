@@ -0,0 +1,37 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/joyme123/thrift-ls/lsp/diagnostic"
+	"go.lsp.dev/protocol"
+)
+
+func (s *Server) codeAction(ctx context.Context, params *protocol.CodeActionParams) ([]protocol.CodeAction, error) {
+	if !codeActionKindRequested(params.Context.Only, protocol.QuickFix) {
+		return nil, nil
+	}
+
+	file := params.TextDocument.URI
+	view, err := s.session.ViewOf(file)
+	if err != nil {
+		return nil, err
+	}
+	ss, release := view.Snapshot()
+	defer release()
+
+	return diagnostic.CodeActions(ctx, ss, file, params.Context.Diagnostics)
+}
+
+// codeActionKindRequested reports whether kind is requested. all kinds are requested if only is empty
+func codeActionKindRequested(only []protocol.CodeActionKind, kind protocol.CodeActionKind) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, item := range only {
+		if item == kind {
+			return true
+		}
+	}
+	return false
+}
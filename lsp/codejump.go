@@ -42,3 +42,15 @@ func (s *Server) typeDefinition(ctx context.Context, params *protocol.TypeDefini
 
 	return codejump.TypeDefinition(ctx, ss, params.TextDocument.URI, params.Position)
 }
+
+func (s *Server) documentHighlight(ctx context.Context, params *protocol.DocumentHighlightParams) (result []protocol.DocumentHighlight, err error) {
+	file := params.TextDocument.URI
+	view, err := s.session.ViewOf(file)
+	if err != nil {
+		return nil, err
+	}
+	ss, release := view.Snapshot()
+	defer release()
+
+	return codejump.DocumentHighlight(ctx, ss, params.TextDocument.URI, params.Position)
+}
@@ -72,7 +72,7 @@ func ServiceDefinitionIdentifier(ctx context.Context, ss *cache.Snapshot, file u
 		if path == "" { // doesn't match any include path
 			return "", nil, "", nil
 		}
-		astFile = lsputils.IncludeURI(file, path)
+		astFile = lsputils.IncludeURI(file, path, ss.IncludeDirs()...)
 	}
 
 	// now we can find destinate definition in `dstAst` by `identifier`
@@ -124,7 +124,7 @@ func TypeNameDefinitionIdentifier(ctx context.Context, ss *cache.Snapshot, file
 		if path == "" { // doesn't match any include path
 			return "", nil, "", nil
 		}
-		astFile = lsputils.IncludeURI(file, path)
+		astFile = lsputils.IncludeURI(file, path, ss.IncludeDirs()...)
 	}
 
 	// now we can find destinate definition in `dstAst` by `identifier`
@@ -196,7 +196,7 @@ func ConstValueTypeDefinitionIdentifier(ctx context.Context, ss *cache.Snapshot,
 			identifier = constValue.Value.(string)
 			astFile = file
 		} else {
-			astFile = lsputils.IncludeURI(file, path)
+			astFile = lsputils.IncludeURI(file, path, ss.IncludeDirs()...)
 		}
 	}
 
@@ -0,0 +1,271 @@
+package codejump
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/lsputils"
+	"github.com/joyme123/thrift-ls/lsp/types"
+	"github.com/joyme123/thrift-ls/parser"
+	"github.com/joyme123/thrift-ls/utils"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// DocumentHighlight returns all occurrences of symbol under cursor in current file.
+// declaration is highlighted as write, others are highlighted as read
+func DocumentHighlight(ctx context.Context, ss *cache.Snapshot, file uri.URI, pos protocol.Position) (res []protocol.DocumentHighlight, err error) {
+	res = make([]protocol.DocumentHighlight, 0)
+	pf, err := ss.Parse(ctx, file)
+	if err != nil {
+		return
+	}
+
+	if pf.AST() == nil {
+		err = errors.New("parse ast failed")
+		return
+	}
+
+	astPos, err := pf.Mapper().LSPPosToParserPosition(types.Position{Line: pos.Line, Character: pos.Character})
+	if err != nil {
+		return
+	}
+	nodePath := parser.SearchNodePathByPosition(pf.AST(), astPos)
+	targetNode := nodePath[len(nodePath)-1]
+
+	fh, err := ss.ReadFile(ctx, file)
+	if err != nil {
+		return
+	}
+	content, err := fh.Content()
+	if err != nil {
+		return
+	}
+
+	// cursor is on `common` of `common.User`
+	if include := includePrefixUnderCursor(content, pf.AST(), nodePath, astPos); include != "" {
+		return includeHighlight(content, pf.AST(), include), nil
+	}
+
+	declarations, err := declarationLocations(ctx, ss, file, pf.AST(), nodePath, targetNode)
+	if err != nil {
+		return
+	}
+
+	references, err := Reference(ctx, ss, file, pos)
+	if err != nil {
+		return
+	}
+
+	seen := make(map[protocol.Range]struct{})
+	for _, loc := range declarations {
+		if loc.URI != file {
+			continue
+		}
+		if _, ok := seen[loc.Range]; ok {
+			continue
+		}
+		seen[loc.Range] = struct{}{}
+		res = append(res, protocol.DocumentHighlight{
+			Range: loc.Range,
+			Kind:  protocol.DocumentHighlightKindWrite,
+		})
+	}
+
+	for _, loc := range references {
+		if loc.URI != file {
+			continue
+		}
+		if _, ok := seen[loc.Range]; ok {
+			continue
+		}
+		seen[loc.Range] = struct{}{}
+		res = append(res, protocol.DocumentHighlight{
+			Range: loc.Range,
+			Kind:  protocol.DocumentHighlightKindRead,
+		})
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return lessPosition(res[i].Range.Start, res[j].Range.Start)
+	})
+
+	return
+}
+
+func lessPosition(a, b protocol.Position) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Character < b.Character
+}
+
+// declarationLocations returns the definition identifier of target node
+func declarationLocations(ctx context.Context, ss *cache.Snapshot, file uri.URI, ast *parser.Document, nodePath []parser.Node, targetNode parser.Node) ([]protocol.Location, error) {
+	var defFile uri.URI
+	var id *parser.Identifier
+	var err error
+	switch targetNode.Type() {
+	case "TypeName":
+		defFile, id, _, err = TypeNameDefinitionIdentifier(ctx, ss, file, ast, targetNode)
+	case "ConstValue":
+		defFile, id, err = ConstValueTypeDefinitionIdentifier(ctx, ss, file, ast, targetNode)
+	case "IdentifierName":
+		if len(nodePath) <= 2 {
+			return nil, nil
+		}
+		// identifierName -> identifier -> definition
+		switch nodePath[len(nodePath)-3].Type() {
+		case "Struct", "Union", "Exception", "Enum", "Typedef", "Const", "EnumValue":
+			return []protocol.Location{jump(file, targetNode)}, nil
+		case "Service": // service name or service extends
+			defFile, id, _, err = ServiceDefinitionIdentifier(ctx, ss, file, ast, targetNode)
+		}
+	}
+	if err != nil || id == nil || id.Name == nil {
+		return nil, err
+	}
+
+	return []protocol.Location{jump(defFile, id.Name)}, nil
+}
+
+// includePrefixUnderCursor returns include name if cursor is on include prefix of a type name,
+// const value or service extends
+func includePrefixUnderCursor(content []byte, ast *parser.Document, nodePath []parser.Node, pos parser.Position) string {
+	targetNode := nodePath[len(nodePath)-1]
+	var name string
+	var start parser.Position
+	switch targetNode.Type() {
+	case "TypeName":
+		name = targetNode.(*parser.TypeName).Name
+		start = targetNode.Pos()
+	case "IdentifierName":
+		// only service extends can be prefixed by include name
+		if len(nodePath) <= 2 || nodePath[len(nodePath)-3].Type() != "Service" {
+			return ""
+		}
+		name = targetNode.(*parser.IdentifierName).Text
+		start = targetNode.Pos()
+	case "ConstValue":
+		loc, ok := lsputils.ConstValueIdentifierLocation(content, targetNode.(*parser.ConstValue))
+		if !ok {
+			return ""
+		}
+		name = targetNode.(*parser.ConstValue).Value.(string)
+		start = loc.StartPos
+	default:
+		return ""
+	}
+
+	include, _, found := strings.Cut(name, ".")
+	if !found || lsputils.GetIncludePath(ast, include) == "" {
+		return ""
+	}
+
+	if pos.Line != start.Line || pos.Col < start.Col || pos.Col >= start.Col+utf8.RuneCountInString(include) {
+		return ""
+	}
+
+	return include
+}
+
+// includeHighlight highlights include path as write, and all include prefixes as read
+func includeHighlight(content []byte, ast *parser.Document, include string) []protocol.DocumentHighlight {
+	res := make([]protocol.DocumentHighlight, 0)
+	for _, item := range ast.Includes {
+		if item.BadNode || item.Path == nil || item.Path.BadNode || item.Path.Value == nil {
+			continue
+		}
+		if lsputils.GetIncludePath(ast, include) != item.Path.Value.Text {
+			continue
+		}
+		res = append(res, protocol.DocumentHighlight{
+			Range: lsputils.ASTNodeToRange(item.Path.Value),
+			Kind:  protocol.DocumentHighlightKindWrite,
+		})
+	}
+
+	prefix := include + "."
+	addPrefix := func(name string, start parser.Position) {
+		if !strings.HasPrefix(name, prefix) {
+			return
+		}
+		end := start
+		end.Col += utf8.RuneCountInString(include)
+		end.Offset += len(include)
+		res = append(res, protocol.DocumentHighlight{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: uint32(start.Line - 1), Character: uint32(start.Col - 1)},
+				End:   protocol.Position{Line: uint32(end.Line - 1), Character: uint32(end.Col - 1)},
+			},
+			Kind: protocol.DocumentHighlightKindRead,
+		})
+	}
+
+	var walkConstValue func(cv *parser.ConstValue)
+	walkConstValue = func(cv *parser.ConstValue) {
+		if cv == nil || cv.BadNode {
+			return
+		}
+		switch cv.TypeName {
+		case "identifier":
+			if loc, ok := lsputils.ConstValueIdentifierLocation(content, cv); ok {
+				addPrefix(cv.Value.(string), loc.StartPos)
+			}
+		case "list", "map":
+			if values, ok := cv.Value.([]*parser.ConstValue); ok {
+				for i := range values {
+					walkConstValue(values[i])
+				}
+			}
+		case "pair":
+			if key, ok := cv.Key.(*parser.ConstValue); ok {
+				walkConstValue(key)
+			}
+			if value, ok := cv.Value.(*parser.ConstValue); ok {
+				walkConstValue(value)
+			}
+		}
+	}
+
+	var walk func(node parser.Node)
+	walk = func(node parser.Node) {
+		if utils.IsNil(node) {
+			return
+		}
+		switch n := node.(type) {
+		case *parser.TypeName:
+			if !n.BadNode {
+				addPrefix(n.Name, n.Pos())
+			}
+		case *parser.ConstValue:
+			walkConstValue(n)
+		case *parser.Service:
+			if n.Extends != nil && !n.Extends.BadNode && n.Extends.Name != nil {
+				addPrefix(n.Extends.Name.Text, n.Extends.Name.Pos())
+			}
+			for _, fn := range n.Functions {
+				walk(fn)
+			}
+			return
+		case *parser.EnumValue:
+			walkConstValue(n.ValueNode)
+			return
+		}
+
+		for _, child := range node.Children() {
+			walk(child)
+		}
+	}
+	walk(ast)
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return lessPosition(res[i].Range.Start, res[j].Range.Start)
+	})
+
+	return res
+}
@@ -0,0 +1,136 @@
+package codejump
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestDocumentHighlight(t *testing.T) {
+	file1 := `enum Status {
+  OK = 1,
+}
+const Status DEFAULT = Status.OK
+struct User {
+  1: Status st = Status.OK
+}`
+
+	file2 := `include "user.thrift"
+service Base {}
+service Demo extends Base {
+  user.User Get(1: user.Status st = user.Status.OK)
+}`
+
+	ss := cache.BuildSnapshotForTest([]*cache.FileChange{
+		{
+			URI:     "file:///tmp/user.thrift",
+			Version: 0,
+			Content: []byte(file1),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+		{
+			URI:     "file:///tmp/api.thrift",
+			Version: 0,
+			Content: []byte(file2),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	})
+
+	highlight := func(startLine, startChar, endLine, endChar uint32, kind protocol.DocumentHighlightKind) protocol.DocumentHighlight {
+		return protocol.DocumentHighlight{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: startLine, Character: startChar},
+				End:   protocol.Position{Line: endLine, Character: endChar},
+			},
+			Kind: kind,
+		}
+	}
+
+	type args struct {
+		file uri.URI
+		pos  protocol.Position
+	}
+	tests := []struct {
+		name      string
+		args      args
+		want      []protocol.DocumentHighlight
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name: "enum value declaration",
+			args: args{
+				file: "file:///tmp/user.thrift",
+				pos:  protocol.Position{Line: 1, Character: 2},
+			},
+			want: []protocol.DocumentHighlight{
+				highlight(1, 2, 1, 4, protocol.DocumentHighlightKindWrite),
+				highlight(3, 23, 3, 32, protocol.DocumentHighlightKindRead),
+				highlight(5, 17, 5, 26, protocol.DocumentHighlightKindRead),
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "enum value in const",
+			args: args{
+				file: "file:///tmp/user.thrift",
+				pos:  protocol.Position{Line: 3, Character: 24},
+			},
+			want: []protocol.DocumentHighlight{
+				highlight(1, 2, 1, 4, protocol.DocumentHighlightKindWrite),
+				highlight(3, 23, 3, 32, protocol.DocumentHighlightKindRead),
+				highlight(5, 17, 5, 26, protocol.DocumentHighlightKindRead),
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "type",
+			args: args{
+				file: "file:///tmp/user.thrift",
+				pos:  protocol.Position{Line: 5, Character: 5},
+			},
+			want: []protocol.DocumentHighlight{
+				highlight(0, 5, 0, 11, protocol.DocumentHighlightKindWrite),
+				highlight(3, 6, 3, 12, protocol.DocumentHighlightKindRead),
+				highlight(5, 5, 5, 11, protocol.DocumentHighlightKindRead),
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "include prefix",
+			args: args{
+				file: "file:///tmp/api.thrift",
+				pos:  protocol.Position{Line: 3, Character: 3},
+			},
+			want: []protocol.DocumentHighlight{
+				highlight(0, 9, 0, 20, protocol.DocumentHighlightKindWrite),
+				highlight(3, 2, 3, 6, protocol.DocumentHighlightKindRead),
+				highlight(3, 19, 3, 23, protocol.DocumentHighlightKindRead),
+				highlight(3, 36, 3, 40, protocol.DocumentHighlightKindRead),
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "service extends",
+			args: args{
+				file: "file:///tmp/api.thrift",
+				pos:  protocol.Position{Line: 2, Character: 22},
+			},
+			want: []protocol.DocumentHighlight{
+				highlight(1, 8, 1, 12, protocol.DocumentHighlightKindWrite),
+				highlight(2, 21, 2, 25, protocol.DocumentHighlightKindRead),
+			},
+			assertion: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DocumentHighlight(context.TODO(), ss, tt.args.file, tt.args.pos)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -61,7 +61,7 @@ func hoverService(ctx context.Context, ss *cache.Snapshot, file uri.URI, ast *pa
 		if path == "" { // doesn't match any include path
 			return "", nil
 		}
-		astFile = lsputils.IncludeURI(file, path)
+		astFile = lsputils.IncludeURI(file, path, ss.IncludeDirs()...)
 	}
 
 	// now we can find destinate definition in `dstAst` by `identifier`
@@ -76,7 +76,7 @@ func hoverService(ctx context.Context, ss *cache.Snapshot, file uri.URI, ast *pa
 
 	dstService := GetServiceNode(dstAst.AST(), identifier)
 	if dstService != nil {
-		return format.MustFormatService(dstService), nil
+		return formatDefinition(ctx, ss, astFile, dstService)
 	}
 
 	return "", nil
@@ -100,7 +100,7 @@ func hoverDefinition(ctx context.Context, ss *cache.Snapshot, file uri.URI, ast
 		if path == "" { // doesn't match any include path
 			return "", nil
 		}
-		astFile = lsputils.IncludeURI(file, path)
+		astFile = lsputils.IncludeURI(file, path, ss.IncludeDirs()...)
 	}
 
 	// now we can find destinate definition in `dstAst` by `identifier`
@@ -116,23 +116,23 @@ func hoverDefinition(ctx context.Context, ss *cache.Snapshot, file uri.URI, ast
 	// struct, exception, enum or union
 	dstException := GetExceptionNode(dstAst.AST(), identifier)
 	if dstException != nil {
-		return format.MustFormatException(dstException), nil
+		return formatDefinition(ctx, ss, astFile, dstException)
 	}
 	dstStruct := GetStructNode(dstAst.AST(), identifier)
 	if dstStruct != nil {
-		return format.MustFormatStruct(dstStruct), nil
+		return formatDefinition(ctx, ss, astFile, dstStruct)
 	}
 	dstEnum := GetEnumNode(dstAst.AST(), identifier)
 	if dstEnum != nil {
-		return format.MustFormatEnum(dstEnum), nil
+		return formatDefinition(ctx, ss, astFile, dstEnum)
 	}
 	dstUnion := GetUnionNode(dstAst.AST(), identifier)
 	if dstUnion != nil {
-		return format.MustFormatUnion(dstUnion), nil
+		return formatDefinition(ctx, ss, astFile, dstUnion)
 	}
 	dstTypedef := GetTypedefNode(dstAst.AST(), identifier)
 	if dstTypedef != nil {
-		return format.MustFormatTypedef(dstTypedef), nil
+		return formatDefinition(ctx, ss, astFile, dstTypedef)
 	}
 
 	return "", nil
@@ -157,7 +157,7 @@ func hoverConstValue(ctx context.Context, ss *cache.Snapshot, file uri.URI, ast
 			identifier = constValue.Value.(string)
 			astFile = file
 		} else {
-			astFile = lsputils.IncludeURI(file, path)
+			astFile = lsputils.IncludeURI(file, path, ss.IncludeDirs()...)
 		}
 	}
 
@@ -169,13 +169,28 @@ func hoverConstValue(ctx context.Context, ss *cache.Snapshot, file uri.URI, ast
 
 	dstEnum := GetEnumNodeByEnumValue(dstAst.AST(), identifier)
 	if dstEnum != nil {
-		return format.MustFormatEnum(dstEnum), nil
+		return formatDefinition(ctx, ss, astFile, dstEnum)
 	}
 
 	dstConst := GetConstNode(dstAst.AST(), identifier)
 	if dstConst != nil {
-		return format.MustFormatConst(dstConst), nil
+		return formatDefinition(ctx, ss, astFile, dstConst)
 	}
 
 	return "", nil
 }
+
+// formatDefinition formats definition in file. fields, enum values and functions with syntax error are shown
+// as they are
+func formatDefinition(ctx context.Context, ss *cache.Snapshot, file uri.URI, node parser.Node) (string, error) {
+	fh, err := ss.ReadFile(ctx, file)
+	if err != nil {
+		return "", err
+	}
+	content, err := fh.Content()
+	if err != nil {
+		return "", err
+	}
+
+	return format.FormatNodeWithOptions(node, content, format.DefaultOptions()), nil
+}
@@ -0,0 +1,35 @@
+package codejump
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+)
+
+func TestHover(t *testing.T) {
+	content := `struct User {
+  1: string name
+  2: i64
+}
+
+struct Req {
+  1: User user
+}
+`
+	ss := cache.BuildSnapshotForTest([]*cache.FileChange{
+		{
+			URI:     "file:///tmp/user.thrift",
+			Version: 0,
+			Content: []byte(content),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	})
+
+	// fields with syntax error are shown as they are
+	got, err := Hover(context.TODO(), ss, "file:///tmp/user.thrift", protocol.Position{Line: 6, Character: 6})
+	assert.NoError(t, err)
+	assert.Equal(t, "struct User {\n    1: string name\n    2: i64\n}\n", got)
+}
@@ -71,7 +71,7 @@ func Reference(ctx context.Context, ss *cache.Snapshot, file uri.URI, pos protoc
 				include, _, _ := strings.Cut(svcName, ".")
 				path := lsputils.GetIncludePath(pf.AST(), include)
 				if path != "" { // doesn't match any include path
-					file = lsputils.IncludeURI(file, path)
+					file = lsputils.IncludeURI(file, path, ss.IncludeDirs()...)
 				}
 			}
 			return searchServiceReferences(ctx, ss, file, svcName)
@@ -98,7 +98,7 @@ func Rename(ctx context.Context, ss *cache.Snapshot, file uri.URI, pos protocol.
 				include, _, _ := strings.Cut(svcName, ".")
 				path := lsputils.GetIncludePath(pf.AST(), include)
 				if path != "" { // doesn't match any include path
-					file = lsputils.IncludeURI(file, path)
+					file = lsputils.IncludeURI(file, path, ss.IncludeDirs()...)
 				}
 			}
 			locations, err := searchServiceReferences(ctx, ss, file, svcName)
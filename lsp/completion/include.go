@@ -0,0 +1,78 @@
+package completion
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/joyme123/thrift-ls/parser"
+	"go.lsp.dev/protocol"
+)
+
+// includeEdit returns edit which adds include of path into header. Include is added after the last include.
+// If there is no include, it is added before the first definition
+func includeEdit(ast *parser.Document, content []byte, path string) protocol.TextEdit {
+	text := fmt.Sprintf("include %q\n", path)
+
+	lastLine := 0
+	for _, include := range ast.Includes {
+		if include.BadNode || include.Path == nil || include.Path.BadNode || include.Path.Value == nil {
+			continue
+		}
+		if line := include.Path.Value.End().Line; line > lastLine {
+			lastLine = line
+		}
+	}
+
+	if lastLine > 0 {
+		lines := bytes.Split(content, []byte("\n"))
+		if lastLine < len(lines) {
+			return protocol.TextEdit{
+				Range:   emptyRange(uint32(lastLine), 0),
+				NewText: text,
+			}
+		}
+		// last include is in the last line of file
+		return protocol.TextEdit{
+			Range:   emptyRange(uint32(lastLine-1), uint32(utf8.RuneCount(lines[lastLine-1]))),
+			NewText: "\n" + text,
+		}
+	}
+
+	for _, node := range ast.Nodes {
+		if node.IsBadNode() {
+			continue
+		}
+		line := node.Pos().Line
+		if ns, ok := node.(*parser.Namespace); ok {
+			// comments of first namespace are usually file header, include is added after them
+			if ns.NamespaceKeyword != nil && ns.NamespaceKeyword.Literal != nil {
+				line = ns.NamespaceKeyword.Literal.Pos().Line
+			}
+		} else {
+			text = text + "\n"
+		}
+
+		return protocol.TextEdit{
+			Range:   emptyRange(uint32(line-1), 0),
+			NewText: text,
+		}
+	}
+
+	return protocol.TextEdit{
+		Range:   emptyRange(0, 0),
+		NewText: text,
+	}
+}
+
+func emptyRange(line, character uint32) protocol.Range {
+	pos := protocol.Position{
+		Line:      line,
+		Character: character,
+	}
+
+	return protocol.Range{
+		Start: pos,
+		End:   pos,
+	}
+}
@@ -0,0 +1,234 @@
+package completion
+
+import (
+	"strings"
+)
+
+// completionPosition is kind of position where completion is triggered
+type completionPosition int
+
+const (
+	positionUnknown completionPosition = iota
+	// field type of struct, union, exception, function arguments, typedef and const
+	positionFieldType
+	// function return type
+	positionFunctionType
+	// default value of field or value of const
+	positionDefaultValue
+	// field type in throws
+	positionThrows
+	// service after extends
+	positionExtends
+	// qualified name after `include.`
+	positionQualified
+)
+
+func (p completionPosition) String() string {
+	switch p {
+	case positionFieldType:
+		return "FieldType"
+	case positionFunctionType:
+		return "FunctionType"
+	case positionDefaultValue:
+		return "DefaultValue"
+	case positionThrows:
+		return "Throws"
+	case positionExtends:
+		return "Extends"
+	case positionQualified:
+		return "Qualified"
+	}
+	return "Unknown"
+}
+
+var definitionKeywords = map[string]struct{}{
+	"include":     {},
+	"cpp_include": {},
+	"namespace":   {},
+	"const":       {},
+	"typedef":     {},
+	"enum":        {},
+	"senum":       {},
+	"struct":      {},
+	"union":       {},
+	"exception":   {},
+	"service":     {},
+}
+
+// bracket is an unclosed '{', '(', '[' or '<' before cursor
+type bracket struct {
+	char byte
+	// prev is the token before bracket. such as `throws`, or `)` for annotations of function
+	prev string
+	// value is true if bracket is in const value, such as `= [`
+	value bool
+}
+
+// detectPosition detects completion position by content before the word under cursor. Content is scanned
+// as tokens, because the definition under cursor is usually incomplete and can't be parsed correctly.
+func detectPosition(content []byte) completionPosition {
+	var (
+		definition string // keyword of definition under cursor
+		brackets   []bracket
+		tokens     []string // last tokens
+		afterEqual bool
+	)
+
+	push := func(token string) {
+		tokens = append(tokens, token)
+		if len(tokens) > 2 {
+			tokens = tokens[1:]
+		}
+	}
+
+	for i := 0; i < len(content); {
+		ch := content[i]
+		switch {
+		case ch == '/' && i+1 < len(content) && content[i+1] == '/', ch == '#':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			continue
+		case ch == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := strings.Index(string(content[i+2:]), "*/")
+			if end == -1 {
+				// cursor is in comment
+				return positionUnknown
+			}
+			i = i + 2 + end + 2
+			continue
+		case ch == '"' || ch == '\'':
+			j := i + 1
+			for j < len(content) && content[j] != ch {
+				j++
+			}
+			if j >= len(content) {
+				// cursor is in string
+				return positionUnknown
+			}
+			push("literal")
+			afterEqual = false
+			i = j + 1
+			continue
+		case isIdentifierChar(ch):
+			j := i
+			for j < len(content) && isIdentifierChar(content[j]) {
+				j++
+			}
+			word := string(content[i:j])
+			if _, ok := definitionKeywords[word]; ok && len(brackets) == 0 {
+				definition = word
+			}
+			if word[0] >= '0' && word[0] <= '9' {
+				push("number")
+			} else {
+				push(word)
+			}
+			afterEqual = false
+			i = j
+			continue
+		case ch == '{' || ch == '(' || ch == '[' || ch == '<':
+			prev := ""
+			if len(tokens) > 0 {
+				prev = tokens[len(tokens)-1]
+			}
+			brackets = append(brackets, bracket{char: ch, prev: prev, value: afterEqual || inValue(brackets)})
+		case ch == '}' || ch == ')' || ch == ']' || ch == '>':
+			if len(brackets) > 0 {
+				brackets = brackets[:len(brackets)-1]
+			}
+		}
+
+		if !isSpace(ch) {
+			push(string(ch))
+			afterEqual = ch == '='
+		}
+		i++
+	}
+
+	if len(tokens) == 0 {
+		return positionUnknown
+	}
+
+	last := tokens[len(tokens)-1]
+	prev := ""
+	if len(tokens) > 1 {
+		prev = tokens[len(tokens)-2]
+	}
+
+	var top bracket
+	if len(brackets) > 0 {
+		top = brackets[len(brackets)-1]
+	}
+
+	switch {
+	case last == "extends":
+		return positionExtends
+	case last == "=":
+		if definition == "const" && len(brackets) == 0 {
+			return positionDefaultValue
+		}
+		if top.char == '{' && len(brackets) == 1 && isStructLike(definition) {
+			return positionDefaultValue
+		}
+		if isFunctionFields(definition, brackets) {
+			return positionDefaultValue
+		}
+		return positionUnknown
+	case top.value:
+		if last == "[" || last == "{" || last == "," || last == ":" {
+			return positionDefaultValue
+		}
+		return positionUnknown
+	case last == "const" || last == "typedef":
+		if len(brackets) == 0 {
+			return positionFieldType
+		}
+	case top.char == '<':
+		if last == "<" || last == "," {
+			return positionFieldType
+		}
+	case (last == ":" && prev == "number") || last == "required" || last == "optional":
+		if isFunctionFields(definition, brackets) && top.prev == "throws" {
+			return positionThrows
+		}
+		if top.char == '{' && len(brackets) == 1 && isStructLike(definition) {
+			return positionFieldType
+		}
+		if isFunctionFields(definition, brackets) {
+			return positionFieldType
+		}
+	case definition == "service" && len(brackets) == 1 && top.char == '{':
+		switch last {
+		case "{", ",", ";", ")", "oneway":
+			return positionFunctionType
+		}
+	}
+
+	return positionUnknown
+}
+
+// isFunctionFields reports whether cursor is in function arguments or throws
+func isFunctionFields(definition string, brackets []bracket) bool {
+	if definition != "service" || len(brackets) != 2 || brackets[1].char != '(' {
+		return false
+	}
+	// annotations of function
+	return brackets[1].prev != ")"
+}
+
+func inValue(brackets []bracket) bool {
+	return len(brackets) > 0 && brackets[len(brackets)-1].value
+}
+
+func isStructLike(definition string) bool {
+	return definition == "struct" || definition == "union" || definition == "exception"
+}
+
+func isIdentifierChar(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '_' || ch == '.'
+}
+
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\v' || ch == '\f'
+}
@@ -2,17 +2,32 @@ package completion
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/joyme123/thrift-ls/format"
 	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/lsputils"
+	"github.com/joyme123/thrift-ls/parser"
+	log "github.com/sirupsen/logrus"
 	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
 )
 
 type Interface interface {
 	Completion(ctx context.Context, ss *cache.Snapshot, cmp *CompletionRequest) ([]*CompletionItem, protocol.Range, error)
 }
 
-// SemanticBasedCompletion generates completion list based on semantic. It is more precisely than token based completion
-// TODO(jpf)
+var DefaultSemanticBasedCompletion Interface = &SemanticBasedCompletion{}
+
+// UnknownPositionError is returned by SemanticBasedCompletion if position of cursor isn't recognized
+var UnknownPositionError error = errors.New("unknown completion position")
+
+// SemanticBasedCompletion generates completion list based on semantic. It is more precisely than token based completion.
+// Candidates are decided by the position of cursor, for example only exceptions are listed in throws.
+// UnknownPositionError is returned if position is unknown, and token based completion should be used. Empty list
+// is returned if position is known but no candidate matches.
 type SemanticBasedCompletion struct {
 }
 
@@ -28,3 +43,290 @@ func BuildCompletionItem(candidate Candidate) *CompletionItem {
 		Documentation:    "",
 	}
 }
+
+func (c *SemanticBasedCompletion) Completion(ctx context.Context, ss *cache.Snapshot, cmp *CompletionRequest) ([]*CompletionItem, protocol.Range, error) {
+	rng := protocol.Range{
+		Start: protocol.Position{
+			Line:      cmp.Pos.Line,
+			Character: cmp.Pos.Character,
+		},
+		End: protocol.Position{
+			Line:      cmp.Pos.Line,
+			Character: cmp.Pos.Character,
+		},
+	}
+
+	file := cmp.Fh.URI()
+	pf, err := ss.Parse(ctx, file)
+	if err != nil {
+		return nil, rng, err
+	}
+	if pf.AST() == nil {
+		return nil, rng, fmt.Errorf("parser ast failed")
+	}
+
+	pos, err := pf.Mapper().LSPPosToParserPosition(cmp.Pos)
+	if err != nil {
+		return nil, rng, err
+	}
+
+	content, err := cmp.Fh.Content()
+	if err != nil {
+		return nil, rng, err
+	}
+	if pos.Offset > len(content) {
+		return nil, rng, fmt.Errorf("invalid position")
+	}
+
+	start := pos.Offset
+	for start > 0 && isIdentifierChar(content[start-1]) {
+		start--
+	}
+	prefix := string(content[start:pos.Offset])
+	rng.Start.Character = rng.Start.Character - uint32(len(prefix))
+
+	position := detectPosition(content[:start])
+	log.Debugln("semantic completion position:", position, "prefix:", prefix)
+	if position == positionUnknown {
+		if !strings.Contains(prefix, ".") {
+			return nil, rng, UnknownPositionError
+		}
+		// qualified name, such as `user.`
+		position = positionQualified
+	}
+
+	candidates, err := c.candidates(ctx, ss, file, pf.AST(), content, position)
+	if err != nil {
+		return nil, rng, err
+	}
+
+	res := make([]*CompletionItem, 0)
+	for _, item := range candidates {
+		if !matchPrefix(item.Label, prefix) {
+			continue
+		}
+		if position == positionQualified && !strings.Contains(item.Label, ".") {
+			continue
+		}
+		res = append(res, item)
+	}
+
+	return res, rng, nil
+}
+
+// matchPrefix reports whether label or label without qualifier starts with prefix
+func matchPrefix(label string, prefix string) bool {
+	label, prefix = strings.ToLower(label), strings.ToLower(prefix)
+	if strings.HasPrefix(label, prefix) {
+		return true
+	}
+	_, name, found := strings.Cut(label, ".")
+	return found && strings.HasPrefix(name, prefix)
+}
+
+// candidates returns definitions which are allowed in position. Definitions of included files are
+// qualified by include name. Definitions of other files in workspace are qualified too, and an include
+// of the file is added when it is selected
+func (c *SemanticBasedCompletion) candidates(ctx context.Context, ss *cache.Snapshot, file uri.URI, ast *parser.Document, content []byte, position completionPosition) ([]*CompletionItem, error) {
+	res := make([]*CompletionItem, 0)
+	res = append(res, definitionItems(ast, "", position)...)
+
+	included := map[uri.URI]struct{}{
+		file: {},
+	}
+	includeNames := make(map[string]struct{})
+	for _, include := range ast.Includes {
+		if include.BadNode || include.Path == nil || include.Path.BadNode || include.Path.Value == nil {
+			continue
+		}
+		includeURI := lsputils.IncludeURI(file, include.Path.Value.Text, ss.IncludeDirs()...)
+		included[includeURI] = struct{}{}
+		includeNames[lsputils.GetIncludeName(includeURI)] = struct{}{}
+		pf, err := ss.Parse(ctx, includeURI)
+		if err != nil || pf.AST() == nil {
+			log.Debugln("parse include file failed:", includeURI, err)
+			continue
+		}
+		res = append(res, definitionItems(pf.AST(), lsputils.GetIncludeName(includeURI), position)...)
+	}
+
+	for _, workspaceFile := range ss.ParsedFiles() {
+		if _, ok := included[workspaceFile]; ok {
+			continue
+		}
+		includeName := lsputils.GetIncludeName(workspaceFile)
+		if _, ok := includeNames[includeName]; ok {
+			// include name is used by other file
+			continue
+		}
+		includePath, ok := lsputils.IncludePath(file, workspaceFile, ss.IncludeDirs()...)
+		if !ok {
+			continue
+		}
+		pf, err := ss.Parse(ctx, workspaceFile)
+		if err != nil || pf.AST() == nil {
+			continue
+		}
+		edit := includeEdit(ast, content, includePath)
+		for _, item := range definitionItems(pf.AST(), includeName, position) {
+			item.Detail = fmt.Sprintf("%s (include %q)", item.Detail, includePath)
+			item.AdditionalTextEdits = []protocol.TextEdit{edit}
+			res = append(res, item)
+		}
+	}
+
+	switch position {
+	case positionFieldType, positionFunctionType:
+		res = append(res, basicTypeItems(position == positionFunctionType)...)
+	}
+
+	return res, nil
+}
+
+// definitionItems returns completion items of definitions in ast. names are prefixed by include name
+// if include is not empty
+func definitionItems(ast *parser.Document, include string, position completionPosition) []*CompletionItem {
+	qualify := func(name string) string {
+		if include == "" {
+			return name
+		}
+		return include + "." + name
+	}
+	valid := func(id *parser.Identifier) bool {
+		return id != nil && !id.BadNode && id.Name != nil && id.Name.Text != ""
+	}
+
+	types := position == positionFieldType || position == positionFunctionType || position == positionQualified
+	values := position == positionDefaultValue || position == positionQualified
+	exceptions := types || position == positionThrows
+	services := position == positionExtends || position == positionQualified
+
+	res := make([]*CompletionItem, 0)
+	for _, node := range ast.Nodes {
+		if node.IsBadNode() {
+			continue
+		}
+		switch node.Type() {
+		case "Struct":
+			st := node.(*parser.Struct)
+			if !types || !valid(st.Identifier) {
+				continue
+			}
+			res = append(res, definitionItem(qualify(st.Identifier.Name.Text), protocol.CompletionItemKindStruct,
+				"struct "+st.Identifier.Name.Text, st.Comments))
+		case "Union":
+			union := node.(*parser.Union)
+			if !types || !valid(union.Name) {
+				continue
+			}
+			res = append(res, definitionItem(qualify(union.Name.Name.Text), protocol.CompletionItemKindStruct,
+				"union "+union.Name.Name.Text, union.Comments))
+		case "Exception":
+			excep := node.(*parser.Exception)
+			if !exceptions || !valid(excep.Name) {
+				continue
+			}
+			res = append(res, definitionItem(qualify(excep.Name.Name.Text), protocol.CompletionItemKindClass,
+				"exception "+excep.Name.Name.Text, excep.Comments))
+		case "Enum":
+			enum := node.(*parser.Enum)
+			if !valid(enum.Name) {
+				continue
+			}
+			if types {
+				res = append(res, definitionItem(qualify(enum.Name.Name.Text), protocol.CompletionItemKindEnum,
+					"enum "+enum.Name.Name.Text, enum.Comments))
+			}
+			if !values {
+				continue
+			}
+			for _, value := range enum.Values {
+				if value.BadNode || !valid(value.Name) {
+					continue
+				}
+				name := enum.Name.Name.Text + "." + value.Name.Name.Text
+				res = append(res, definitionItem(qualify(name), protocol.CompletionItemKindEnumMember,
+					fmt.Sprintf("%s = %d", name, value.Value), value.Comments))
+			}
+		case "Typedef":
+			td := node.(*parser.Typedef)
+			if !types || !valid(td.Alias) || td.T == nil {
+				continue
+			}
+			res = append(res, definitionItem(qualify(td.Alias.Name.Text), protocol.CompletionItemKindTypeParameter,
+				fmt.Sprintf("typedef %s %s", format.MustFormatFieldType(td.T), td.Alias.Name.Text), td.Comments))
+		case "Const":
+			cst := node.(*parser.Const)
+			if !values || !valid(cst.Name) || cst.ConstType == nil {
+				continue
+			}
+			res = append(res, definitionItem(qualify(cst.Name.Name.Text), protocol.CompletionItemKindConstant,
+				fmt.Sprintf("const %s %s", format.MustFormatFieldType(cst.ConstType), cst.Name.Name.Text), cst.Comments))
+		case "Service":
+			svc := node.(*parser.Service)
+			if !services || !valid(svc.Name) {
+				continue
+			}
+			res = append(res, definitionItem(qualify(svc.Name.Name.Text), protocol.CompletionItemKindInterface,
+				"service "+svc.Name.Name.Text, svc.Comments))
+		}
+	}
+
+	return res
+}
+
+func definitionItem(label string, kind protocol.CompletionItemKind, detail string, comments []*parser.Comment) *CompletionItem {
+	return &CompletionItem{
+		Label:            label,
+		Detail:           detail,
+		InsertText:       label,
+		InsertTextFormat: protocol.InsertTextFormatPlainText,
+		Kind:             kind,
+		Score:            100,
+		Documentation:    lsputils.CommentsDocumentation(comments),
+	}
+}
+
+var basicTypes = []string{"bool", "byte", "i8", "i16", "i32", "i64", "double", "string", "binary"}
+
+var containerTypes = map[string]string{
+	"list": "list<$1>",
+	"set":  "set<$1>",
+	"map":  "map<$1, $2>",
+}
+
+func basicTypeItems(withVoid bool) []*CompletionItem {
+	res := make([]*CompletionItem, 0)
+	if withVoid {
+		res = append(res, &CompletionItem{
+			Label:            "void",
+			Detail:           "void",
+			InsertText:       "void",
+			InsertTextFormat: protocol.InsertTextFormatPlainText,
+			Kind:             protocol.CompletionItemKindKeyword,
+			Score:            80,
+		})
+	}
+	for _, t := range basicTypes {
+		res = append(res, &CompletionItem{
+			Label:            t,
+			Detail:           "base type " + t,
+			InsertText:       t,
+			InsertTextFormat: protocol.InsertTextFormatPlainText,
+			Kind:             protocol.CompletionItemKindKeyword,
+			Score:            80,
+		})
+	}
+	for _, t := range []string{"list", "set", "map"} {
+		res = append(res, &CompletionItem{
+			Label:            t,
+			Detail:           "container type " + containerTypes[t],
+			InsertText:       containerTypes[t],
+			InsertTextFormat: protocol.InsertTextFormatSnippet,
+			Kind:             protocol.CompletionItemKindKeyword,
+			Score:            80,
+		})
+	}
+
+	return res
+}
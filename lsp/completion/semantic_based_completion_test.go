@@ -0,0 +1,280 @@
+package completion
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/types"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestSemanticBasedCompletion(t *testing.T) {
+	userFile := `// user status
+enum Status {
+  OK = 1,
+  Deleted = 2,
+}
+
+const i32 MaxAge = 100
+
+/**
+ * user info
+ */
+struct User {
+  1: string name
+}
+
+exception UserNotFound {}
+
+service UserService {}
+`
+
+	apiFile := `include "user.thrift"
+
+exception ApiError {}
+
+struct Request {
+  1: required 
+  2: user.Status status = 
+  3: i32 age = user.
+  4: list<Req> reqs
+}
+
+service Api extends  {
+  user.User Get(1: Request req) throws (1: )
+
+}`
+
+	ss := cache.BuildSnapshotForTest([]*cache.FileChange{
+		{
+			URI:     "file:///tmp/user.thrift",
+			Version: 0,
+			Content: []byte(userFile),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+		{
+			URI:     "file:///tmp/api.thrift",
+			Version: 0,
+			Content: []byte(apiFile),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	})
+
+	labels := func(items []*CompletionItem) []string {
+		res := make([]string, 0, len(items))
+		for _, item := range items {
+			res = append(res, item.Label)
+		}
+		return res
+	}
+
+	tests := []struct {
+		name    string
+		file    uri.URI
+		pos     types.Position
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "field type",
+			file: "file:///tmp/api.thrift",
+			pos:  types.Position{Line: 5, Character: 14},
+			want: []string{"ApiError", "Request", "user.Status", "user.User", "user.UserNotFound",
+				"bool", "byte", "i8", "i16", "i32", "i64", "double", "string", "binary", "list", "set", "map"},
+		},
+		{
+			name: "default value",
+			file: "file:///tmp/api.thrift",
+			pos:  types.Position{Line: 6, Character: 26},
+			want: []string{"user.Status.OK", "user.Status.Deleted", "user.MaxAge"},
+		},
+		{
+			name: "qualified default value",
+			file: "file:///tmp/api.thrift",
+			pos:  types.Position{Line: 7, Character: 20},
+			want: []string{"user.Status.OK", "user.Status.Deleted", "user.MaxAge"},
+		},
+		{
+			name: "container type",
+			file: "file:///tmp/api.thrift",
+			pos:  types.Position{Line: 8, Character: 11},
+			want: []string{"Request"},
+		},
+		{
+			name: "extends",
+			file: "file:///tmp/api.thrift",
+			pos:  types.Position{Line: 11, Character: 20},
+			want: []string{"user.UserService"},
+		},
+		{
+			name: "throws",
+			file: "file:///tmp/api.thrift",
+			pos:  types.Position{Line: 12, Character: 43},
+			want: []string{"ApiError", "user.UserNotFound"},
+		},
+		{
+			name:    "unknown position",
+			file:    "file:///tmp/api.thrift",
+			pos:     types.Position{Line: 4, Character: 8},
+			wantErr: UnknownPositionError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fh, err := ss.ReadFile(context.TODO(), tt.file)
+			assert.NoError(t, err)
+
+			got, _, err := DefaultSemanticBasedCompletion.Completion(context.TODO(), ss, &CompletionRequest{
+				Pos: tt.pos,
+				Fh:  fh,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, labels(got))
+		})
+	}
+}
+
+func TestSemanticBasedCompletionItem(t *testing.T) {
+	userFile := `/**
+ * user info
+ */
+struct User {
+  1: string name
+}
+
+struct Group {
+  1: U
+}`
+
+	ss := cache.BuildSnapshotForTest([]*cache.FileChange{
+		{
+			URI:     "file:///tmp/user.thrift",
+			Version: 0,
+			Content: []byte(userFile),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	})
+
+	fh, err := ss.ReadFile(context.TODO(), "file:///tmp/user.thrift")
+	assert.NoError(t, err)
+
+	got, rng, err := DefaultSemanticBasedCompletion.Completion(context.TODO(), ss, &CompletionRequest{
+		Pos: types.Position{Line: 8, Character: 6},
+		Fh:  fh,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []*CompletionItem{
+		{
+			Label:            "User",
+			Detail:           "struct User",
+			InsertText:       "User",
+			InsertTextFormat: protocol.InsertTextFormatPlainText,
+			Kind:             protocol.CompletionItemKindStruct,
+			Score:            100,
+			Documentation:    "user info",
+		},
+	}, got)
+	assert.Equal(t, protocol.Range{
+		Start: protocol.Position{Line: 8, Character: 5},
+		End:   protocol.Position{Line: 8, Character: 6},
+	}, rng)
+}
+
+func TestSemanticBasedCompletionAutoInclude(t *testing.T) {
+	ss := cache.BuildSnapshotForTest([]*cache.FileChange{
+		{
+			URI:     "file:///tmp/api.thrift",
+			Version: 0,
+			Content: []byte("include \"base.thrift\"\n\nstruct Request {\n  1: Gr\n}"),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+		{
+			URI:     "file:///tmp/admin.thrift",
+			Version: 0,
+			Content: []byte("// admin api\nnamespace go admin\n\nstruct Request {\n  1: Gr\n}"),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+		{
+			URI:     "file:///tmp/base.thrift",
+			Version: 0,
+			Content: []byte("struct Base {}"),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+		{
+			URI:     "file:///tmp/common/user.thrift",
+			Version: 0,
+			Content: []byte("struct User {}\nstruct Group {}"),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	})
+
+	tests := []struct {
+		name string
+		file uri.URI
+		pos  types.Position
+		want []*CompletionItem
+	}{
+		{
+			name: "add after last include",
+			file: "file:///tmp/api.thrift",
+			pos:  types.Position{Line: 3, Character: 7},
+			want: []*CompletionItem{
+				{
+					Label:            "user.Group",
+					Detail:           `struct Group (include "common/user.thrift")`,
+					InsertText:       "user.Group",
+					InsertTextFormat: protocol.InsertTextFormatPlainText,
+					Kind:             protocol.CompletionItemKindStruct,
+					Score:            100,
+					AdditionalTextEdits: []protocol.TextEdit{
+						{
+							Range:   emptyRange(1, 0),
+							NewText: "include \"common/user.thrift\"\n",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "add before namespace",
+			file: "file:///tmp/admin.thrift",
+			pos:  types.Position{Line: 4, Character: 7},
+			want: []*CompletionItem{
+				{
+					Label:            "user.Group",
+					Detail:           `struct Group (include "common/user.thrift")`,
+					InsertText:       "user.Group",
+					InsertTextFormat: protocol.InsertTextFormatPlainText,
+					Kind:             protocol.CompletionItemKindStruct,
+					Score:            100,
+					AdditionalTextEdits: []protocol.TextEdit{
+						{
+							Range:   emptyRange(1, 0),
+							NewText: "include \"common/user.thrift\"\n",
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fh, err := ss.ReadFile(context.TODO(), tt.file)
+			assert.NoError(t, err)
+
+			got, _, err := DefaultSemanticBasedCompletion.Completion(context.TODO(), ss, &CompletionRequest{
+				Pos: tt.pos,
+				Fh:  fh,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -163,6 +163,29 @@ func (c *TokenCompletion) includeCompletion(ss *cache.Snapshot, file uri.URI, no
 	log.Debugf("search prefix %s in path %s", pathPrefix, currentDir)
 
 	res, err = ListDirAndFiles(currentDir, pathPrefix)
+	if err != nil || strings.HasPrefix(pathPrefix, ".") {
+		log.Debugln("include completion: ", res, "err", err)
+		return
+	}
+
+	// files in include dirs can be included by path relative to include dir
+	seen := make(map[string]struct{})
+	for i := range res {
+		seen[res[i].insertText] = struct{}{}
+	}
+	for _, dir := range ss.IncludeDirs() {
+		items, err := ListDirAndFiles(dir, pathPrefix)
+		if err != nil {
+			continue
+		}
+		for i := range items {
+			if _, ok := seen[items[i].insertText]; ok {
+				continue
+			}
+			seen[items[i].insertText] = struct{}{}
+			res = append(res, items[i])
+		}
+	}
 
 	log.Debugln("include completion: ", res, "err", err)
 	return
@@ -32,4 +32,8 @@ type CompletionItem struct {
 
 	// Documentation holds document text for this completion
 	Documentation string
+
+	// AdditionalTextEdits holds edits applied when this completion is selected, such as
+	// adding an include of the file which defines completed type
+	AdditionalTextEdits []protocol.TextEdit
 }
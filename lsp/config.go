@@ -0,0 +1,365 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/joyme123/thrift-ls/format"
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/diagnostic"
+	utilerrors "github.com/joyme123/thrift-ls/utils/errors"
+	log "github.com/sirupsen/logrus"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+	"gopkg.in/yaml.v2"
+)
+
+// ProjectConfigFile is the config file at the root of workspace folder
+const ProjectConfigFile = ".thriftls.yaml"
+
+// ProjectConfig is the config of workspace folder. Options which aren't set in config file keep their
+// original values
+type ProjectConfig struct {
+	Format format.Options `yaml:"format"`
+	// IncludeDirs are directories searched for included files, like `thrift -I`. Relative directories are
+	// relative to workspace folder
+	IncludeDirs []string `yaml:"includeDirs"`
+	// Rules configure diagnostic rules by name: CycleCheck, Parse, FieldIDCheck and SemanticAnalysis
+	Rules map[string]RuleConfig `yaml:"rules"`
+	// Exclude are glob patterns of files and directories which aren't indexed. They are relative to workspace
+	// folder, and `**` matches any number of directories
+	Exclude []string `yaml:"exclude"`
+	// Dialect is the target thrift dialect: apache or thriftgo. Default is apache
+	Dialect string `yaml:"dialect"`
+
+	// indent holds indent options of formatter set in config file. indent of editor is used if they aren't set
+	indent formatIndent
+}
+
+type formatIndent struct {
+	IndentSize *int  `yaml:"indentSize"`
+	UseTabs    *bool `yaml:"useTabs"`
+}
+
+// RuleConfig configures a diagnostic rule
+type RuleConfig struct {
+	Disabled bool `yaml:"disabled"`
+	// Severity overrides severity of problems reported by rule: error, warning, information or hint
+	Severity string `yaml:"severity"`
+}
+
+// InitializationOptions are options sent by client in initialize request. They can be changed by
+// workspace/didChangeConfiguration
+type InitializationOptions struct {
+	// IncludeDirs are searched after include dirs in project config. Relative directories are relative to
+	// workspace folder
+	IncludeDirs []string `json:"includeDirs"`
+}
+
+// LoadProjectConfig reads config file in folder into cfg. It's not an error if config file doesn't exist
+func LoadProjectConfig(folder uri.URI, cfg *ProjectConfig) error {
+	data, err := os.ReadFile(filepath.Join(folder.Filename(), ProjectConfigFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return err
+	}
+	keys := struct {
+		Format formatIndent `yaml:"format"`
+	}{}
+	if err := yaml.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+	cfg.indent = keys.Format
+
+	return nil
+}
+
+// FindProjectFolder returns the nearest directory containing config file from dir up to root. It's used
+// by command line tools, which don't know workspace folder
+func FindProjectFolder(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ProjectConfigFile)); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// ViewOptions returns options of view of folder. includeDirs are searched after include dirs in config, and
+// relative directories are relative to folder. unknown dialect is reported as error and default dialect is used
+func (c *ProjectConfig) ViewOptions(folder string, includeDirs ...string) (cache.ViewOptions, error) {
+	dirs := make([]string, 0, len(c.IncludeDirs)+len(includeDirs))
+	for _, dir := range append(append([]string(nil), c.IncludeDirs...), includeDirs...) {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(folder, dir)
+		}
+		dirs = append(dirs, filepath.Clean(dir))
+	}
+
+	var err error
+	dialect := c.Dialect
+	if dialect != "" && dialect != cache.DialectApache && dialect != cache.DialectThriftgo {
+		err = fmt.Errorf("unknown dialect %s", dialect)
+		dialect = ""
+	}
+
+	return cache.ViewOptions{
+		IncludeDirs: dirs,
+		Dialect:     dialect,
+	}, err
+}
+
+// DiagnosticRules returns rules of diagnostics keyed by name of checker. invalid severities are reported as
+// error, and problems of these rules keep their severities
+func (c *ProjectConfig) DiagnosticRules() (map[string]diagnostic.Rule, error) {
+	var errs []error
+	rules := make(map[string]diagnostic.Rule, len(c.Rules))
+	for name, rc := range c.Rules {
+		rule := diagnostic.Rule{Disabled: rc.Disabled}
+		if rc.Severity != "" {
+			severity, ok := diagnostic.ParseSeverity(rc.Severity)
+			if !ok {
+				errs = append(errs, fmt.Errorf("invalid severity %s of rule %s", rc.Severity, name))
+			}
+			rule.Severity = severity
+		}
+		rules[name] = rule
+	}
+	if len(errs) > 0 {
+		return rules, utilerrors.NewAggregate(errs)
+	}
+
+	return rules, nil
+}
+
+// Excluded reports whether file or directory in folder is excluded. files in excluded directories are
+// excluded too
+func (c *ProjectConfig) Excluded(folder, filename string) bool {
+	rel, err := filepath.Rel(folder, filename)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	elems := strings.Split(filepath.ToSlash(rel), "/")
+
+	for _, pattern := range c.Exclude {
+		for i := range elems {
+			if matchGlob(pattern, strings.Join(elems[:i+1], "/")) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// configKey is the key of project config of folder in cache
+func configKey(folder uri.URI) string {
+	return filepath.Clean(folder.Filename())
+}
+
+// projectConfig returns project config of workspace folder. config file is loaded at the first time, and
+// loaded again after it's changed
+func (s *Server) projectConfig(folder uri.URI) *ProjectConfig {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	key := configKey(folder)
+	if cfg, ok := s.configs[key]; ok {
+		return cfg
+	}
+
+	cfg := &ProjectConfig{Format: format.DefaultOptions()}
+	if err := LoadProjectConfig(folder, cfg); err != nil {
+		log.Errorf("load project config of %s failed: %v", folder, err)
+	}
+	for name := range cfg.Rules {
+		if !isChecker(name) {
+			log.Warnf("unknown diagnostic rule %s in project config of %s", name, folder)
+		}
+	}
+	if s.configs == nil {
+		s.configs = make(map[string]*ProjectConfig)
+	}
+	s.configs[key] = cfg
+
+	return cfg
+}
+
+func isChecker(name string) bool {
+	for _, checker := range diagnostic.Checkers() {
+		if checker.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// viewOptions returns options of view of workspace folder. include dirs are from project config and
+// initialization options in order
+func (s *Server) viewOptions(folder uri.URI) cache.ViewOptions {
+	cfg := s.projectConfig(folder)
+
+	s.configMu.Lock()
+	includeDirs := append([]string(nil), s.initOptions.IncludeDirs...)
+	s.configMu.Unlock()
+
+	opts, err := cfg.ViewOptions(folder.Filename(), includeDirs...)
+	if err != nil {
+		log.Errorf("%v in project config of %s", err, folder)
+	}
+
+	return opts
+}
+
+// formatOptions returns options of formatter of workspace folder of view. indent is from editor if it isn't
+// set in project config
+func (s *Server) formatOptions(view *cache.View, options protocol.FormattingOptions) format.Options {
+	cfg := s.projectConfig(view.Folder())
+	opts := cfg.Format
+	if options.TabSize > 0 {
+		if cfg.indent.IndentSize == nil {
+			opts.IndentSize = int(options.TabSize)
+		}
+		if cfg.indent.UseTabs == nil {
+			opts.UseTabs = !options.InsertSpaces
+		}
+	}
+
+	return opts
+}
+
+// diagnosticRules returns rules of diagnostics of workspace folder
+func (s *Server) diagnosticRules(folder uri.URI) map[string]diagnostic.Rule {
+	rules, err := s.projectConfig(folder).DiagnosticRules()
+	if err != nil {
+		log.Errorf("%v in project config of %s", err, folder)
+	}
+
+	return rules
+}
+
+// excluded reports whether file or directory in workspace folder is excluded by project config
+func (s *Server) excluded(folder uri.URI, filename string) bool {
+	return s.projectConfig(folder).Excluded(folder.Filename(), filename)
+}
+
+// matchGlob reports whether slash separated name matches pattern. `**` matches any number of path
+// elements, and other elements are matched by path.Match
+func matchGlob(pattern, name string) bool {
+	return matchElems(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(name, "/"))
+}
+
+func matchElems(pattern, elems []string) bool {
+	if len(pattern) == 0 {
+		return len(elems) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(elems); i++ {
+			if matchElems(pattern[1:], elems[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(elems) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], elems[0])
+
+	return err == nil && ok && matchElems(pattern[1:], elems[1:])
+}
+
+// reloadProjectConfig loads project config of folders again and applies it to their views. Config of all
+// views is reloaded if folders is empty
+func (s *Server) reloadProjectConfig(ctx context.Context, folders []uri.URI) {
+	keys := make(map[string]bool)
+	for _, folder := range folders {
+		keys[configKey(folder)] = true
+	}
+	s.configMu.Lock()
+	for key := range s.configs {
+		if len(keys) == 0 || keys[key] {
+			delete(s.configs, key)
+		}
+	}
+	s.configMu.Unlock()
+
+	reloaded := false
+	for _, view := range s.session.Views() {
+		if len(keys) > 0 && !keys[configKey(view.Folder())] {
+			continue
+		}
+		reloaded = true
+		view.SetOptions(s.viewOptions(view.Folder()))
+
+		// includes are resolved again with new include dirs
+		ss, release := view.Snapshot()
+		files := ss.ParsedFiles()
+		release()
+		changes := make([]*cache.FileChange, 0, len(files))
+		for _, file := range files {
+			from := cache.FileChangeTypeConfigChange
+			if s.excluded(view.Folder(), file.Filename()) {
+				from = cache.FileChangeTypeExclude
+			}
+			changes = append(changes, &cache.FileChange{
+				URI:  file,
+				From: from,
+			})
+		}
+		view.FileChange(ctx, changes)
+		// files which aren't excluded any more are indexed
+		s.walkFoldersThriftFile(view.Folder())
+	}
+
+	if reloaded {
+		// rules of diagnostics may be changed
+		s.runInBackground(s.diagnosticWorkspace)
+		s.refreshDiagnostics()
+	}
+}
+
+func (s *Server) didChangeConfiguration(ctx context.Context, params *protocol.DidChangeConfigurationParams) error {
+	// settings may be in section of server. settings of other servers or without options of this server keep
+	// current options
+	var settings interface{}
+	if sections, ok := params.Settings.(map[string]interface{}); ok {
+		if section, ok := sections[ServerName]; ok {
+			settings = section
+		} else if _, ok := sections["includeDirs"]; ok {
+			settings = sections
+		}
+	}
+	if settings != nil {
+		opts := InitializationOptions{}
+		if err := decodeParams(settings, &opts); err != nil {
+			return err
+		}
+		s.configMu.Lock()
+		s.initOptions = opts
+		s.configMu.Unlock()
+	}
+
+	s.reloadProjectConfig(ctx, nil)
+
+	return nil
+}
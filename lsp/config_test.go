@@ -0,0 +1,325 @@
+package lsp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/format"
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/memoize"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func Test_LoadProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg := &ProjectConfig{Format: format.DefaultOptions()}
+	assert.NoError(t, LoadProjectConfig(uri.File(dir), cfg))
+	assert.Equal(t, format.DefaultOptions(), cfg.Format)
+
+	content := `format:
+  useTabs: true
+  fieldSeparator: comma
+  maxLineWidth: 100
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ProjectConfigFile), []byte(content), 0644))
+
+	cfg = &ProjectConfig{Format: format.DefaultOptions()}
+	assert.NoError(t, LoadProjectConfig(uri.File(dir), cfg))
+
+	want := format.DefaultOptions()
+	want.UseTabs = true
+	want.FieldSeparator = format.FieldSeparatorComma
+	want.MaxLineWidth = 100
+	assert.Equal(t, want, cfg.Format)
+}
+
+func Test_FormatOptions(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	config := `format:
+  useTabs: true
+  fieldSeparator: comma
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ProjectConfigFile), []byte(config), 0644))
+
+	srv := NewServer(cache.New(&memoize.Store{}), nil)
+	_, err := srv.Initialize(ctx, &protocol.InitializeParams{RootURI: uri.File(dir)})
+	assert.NoError(t, err)
+	assert.NoError(t, srv.Initialized(ctx, &protocol.InitializedParams{}))
+	srv.background.Wait()
+	view, err := srv.session.ViewOf(uri.File(filepath.Join(dir, "api.thrift")))
+	assert.NoError(t, err)
+
+	// indent size is from editor, and options in config override options of editor
+	want := format.DefaultOptions()
+	want.IndentSize = 2
+	want.UseTabs = true
+	want.FieldSeparator = format.FieldSeparatorComma
+	assert.Equal(t, want, srv.formatOptions(view, protocol.FormattingOptions{TabSize: 2, InsertSpaces: true}))
+
+	// config is loaded again only after it's changed
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ProjectConfigFile), []byte("format: ["), 0644))
+	assert.Equal(t, want, srv.formatOptions(view, protocol.FormattingOptions{TabSize: 2, InsertSpaces: true}))
+}
+
+func Test_IncludeDirs(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	files := map[string]string{
+		ProjectConfigFile:    "includeDirs:\n  - common\n",
+		"common/base.thrift": "struct Base {\n    1: string id\n}\n",
+		"shared/user.thrift": "struct User {\n    1: string name\n}\n",
+		"svc/api.thrift":     "include \"base.thrift\"\ninclude \"user.thrift\"\n\nstruct Req {\n    1: base.Base base\n    2: user.User user\n}\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	apiURI := uri.File(filepath.Join(dir, "svc/api.thrift"))
+
+	client := &recordClient{}
+	srv := NewServer(cache.New(&memoize.Store{}), client)
+	_, err := srv.Initialize(ctx, &protocol.InitializeParams{
+		RootURI:               uri.File(dir),
+		InitializationOptions: map[string]interface{}{"includeDirs": []string{"shared", "/not/exist"}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, srv.Initialized(ctx, &protocol.InitializedParams{}))
+	srv.background.Wait()
+
+	ss, release, _, err := srv.getFileContext(ctx, apiURI)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "common"), filepath.Join(dir, "shared"), "/not/exist"}, ss.IncludeDirs())
+	release()
+
+	// included files are found in include dirs
+	diagnostics := client.takeDiagnostics()
+	assert.Contains(t, diagnostics, apiURI)
+	assert.Empty(t, diagnostics[apiURI])
+
+	locations, err := srv.Definition(ctx, &protocol.DefinitionParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: apiURI},
+			Position:     protocol.Position{Line: 5, Character: 15},
+		},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, locations, 1) {
+		assert.Equal(t, uri.File(filepath.Join(dir, "shared/user.thrift")), locations[0].URI)
+	}
+
+	// include paths are completed from include dirs
+	newURI := uri.File(filepath.Join(dir, "svc/new.thrift"))
+	err = srv.DidOpen(ctx, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{URI: newURI, LanguageID: "thrift", Text: "include \"b\"\n"},
+	})
+	assert.NoError(t, err)
+	completion, err := srv.Completion(ctx, &protocol.CompletionParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: newURI},
+			Position:     protocol.Position{Line: 0, Character: 10},
+		},
+	})
+	assert.NoError(t, err)
+	labels := make([]string, 0)
+	for _, item := range completion.Items {
+		labels = append(labels, item.Label)
+	}
+	assert.Equal(t, []string{"base.thrift"}, labels)
+}
+
+func Test_matchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "gen", name: "gen", want: true},
+		{pattern: "gen", name: "svc/gen", want: false},
+		{pattern: "**/gen", name: "svc/gen", want: true},
+		{pattern: "**/gen", name: "gen", want: true},
+		{pattern: "vendor/**", name: "vendor/a/b.thrift", want: true},
+		{pattern: "**/*_test.thrift", name: "a/b/c_test.thrift", want: true},
+		{pattern: "**/*_test.thrift", name: "a/b/c.thrift", want: false},
+		{pattern: "a/**/c.thrift", name: "a/c.thrift", want: true},
+		{pattern: "a/*.thrift", name: "a/b/c.thrift", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchGlob(tt.pattern, tt.name))
+		})
+	}
+}
+
+func Test_excluded(t *testing.T) {
+	dir := t.TempDir()
+	config := "exclude:\n  - \"**/gen\"\n  - \"*_test.thrift\"\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ProjectConfigFile), []byte(config), 0644))
+	srv := NewServer(cache.New(&memoize.Store{}), nil)
+
+	folder := uri.File(dir)
+	assert.True(t, srv.excluded(folder, filepath.Join(dir, "svc/gen")))
+	// files in excluded directories are excluded
+	assert.True(t, srv.excluded(folder, filepath.Join(dir, "svc/gen/a/b.thrift")))
+	assert.True(t, srv.excluded(folder, filepath.Join(dir, "a_test.thrift")))
+	assert.False(t, srv.excluded(folder, filepath.Join(dir, "svc/a_test.thrift")))
+	assert.False(t, srv.excluded(folder, filepath.Join(dir, "svc/generated.thrift")))
+	assert.False(t, srv.excluded(folder, dir))
+}
+
+func Test_ProjectConfig(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	config := `rules:
+  FieldIDCheck:
+    severity: warning
+exclude:
+  - "**/gen"
+dialect: thriftgo
+`
+	files := map[string]string{
+		ProjectConfigFile:    config,
+		"api.thrift":         "struct A {\n    1: uuid id\n    1: string name\n}\n",
+		"svc/gen/gen.thrift": "struct Broken {\n    1: string\n}\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	apiURI := uri.File(filepath.Join(dir, "api.thrift"))
+	genURI := uri.File(filepath.Join(dir, "svc/gen/gen.thrift"))
+
+	client := &recordClient{}
+	srv := NewServer(cache.New(&memoize.Store{}), client)
+	_, err := srv.Initialize(ctx, &protocol.InitializeParams{RootURI: uri.File(dir)})
+	assert.NoError(t, err)
+	assert.NoError(t, srv.Initialized(ctx, &protocol.InitializedParams{}))
+	srv.background.Wait()
+
+	severities := func(items []protocol.Diagnostic) map[string][]protocol.DiagnosticSeverity {
+		res := make(map[string][]protocol.DiagnosticSeverity)
+		for _, item := range items {
+			res[item.Message] = append(res[item.Message], item.Severity)
+		}
+		return res
+	}
+
+	// excluded files aren't diagnosed, uuid isn't a basic type of thriftgo
+	diagnostics := client.takeDiagnostics()
+	assert.NotContains(t, diagnostics, genURI)
+	assert.Equal(t, map[string][]protocol.DiagnosticSeverity{
+		"field id conflict":        {protocol.DiagnosticSeverityWarning, protocol.DiagnosticSeverityWarning},
+		"field type doesn't exist": {protocol.DiagnosticSeverityError},
+	}, severities(diagnostics[apiURI]))
+
+	// changes of config file are applied
+	config = `rules:
+  FieldIDCheck:
+    disabled: true
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ProjectConfigFile), []byte(config), 0644))
+	err = srv.DidChangeWatchedFiles(ctx, &protocol.DidChangeWatchedFilesParams{
+		Changes: []*protocol.FileEvent{
+			{URI: uri.File(filepath.Join(dir, ProjectConfigFile)), Type: protocol.FileChangeTypeChanged},
+		},
+	})
+	assert.NoError(t, err)
+	srv.background.Wait()
+
+	diagnostics = client.takeDiagnostics()
+	assert.Contains(t, diagnostics, apiURI)
+	assert.Empty(t, diagnostics[apiURI])
+	assert.Contains(t, diagnostics, genURI)
+	assert.NotEmpty(t, diagnostics[genURI])
+
+	// files excluded again are removed from snapshot
+	config = "exclude:\n  - \"**/gen\"\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ProjectConfigFile), []byte(config), 0644))
+	err = srv.DidChangeWatchedFiles(ctx, &protocol.DidChangeWatchedFilesParams{
+		Changes: []*protocol.FileEvent{
+			{URI: uri.File(filepath.Join(dir, ProjectConfigFile)), Type: protocol.FileChangeTypeChanged},
+		},
+	})
+	assert.NoError(t, err)
+	srv.background.Wait()
+
+	ss, release, _, err := srv.getFileContext(ctx, apiURI)
+	assert.NoError(t, err)
+	assert.Contains(t, ss.ParsedFiles(), apiURI)
+	assert.NotContains(t, ss.ParsedFiles(), genURI)
+	release()
+}
+
+func Test_DidChangeConfiguration(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	files := map[string]string{
+		"common/base.thrift": "struct Base {\n    1: string id\n}\n",
+		"api.thrift":         "include \"base.thrift\"\n\nstruct Req {\n    1: base.Base base\n}\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	apiURI := uri.File(filepath.Join(dir, "api.thrift"))
+
+	client := &recordClient{}
+	srv := NewServer(cache.New(&memoize.Store{}), client)
+	_, err := srv.Initialize(ctx, &protocol.InitializeParams{RootURI: uri.File(dir)})
+	assert.NoError(t, err)
+	assert.NoError(t, srv.Initialized(ctx, &protocol.InitializedParams{}))
+	srv.background.Wait()
+
+	diagnostics := client.takeDiagnostics()
+	assert.NotEmpty(t, diagnostics[apiURI])
+
+	// settings in section of server are applied
+	err = srv.DidChangeConfiguration(ctx, &protocol.DidChangeConfigurationParams{
+		Settings: map[string]interface{}{
+			ServerName: map[string]interface{}{"includeDirs": []interface{}{"common"}},
+		},
+	})
+	assert.NoError(t, err)
+	srv.background.Wait()
+
+	ss, release, _, err := srv.getFileContext(ctx, apiURI)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "common")}, ss.IncludeDirs())
+	release()
+
+	diagnostics = client.takeDiagnostics()
+	assert.Contains(t, diagnostics, apiURI)
+	assert.Empty(t, diagnostics[apiURI])
+
+	// settings of other sections don't reset options
+	err = srv.DidChangeConfiguration(ctx, &protocol.DidChangeConfigurationParams{
+		Settings: map[string]interface{}{
+			"editor": map[string]interface{}{"tabSize": 4},
+		},
+	})
+	assert.NoError(t, err)
+	srv.background.Wait()
+
+	ss, release, _, err = srv.getFileContext(ctx, apiURI)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "common")}, ss.IncludeDirs())
+	release()
+	assert.Empty(t, client.takeDiagnostics()[apiURI])
+
+	// settings without section are applied
+	err = srv.DidChangeConfiguration(ctx, &protocol.DidChangeConfigurationParams{
+		Settings: map[string]interface{}{"includeDirs": []interface{}{}},
+	})
+	assert.NoError(t, err)
+	srv.background.Wait()
+
+	ss, release, _, err = srv.getFileContext(ctx, apiURI)
+	assert.NoError(t, err)
+	assert.Empty(t, ss.IncludeDirs())
+	release()
+}
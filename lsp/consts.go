@@ -1,8 +1,14 @@
 package lsp
 
+import "time"
+
 const (
 	ServerName    = "thriftls"
 	ServerVersion = "0.1"
 
 	LanguageIDThrift = "thrift"
 )
+
+// dependentDiagnosticsDelay is the delay of diagnosing files including changed file, so a burst of
+// keystrokes triggers one pass
+const dependentDiagnosticsDelay = 500 * time.Millisecond
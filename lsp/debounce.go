@@ -0,0 +1,65 @@
+package lsp
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.lsp.dev/uri"
+)
+
+// filesDebouncer collects files scheduled in a burst, and runs fn with them once no file is scheduled
+// for delay
+type filesDebouncer struct {
+	delay time.Duration
+	fn    func(files []uri.URI)
+
+	mu    sync.Mutex
+	timer *time.Timer
+	files map[uri.URI]struct{}
+}
+
+func newFilesDebouncer(delay time.Duration, fn func(files []uri.URI)) *filesDebouncer {
+	return &filesDebouncer{
+		delay: delay,
+		fn:    fn,
+		files: make(map[uri.URI]struct{}),
+	}
+}
+
+// Schedule adds files to current burst, and delays running fn
+func (d *filesDebouncer) Schedule(files []uri.URI) {
+	if len(files) == 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, file := range files {
+		d.files[file] = struct{}{}
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(d.delay, d.run)
+}
+
+func (d *filesDebouncer) run() {
+	d.mu.Lock()
+	files := make([]uri.URI, 0, len(d.files))
+	for file := range d.files {
+		files = append(files, file)
+	}
+	d.files = make(map[uri.URI]struct{})
+	d.timer = nil
+	d.mu.Unlock()
+
+	if len(files) == 0 {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i] < files[j]
+	})
+	d.fn(files)
+}
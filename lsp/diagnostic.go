@@ -2,6 +2,7 @@ package lsp
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/joyme123/thrift-ls/lsp/cache"
 	"github.com/joyme123/thrift-ls/lsp/diagnostic"
@@ -11,16 +12,17 @@ import (
 	"go.lsp.dev/uri"
 )
 
-func (s *Server) diagnostic(ctx context.Context, ss *cache.Snapshot, changeFile *cache.FileChange) error {
-	if s.client == nil {
+func (s *Server) diagnostic(ctx context.Context, ss *cache.Snapshot, files []uri.URI) error {
+	// client pulls diagnostics by itself
+	if s.client == nil || s.pullDiagnostics {
 		return nil
 	}
 
 	log.Debugln("-----------diagnostic called-----------")
 	defer log.Debugln("-----------diagnostic finish-----------")
 
-	diag := diagnostic.NewDiagnostic()
-	diagRes, err := diag.Diagnostic(ctx, ss, []uri.URI{changeFile.URI})
+	diag := diagnostic.NewDiagnosticWithRules(s.diagnosticRules(ss.Folder()))
+	diagRes, err := diag.Diagnostic(ctx, ss, files)
 	if err != nil {
 		log.Errorf("diagnostic failed: %v", err)
 	}
@@ -48,3 +50,105 @@ func (s *Server) diagnostic(ctx context.Context, ss *cache.Snapshot, changeFile
 	}
 	return nil
 }
+
+// dependentFiles returns files including files directly or indirectly, files themselves are excluded
+func dependentFiles(ss *cache.Snapshot, files []uri.URI) []uri.URI {
+	visited := make(map[uri.URI]bool)
+	for _, file := range files {
+		visited[file] = true
+	}
+
+	res := make([]uri.URI, 0)
+	queue := append([]uri.URI(nil), files...)
+	for len(queue) > 0 {
+		file := queue[0]
+		queue = queue[1:]
+
+		node := ss.Graph().Get(file)
+		if node == nil {
+			continue
+		}
+		for _, includer := range node.InDegree() {
+			if visited[includer] {
+				continue
+			}
+			visited[includer] = true
+			res = append(res, includer)
+			queue = append(queue, includer)
+		}
+	}
+
+	return res
+}
+
+// diagnosticDependents schedules diagnostics of files including changed files. dependents are diagnosed
+// once after a burst of changes
+func (s *Server) diagnosticDependents(ss *cache.Snapshot, changedFiles []uri.URI) {
+	s.dependentDiagnostics.Schedule(dependentFiles(ss, changedFiles))
+}
+
+// diagnosticFiles diagnoses files with the latest snapshots of their views
+func (s *Server) diagnosticFiles(files []uri.URI) {
+	views := make([]*cache.View, 0)
+	viewFiles := make(map[*cache.View][]uri.URI)
+	for _, file := range files {
+		view, err := s.session.ViewOf(file)
+		if err != nil {
+			log.Errorf("view of %s not found: %v", file, err)
+			continue
+		}
+		if _, ok := viewFiles[view]; !ok {
+			views = append(views, view)
+		}
+		viewFiles[view] = append(viewFiles[view], file)
+	}
+
+	for _, view := range views {
+		ss, release := view.Snapshot()
+		err := s.diagnostic(context.Background(), ss, viewFiles[view])
+		release()
+		if err != nil {
+			log.Errorf("diagnostic error: %v", err)
+		}
+	}
+}
+
+// diagnosticWorkspace diagnoses all thrift files of workspace folders, and publishes results for every
+// file. it's slow for large workspace, so it should run in background
+func (s *Server) diagnosticWorkspace() {
+	if s.client == nil || s.pullDiagnostics {
+		return
+	}
+
+	ctx := context.Background()
+	files := make([]uri.URI, 0)
+	for _, view := range s.session.Views() {
+		ss, release := view.Snapshot()
+		files = append(files, s.workspaceFiles(view, ss)...)
+		release()
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	progress := s.newWorkDoneProgress(ctx, "Diagnosing workspace")
+	for i, file := range files {
+		// latest snapshot is used, files changed during diagnosing don't get outdated results
+		s.diagnosticFiles([]uri.URI{file})
+		progress.Report(ctx, fmt.Sprintf("%d/%d files", i+1, len(files)), uint32((i+1)*100/len(files)))
+	}
+	progress.End(ctx, fmt.Sprintf("%d files diagnosed", len(files)))
+}
+
+// workspaceFiles returns files of workspace folder of view. files out of folder which are included, and files
+// excluded by project config are excluded
+func (s *Server) workspaceFiles(view *cache.View, ss *cache.Snapshot) []uri.URI {
+	files := make([]uri.URI, 0)
+	for _, file := range ss.ParsedFiles() {
+		if view.ContainsFile(file) && !s.excluded(view.Folder(), file.Filename()) {
+			files = append(files, file)
+		}
+	}
+
+	return files
+}
@@ -0,0 +1,119 @@
+package diagnostic
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/utils/errors"
+	log "github.com/sirupsen/logrus"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+var codeActionRegistry []CodeActionInterface
+
+func init() {
+	codeActionRegistry = []CodeActionInterface{
+		&CycleCheck{},
+		&FieldIDCheck{},
+		&SemanticAnalysis{},
+	}
+}
+
+// CodeActionKinds are kinds of code actions provided by diagnostics
+var CodeActionKinds = []protocol.CodeActionKind{
+	protocol.QuickFix,
+}
+
+const (
+	DataKindCycleInclude      = "cycleInclude"
+	DataKindFieldIDConflict   = "fieldIDConflict"
+	DataKindFieldIDOutOfRange = "fieldIDOutOfRange"
+	DataKindNameConflict      = "nameConflict"
+)
+
+// DiagnosticData is attached to protocol.Diagnostic as Data. It records what is needed to fix
+// the diagnostic, so code actions can be computed without analysing again
+type DiagnosticData struct {
+	// Checker is the name of diagnostic implementation which reports the diagnostic
+	Checker string `json:"checker"`
+	Kind    string `json:"kind"`
+
+	// FieldID is the next free field id in struct like definition
+	FieldID int `json:"fieldID,omitempty"`
+	// NewName is a name doesn't conflict with other definitions
+	NewName string `json:"newName,omitempty"`
+	// Range is the range to be removed
+	Range *protocol.Range `json:"range,omitempty"`
+}
+
+// DataOf returns data of diagnostic. Data sent back by client is decoded from json
+func DataOf(diagnostic protocol.Diagnostic) (*DiagnosticData, bool) {
+	switch data := diagnostic.Data.(type) {
+	case nil:
+		return nil, false
+	case *DiagnosticData:
+		return data, data != nil
+	case DiagnosticData:
+		return &data, true
+	}
+
+	raw, err := json.Marshal(diagnostic.Data)
+	if err != nil {
+		return nil, false
+	}
+	data := &DiagnosticData{}
+	if err := json.Unmarshal(raw, data); err != nil || data.Checker == "" {
+		return nil, false
+	}
+
+	return data, true
+}
+
+type CodeActionInterface interface {
+	CodeActions(ctx context.Context, ss *cache.Snapshot, file uri.URI, diagnostic protocol.Diagnostic, data *DiagnosticData) ([]protocol.CodeAction, error)
+	Name() string
+}
+
+// CodeActions returns quick fixes of diagnostics. Diagnostic is dispatched to the implementation
+// which reports it
+func CodeActions(ctx context.Context, ss *cache.Snapshot, file uri.URI, diagnostics []protocol.Diagnostic) ([]protocol.CodeAction, error) {
+	res := make([]protocol.CodeAction, 0)
+	var errs []error
+	for _, diagnostic := range diagnostics {
+		data, ok := DataOf(diagnostic)
+		if !ok {
+			continue
+		}
+		for _, impl := range codeActionRegistry {
+			if impl.Name() != data.Checker {
+				continue
+			}
+			log.Debugln("code action called: ", impl.Name())
+			actions, err := impl.CodeActions(ctx, ss, file, diagnostic, data)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			res = append(res, actions...)
+		}
+	}
+	if len(errs) > 0 {
+		return res, errors.NewAggregate(errs)
+	}
+	return res, nil
+}
+
+func quickFix(title string, file uri.URI, diagnostic protocol.Diagnostic, edits ...protocol.TextEdit) protocol.CodeAction {
+	return protocol.CodeAction{
+		Title:       title,
+		Kind:        protocol.QuickFix,
+		Diagnostics: []protocol.Diagnostic{diagnostic},
+		IsPreferred: true,
+		Edit: &protocol.WorkspaceEdit{
+			Changes: map[uri.URI][]protocol.TextEdit{
+				file: edits,
+			},
+		},
+	}
+}
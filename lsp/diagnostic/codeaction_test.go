@@ -0,0 +1,166 @@
+package diagnostic
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestCodeActions(t *testing.T) {
+	file1 := `// comment of include
+include "b.thrift"
+
+struct A {
+  1: string a,
+  1: string b,
+  2: string b,
+}`
+	file2 := `include "a.thrift"`
+
+	ss := buildSnapshotForTest([]*cache.FileChange{
+		{
+			URI:     "file:///tmp/a.thrift",
+			Version: 0,
+			Content: []byte(file1),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+		{
+			URI:     "file:///tmp/b.thrift",
+			Version: 0,
+			Content: []byte(file2),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	})
+
+	edit := func(startLine, startChar, endLine, endChar uint32, newText string) []protocol.TextEdit {
+		return []protocol.TextEdit{
+			{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: startLine, Character: startChar},
+					End:   protocol.Position{Line: endLine, Character: endChar},
+				},
+				NewText: newText,
+			},
+		}
+	}
+
+	type action struct {
+		title string
+		edits []protocol.TextEdit
+	}
+
+	tests := []struct {
+		name      string
+		impl      Interface
+		file      uri.URI
+		want      []action
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name: "field id conflict",
+			impl: &FieldIDCheck{},
+			file: "file:///tmp/a.thrift",
+			want: []action{
+				{title: "Change field id to 3", edits: edit(5, 2, 5, 3, "3")},
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "field name conflict",
+			impl: &SemanticAnalysis{},
+			file: "file:///tmp/a.thrift",
+			want: []action{
+				{title: "Rename to b2", edits: edit(6, 12, 6, 13, "b2")},
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "cycle include",
+			impl: &CycleCheck{},
+			file: "file:///tmp/a.thrift",
+			want: []action{
+				{title: "Remove cyclic include", edits: edit(1, 0, 2, 0, "")},
+			},
+			assertion: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagRes, err := tt.impl.Diagnostic(context.TODO(), ss, []uri.URI{tt.file})
+			assert.NoError(t, err)
+
+			// data is sent back by client as json
+			data, err := json.Marshal(diagRes[tt.file])
+			assert.NoError(t, err)
+			var diagnostics []protocol.Diagnostic
+			assert.NoError(t, json.Unmarshal(data, &diagnostics))
+
+			actions, err := CodeActions(context.TODO(), ss, tt.file, diagnostics)
+			tt.assertion(t, err)
+
+			var got []action
+			for _, item := range actions {
+				assert.Equal(t, protocol.QuickFix, item.Kind)
+				got = append(got, action{title: item.Title, edits: item.Edit.Changes[tt.file]})
+			}
+			sort.SliceStable(got, func(i, j int) bool {
+				return got[i].edits[0].Range.Start.Line < got[j].edits[0].Range.Start.Line
+			})
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFieldIDQuickFixes(t *testing.T) {
+	file := uri.URI("file:///tmp/a.thrift")
+	content := `struct A {
+  1: string a,
+  1: string b,
+  2: string c,
+  1: string d,
+  0: string e,
+  2: string f,
+}`
+	diagnose := func(content string) []protocol.Diagnostic {
+		ss := buildSnapshotForTest([]*cache.FileChange{
+			{URI: file, Content: []byte(content), From: cache.FileChangeTypeDidOpen},
+		})
+		diagRes, err := (&FieldIDCheck{}).Diagnostic(context.TODO(), ss, []uri.URI{file})
+		assert.NoError(t, err)
+
+		data, err := json.Marshal(diagRes[file])
+		assert.NoError(t, err)
+		var diagnostics []protocol.Diagnostic
+		assert.NoError(t, json.Unmarshal(data, &diagnostics))
+		return diagnostics
+	}
+
+	ss := buildSnapshotForTest([]*cache.FileChange{
+		{URI: file, Content: []byte(content), From: cache.FileChangeTypeDidOpen},
+	})
+	actions, err := CodeActions(context.TODO(), ss, file, diagnose(content))
+	assert.NoError(t, err)
+
+	// the first field of conflicting ids keeps its id
+	lines := strings.Split(content, "\n")
+	fixed := make(map[uint32]string)
+	for _, action := range actions {
+		for _, edit := range action.Edit.Changes[file] {
+			line := edit.Range.Start.Line
+			assert.NotContains(t, fixed, line)
+			fixed[line] = edit.NewText
+			lines[line] = lines[line][:edit.Range.Start.Character] + edit.NewText + lines[line][edit.Range.End.Character:]
+		}
+	}
+	assert.Equal(t, map[uint32]string{2: "3", 4: "4", 5: "5", 6: "6"}, fixed)
+
+	// applying all fixes resolves all problems
+	assert.Empty(t, diagnose(strings.Join(lines, "\n")))
+}
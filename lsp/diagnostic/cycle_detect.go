@@ -44,10 +44,45 @@ func cyclePairToDiagnostic(pair CyclePair) protocol.Diagnostic {
 		Severity: protocol.DiagnosticSeverityWarning,
 		Source:   "thrift-ls",
 		Message:  fmt.Sprintf("cycle dependency in %s", pair.include.file),
+		Data: &DiagnosticData{
+			Checker: (&CycleCheck{}).Name(),
+			Kind:    DataKindCycleInclude,
+			Range:   includeLineRange(pair.include.include),
+		},
 	}
 	return res
 }
 
+// includeLineRange returns range of lines of include statement, leading comments are kept
+func includeLineRange(include *parser.Include) *protocol.Range {
+	start := include.Pos().Line
+	if include.IncludeKeyword != nil && include.IncludeKeyword.Literal != nil {
+		start = include.IncludeKeyword.Literal.Pos().Line
+	}
+	end := include.End().Line
+	if include.Path != nil {
+		end = include.Path.End().Line
+	}
+
+	return &protocol.Range{
+		Start: protocol.Position{Line: uint32(start - 1)},
+		End:   protocol.Position{Line: uint32(end)},
+	}
+}
+
+func (c *CycleCheck) CodeActions(ctx context.Context, ss *cache.Snapshot, file uri.URI, diagnostic protocol.Diagnostic, data *DiagnosticData) ([]protocol.CodeAction, error) {
+	if data.Range == nil {
+		return nil, nil
+	}
+
+	return []protocol.CodeAction{
+		quickFix("Remove cyclic include", file, diagnostic, protocol.TextEdit{
+			Range:   *data.Range,
+			NewText: "",
+		}),
+	}, nil
+}
+
 type Include struct {
 	file    uri.URI
 	include *parser.Include
@@ -94,11 +129,11 @@ func getIncludes(ctx context.Context, ss *cache.Snapshot, file uri.URI, includes
 			continue
 		}
 		(*includesMap)[file] = append((*includesMap)[file], Include{
-			file:    lsputils.IncludeURI(file, includes[i].Path.Value.Text),
+			file:    lsputils.IncludeURI(file, includes[i].Path.Value.Text, ss.IncludeDirs()...),
 			include: includes[i],
 		})
 
-		includeURI := lsputils.IncludeURI(file, includes[i].Path.Value.Text)
+		includeURI := lsputils.IncludeURI(file, includes[i].Path.Value.Text, ss.IncludeDirs()...)
 		if _, ok := (*includesMap)[includeURI]; ok {
 			continue
 		}
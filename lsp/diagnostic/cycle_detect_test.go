@@ -480,7 +480,7 @@ func buildSnapshotForTest(files []*cache.FileChange) *cache.Snapshot {
 	fs := cache.NewOverlayFS(c)
 	fs.Update(context.TODO(), files)
 
-	view := cache.NewView("test", "file:///tmp", fs, store)
+	view := cache.NewView("test", "file:///tmp", cache.ViewOptions{}, fs, store)
 	ss := cache.NewSnapshot(view, store)
 
 	return ss
@@ -21,28 +21,56 @@ func init() {
 	}
 }
 
+// Checkers returns registered diagnostic implementations in order
+func Checkers() []Interface {
+	return append([]Interface(nil), registry...)
+}
+
 type Interface interface {
 	Diagnostic(ctx context.Context, ss *cache.Snapshot, changeFiles []uri.URI) (DiagnosticResult, error)
 	Name() string
 }
 
+// Rule configures a registered checker
+type Rule struct {
+	// Disabled checker isn't run
+	Disabled bool
+	// Severity overrides severity of diagnostics reported by checker if it isn't zero
+	Severity protocol.DiagnosticSeverity
+}
+
 type Diagnostic struct {
+	// rules are keyed by name of checker
+	rules map[string]Rule
 }
 
 func NewDiagnostic() Interface {
 	return &Diagnostic{}
 }
 
+// NewDiagnosticWithRules returns diagnostic running registered checkers configured by rules. rules are keyed
+// by name of checker, and checkers without rule run as default
+func NewDiagnosticWithRules(rules map[string]Rule) Interface {
+	return &Diagnostic{rules: rules}
+}
+
 func (d *Diagnostic) Diagnostic(ctx context.Context, ss *cache.Snapshot, changeFiles []uri.URI) (DiagnosticResult, error) {
 	res := make(DiagnosticResult)
 	var errs []error
 	for _, impl := range registry {
+		rule := d.rules[impl.Name()]
+		if rule.Disabled {
+			continue
+		}
 		log.Debugln("diagnostic called: ", impl.Name())
 		diagRes, err := impl.Diagnostic(ctx, ss, changeFiles)
 		if err != nil {
 			errs = append(errs, err)
 		}
 		for key, items := range diagRes {
+			if rule.Severity != 0 {
+				items = withSeverity(items, rule.Severity)
+			}
 			res[key] = append(res[key], items...)
 		}
 	}
@@ -57,4 +85,36 @@ func (d *Diagnostic) Name() string {
 	return "Diagnostic"
 }
 
+// withSeverity returns copy of items with severity changed
+func withSeverity(items []protocol.Diagnostic, severity protocol.DiagnosticSeverity) []protocol.Diagnostic {
+	res := make([]protocol.Diagnostic, len(items))
+	for i := range items {
+		res[i] = items[i]
+		res[i].Severity = severity
+	}
+	return res
+}
+
 type DiagnosticResult map[uri.URI][]protocol.Diagnostic
+
+var severityNames = map[protocol.DiagnosticSeverity]string{
+	protocol.DiagnosticSeverityError:       "error",
+	protocol.DiagnosticSeverityWarning:     "warning",
+	protocol.DiagnosticSeverityInformation: "information",
+	protocol.DiagnosticSeverityHint:        "hint",
+}
+
+// SeverityName returns name of severity: error, warning, information or hint
+func SeverityName(severity protocol.DiagnosticSeverity) string {
+	return severityNames[severity]
+}
+
+// ParseSeverity returns severity of name: error, warning, information or hint
+func ParseSeverity(name string) (protocol.DiagnosticSeverity, bool) {
+	for severity, severityName := range severityNames {
+		if severityName == name {
+			return severity, true
+		}
+	}
+	return 0, false
+}
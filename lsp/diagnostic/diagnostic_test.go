@@ -0,0 +1,76 @@
+package diagnostic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/memoize"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestDiagnosticWithRules(t *testing.T) {
+	file := uri.URI("file:///tmp/user.thrift")
+	content := `struct User {
+	1: uuid id
+	1: Unknown name
+}
+`
+	tests := []struct {
+		name    string
+		dialect string
+		rules   map[string]Rule
+		want    []protocol.Diagnostic
+	}{
+		{
+			name: "default rules",
+			want: []protocol.Diagnostic{
+				{Message: "field id conflict", Severity: protocol.DiagnosticSeverityError},
+				{Message: "field id conflict", Severity: protocol.DiagnosticSeverityError},
+				{Message: "field type doesn't exist", Severity: protocol.DiagnosticSeverityError},
+			},
+		},
+		{
+			name: "disable rule and change severity",
+			rules: map[string]Rule{
+				"FieldIDCheck":     {Disabled: true},
+				"SemanticAnalysis": {Severity: protocol.DiagnosticSeverityWarning},
+			},
+			want: []protocol.Diagnostic{
+				{Message: "field type doesn't exist", Severity: protocol.DiagnosticSeverityWarning},
+			},
+		},
+		{
+			name:    "uuid isn't a basic type of thriftgo",
+			dialect: cache.DialectThriftgo,
+			rules: map[string]Rule{
+				"FieldIDCheck": {Disabled: true},
+			},
+			want: []protocol.Diagnostic{
+				{Message: "field type doesn't exist", Severity: protocol.DiagnosticSeverityError},
+				{Message: "field type doesn't exist", Severity: protocol.DiagnosticSeverityError},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoize.Store{}
+			fs := cache.NewOverlayFS(cache.New(store))
+			fs.Update(context.TODO(), []*cache.FileChange{
+				{URI: file, Content: []byte(content), From: cache.FileChangeTypeDidOpen},
+			})
+			view := cache.NewView("test", "file:///tmp", cache.ViewOptions{Dialect: tt.dialect}, fs, store)
+			ss := cache.NewSnapshot(view, store)
+
+			res, err := NewDiagnosticWithRules(tt.rules).Diagnostic(context.TODO(), ss, []uri.URI{file})
+			assert.NoError(t, err)
+			got := make([]protocol.Diagnostic, 0)
+			for _, item := range res[file] {
+				got = append(got, protocol.Diagnostic{Message: item.Message, Severity: item.Severity})
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/joyme123/thrift-ls/lsp/cache"
 	"github.com/joyme123/thrift-ls/lsp/lsputils"
@@ -53,40 +54,65 @@ func (c *FieldIDCheck) diagnostic(ctx context.Context, ss *cache.Snapshot, file
 	var ret []protocol.Diagnostic
 
 	processStructLike := func(fields []*parser.Field) {
-		fieldIDSet := make(map[int][]*parser.Field)
+		fieldIDCount := make(map[int]int)
+		maxFieldID := 0
 		for i := range fields {
 			field := fields[i]
 			if field.Index == nil || field.Index.BadNode {
 				continue
 			}
-			fieldIDSet[field.Index.Value] = append(fieldIDSet[field.Index.Value], field)
+			fieldIDCount[field.Index.Value]++
+			if field.Index.Value > maxFieldID && field.Index.Value <= 32767 {
+				maxFieldID = field.Index.Value
+			}
 		}
 
-		for fieldID, set := range fieldIDSet {
-			if fieldID < 1 || fieldID > 32767 {
-				for _, field := range set {
-					// field ID exceeded
-					ret = append(ret, protocol.Diagnostic{
-						Range:    lsputils.ASTNodeToRange(field.Index),
-						Severity: protocol.DiagnosticSeverityError,
-						Source:   "thrift-ls",
-						Message:  fmt.Sprintf("field id should be a positive integer in [1, 32767]"),
-					})
-				}
+		// every fix gets a distinct free field id, so applying all fixes doesn't make new conflicts
+		nextFieldID := maxFieldID
+		freeFieldID := func() int {
+			if nextFieldID >= 32767 {
+				return 0
 			}
+			nextFieldID++
+			return nextFieldID
+		}
 
-			if len(set) == 1 {
+		seen := make(map[int]bool)
+		for i := range fields {
+			field := fields[i]
+			if field.Index == nil || field.Index.BadNode {
 				continue
 			}
-			for _, field := range set {
-				// field id conflict
+			fieldID := field.Index.Value
+			outOfRange := fieldID < 1 || fieldID > 32767
+			if outOfRange {
+				// field ID exceeded
 				ret = append(ret, protocol.Diagnostic{
 					Range:    lsputils.ASTNodeToRange(field.Index),
 					Severity: protocol.DiagnosticSeverityError,
 					Source:   "thrift-ls",
-					Message:  fmt.Sprintf("field id conflict"),
+					Message:  fmt.Sprintf("field id should be a positive integer in [1, 32767]"),
+					Data:     c.data(DataKindFieldIDOutOfRange, freeFieldID()),
 				})
 			}
+
+			if fieldIDCount[fieldID] == 1 {
+				continue
+			}
+			// the first field keeps its id for wire compatibility. field out of range is fixed above
+			newFieldID := 0
+			if seen[fieldID] && !outOfRange {
+				newFieldID = freeFieldID()
+			}
+			seen[fieldID] = true
+			// field id conflict
+			ret = append(ret, protocol.Diagnostic{
+				Range:    lsputils.ASTNodeToRange(field.Index),
+				Severity: protocol.DiagnosticSeverityError,
+				Source:   "thrift-ls",
+				Message:  fmt.Sprintf("field id conflict"),
+				Data:     c.data(DataKindFieldIDConflict, newFieldID),
+			})
 		}
 	}
 
@@ -115,3 +141,26 @@ func (c *FieldIDCheck) diagnostic(ctx context.Context, ss *cache.Snapshot, file
 
 	return ret, nil
 }
+
+// data returns diagnostic data with the field id suggested by quick fix. no quick fix is suggested if
+// fieldID is 0
+func (c *FieldIDCheck) data(kind string, fieldID int) *DiagnosticData {
+	return &DiagnosticData{
+		Checker: c.Name(),
+		Kind:    kind,
+		FieldID: fieldID,
+	}
+}
+
+func (c *FieldIDCheck) CodeActions(ctx context.Context, ss *cache.Snapshot, file uri.URI, diagnostic protocol.Diagnostic, data *DiagnosticData) ([]protocol.CodeAction, error) {
+	if data.FieldID == 0 {
+		return nil, nil
+	}
+
+	return []protocol.CodeAction{
+		quickFix(fmt.Sprintf("Change field id to %d", data.FieldID), file, diagnostic, protocol.TextEdit{
+			Range:   diagnostic.Range,
+			NewText: strconv.Itoa(data.FieldID),
+		}),
+	}, nil
+}
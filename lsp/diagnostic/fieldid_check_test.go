@@ -86,6 +86,10 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id conflict",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDConflict,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -101,6 +105,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id conflict",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDConflict,
+							FieldID: 2,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -116,6 +125,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id should be a positive integer in [1, 32767]",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDOutOfRange,
+							FieldID: 3,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -131,6 +145,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id should be a positive integer in [1, 32767]",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDOutOfRange,
+							FieldID: 4,
+						},
 					},
 
 					// union
@@ -148,6 +167,10 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id conflict",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDConflict,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -163,6 +186,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id conflict",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDConflict,
+							FieldID: 2,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -178,6 +206,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id should be a positive integer in [1, 32767]",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDOutOfRange,
+							FieldID: 3,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -193,6 +226,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id should be a positive integer in [1, 32767]",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDOutOfRange,
+							FieldID: 4,
+						},
 					},
 
 					// exception
@@ -210,6 +248,10 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id conflict",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDConflict,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -225,6 +267,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id conflict",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDConflict,
+							FieldID: 2,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -240,6 +287,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id should be a positive integer in [1, 32767]",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDOutOfRange,
+							FieldID: 3,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -255,6 +307,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id should be a positive integer in [1, 32767]",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDOutOfRange,
+							FieldID: 4,
+						},
 					},
 
 					// function params
@@ -272,6 +329,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id should be a positive integer in [1, 32767]",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDOutOfRange,
+							FieldID: 2,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -287,6 +349,10 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id conflict",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDConflict,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -302,6 +368,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id conflict",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDConflict,
+							FieldID: 3,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -317,6 +388,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id should be a positive integer in [1, 32767]",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDOutOfRange,
+							FieldID: 4,
+						},
 					},
 
 					// function throws
@@ -334,6 +410,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id should be a positive integer in [1, 32767]",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDOutOfRange,
+							FieldID: 2,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -349,6 +430,10 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id conflict",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDConflict,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -364,6 +449,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id conflict",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDConflict,
+							FieldID: 3,
+						},
 					},
 					{
 						Range: protocol.Range{
@@ -379,6 +469,11 @@ service Demo {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "field id should be a positive integer in [1, 32767]",
+						Data: &DiagnosticData{
+							Checker: "FieldIDCheck",
+							Kind:    DataKindFieldIDOutOfRange,
+							FieldID: 4,
+						},
 					},
 				},
 			},
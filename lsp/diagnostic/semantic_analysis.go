@@ -59,6 +59,13 @@ func (s *SemanticAnalysis) checkDefineConflict(ctx context.Context, pf *cache.Pa
 	var ret []protocol.Diagnostic
 
 	processStructLike := func(fields []*parser.Field) {
+		fieldNames := make(map[string]struct{})
+		for i := range fields {
+			if fields[i].IsBadNode() || fields[i].ChildrenBadNode() {
+				continue
+			}
+			fieldNames[fields[i].Identifier.Name.Text] = struct{}{}
+		}
 		fieldMap := make(map[string]struct{})
 		for i := range fields {
 			field := fields[i]
@@ -72,12 +79,14 @@ func (s *SemanticAnalysis) checkDefineConflict(ctx context.Context, pf *cache.Pa
 					Severity: protocol.DiagnosticSeverityError,
 					Source:   "thrift-ls",
 					Message:  fmt.Sprintf("field name conflict with other field"),
+					Data:     s.renameData(field.Identifier.Name.Text, fieldNames),
 				})
 			}
 			fieldMap[field.Identifier.Name.Text] = struct{}{}
 		}
 	}
 
+	names := definitionNames(pf.AST())
 	definitionNameMap := make(map[string]string)
 
 	structMap := make(map[string]struct{})
@@ -93,6 +102,7 @@ func (s *SemanticAnalysis) checkDefineConflict(ctx context.Context, pf *cache.Pa
 				Severity: protocol.DiagnosticSeverityError,
 				Source:   "thrift-ls",
 				Message:  fmt.Sprintf("struct name conflict with other struct"),
+				Data:     s.renameData(st.Identifier.Name.Text, names),
 			})
 		}
 
@@ -103,6 +113,7 @@ func (s *SemanticAnalysis) checkDefineConflict(ctx context.Context, pf *cache.Pa
 				Severity: protocol.DiagnosticSeverityHint,
 				Source:   "thrift-ls",
 				Message:  fmt.Sprintf("struct name conflict with other type"),
+				Data:     s.renameData(st.Identifier.Name.Text, names),
 			})
 		}
 
@@ -125,6 +136,7 @@ func (s *SemanticAnalysis) checkDefineConflict(ctx context.Context, pf *cache.Pa
 				Severity: protocol.DiagnosticSeverityError,
 				Source:   "thrift-ls",
 				Message:  fmt.Sprintf("union name conflict with other union"),
+				Data:     s.renameData(union.Name.Name.Text, names),
 			})
 		}
 
@@ -135,6 +147,7 @@ func (s *SemanticAnalysis) checkDefineConflict(ctx context.Context, pf *cache.Pa
 				Severity: protocol.DiagnosticSeverityHint,
 				Source:   "thrift-ls",
 				Message:  fmt.Sprintf("union name conflict with other type"),
+				Data:     s.renameData(union.Name.Name.Text, names),
 			})
 		}
 
@@ -157,6 +170,7 @@ func (s *SemanticAnalysis) checkDefineConflict(ctx context.Context, pf *cache.Pa
 				Severity: protocol.DiagnosticSeverityError,
 				Source:   "thrift-ls",
 				Message:  fmt.Sprintf("exception name conflict with other exception"),
+				Data:     s.renameData(excep.Name.Name.Text, names),
 			})
 		}
 
@@ -167,6 +181,7 @@ func (s *SemanticAnalysis) checkDefineConflict(ctx context.Context, pf *cache.Pa
 				Severity: protocol.DiagnosticSeverityHint,
 				Source:   "thrift-ls",
 				Message:  fmt.Sprintf("exception name conflict with other type"),
+				Data:     s.renameData(excep.Name.Name.Text, names),
 			})
 		}
 
@@ -189,6 +204,7 @@ func (s *SemanticAnalysis) checkDefineConflict(ctx context.Context, pf *cache.Pa
 				Severity: protocol.DiagnosticSeverityError,
 				Source:   "thrift-ls",
 				Message:  fmt.Sprintf("service name conflict with other service"),
+				Data:     s.renameData(svc.Name.Name.Text, names),
 			})
 		}
 
@@ -199,12 +215,20 @@ func (s *SemanticAnalysis) checkDefineConflict(ctx context.Context, pf *cache.Pa
 				Severity: protocol.DiagnosticSeverityHint,
 				Source:   "thrift-ls",
 				Message:  fmt.Sprintf("service name conflict with other type"),
+				Data:     s.renameData(svc.Name.Name.Text, names),
 			})
 		}
 
 		svcMap[svc.Name.Name.Text] = struct{}{}
 		definitionNameMap[svc.Name.Name.Text] = svc.Type()
 
+		fnNames := make(map[string]struct{})
+		for _, fn := range svc.Functions {
+			if fn.IsBadNode() || fn.ChildrenBadNode() {
+				continue
+			}
+			fnNames[fn.Name.Name.Text] = struct{}{}
+		}
 		fnMap := make(map[string]struct{})
 		for _, fn := range svc.Functions {
 			if fn.IsBadNode() || svc.ChildrenBadNode() {
@@ -217,6 +241,7 @@ func (s *SemanticAnalysis) checkDefineConflict(ctx context.Context, pf *cache.Pa
 					Severity: protocol.DiagnosticSeverityWarning,
 					Source:   "thrift-ls",
 					Message:  fmt.Sprintf("function name conflict with other function"),
+					Data:     s.renameData(fn.Name.Name.Text, fnNames),
 				})
 			}
 			fnMap[fn.Name.Name.Text] = struct{}{}
@@ -230,6 +255,71 @@ func (s *SemanticAnalysis) checkDefineConflict(ctx context.Context, pf *cache.Pa
 	return ret
 }
 
+// definitionNames returns names of all definitions in document
+func definitionNames(doc *parser.Document) map[string]struct{} {
+	names := make(map[string]struct{})
+	add := func(id *parser.Identifier) {
+		if id == nil || id.BadNode || id.Name == nil {
+			return
+		}
+		names[id.Name.Text] = struct{}{}
+	}
+	for _, st := range doc.Structs {
+		add(st.Identifier)
+	}
+	for _, union := range doc.Unions {
+		add(union.Name)
+	}
+	for _, excep := range doc.Exceptions {
+		add(excep.Name)
+	}
+	for _, svc := range doc.Services {
+		add(svc.Name)
+	}
+	for _, enum := range doc.Enums {
+		add(enum.Name)
+	}
+	for _, td := range doc.Typedefs {
+		add(td.Alias)
+	}
+	for _, cst := range doc.Consts {
+		add(cst.Name)
+	}
+
+	return names
+}
+
+// renameData returns diagnostic data with a new name which doesn't conflict with names
+func (s *SemanticAnalysis) renameData(name string, names map[string]struct{}) *DiagnosticData {
+	newName := name
+	for i := 2; ; i++ {
+		newName = fmt.Sprintf("%s%d", name, i)
+		if _, exist := names[newName]; !exist {
+			break
+		}
+	}
+	names[newName] = struct{}{}
+
+	return &DiagnosticData{
+		Checker: s.Name(),
+		Kind:    DataKindNameConflict,
+		NewName: newName,
+	}
+}
+
+func (s *SemanticAnalysis) CodeActions(ctx context.Context, ss *cache.Snapshot, file uri.URI, diagnostic protocol.Diagnostic, data *DiagnosticData) ([]protocol.CodeAction, error) {
+	if data.Kind != DataKindNameConflict || data.NewName == "" {
+		return nil, nil
+	}
+
+	return []protocol.CodeAction{
+		quickFix(fmt.Sprintf("Rename to %s", data.NewName), file, diagnostic, protocol.TextEdit{
+			Range:   diagnostic.Range,
+			NewText: data.NewName,
+		}),
+	}, nil
+}
+
 // same as goto definition
 // struct/union/exception field type
 func (s *SemanticAnalysis) checkDefinitionExist(ctx context.Context, ss *cache.Snapshot, file uri.URI, pf *cache.ParsedFile) []protocol.Diagnostic {
@@ -379,7 +469,7 @@ func (s *SemanticAnalysis) checkTypeExist(ctx context.Context, ss *cache.Snapsho
 	file uri.URI, pf *cache.ParsedFile, ft *parser.FieldType) (res []protocol.Diagnostic) {
 	if codejump.IsContainerType(ft.TypeName.Name) {
 		return s.checkContainerTypeExist(ctx, ss, file, pf, ft)
-	} else if codejump.IsBasicType(ft.TypeName.Name) {
+	} else if isBasicType(ss, ft.TypeName.Name) {
 		return nil
 	} else {
 		_, id, _, err := codejump.TypeNameDefinitionIdentifier(ctx, ss, file, pf.AST(), ft.TypeName)
@@ -396,6 +486,12 @@ func (s *SemanticAnalysis) checkTypeExist(ctx context.Context, ss *cache.Snapsho
 	return res
 }
 
+// isBasicType reports whether name is a basic type in dialect of snapshot. uuid is a basic type of Apache
+// Thrift since 0.19
+func isBasicType(ss *cache.Snapshot, name string) bool {
+	return codejump.IsBasicType(name) || (name == "uuid" && ss.Dialect() == cache.DialectApache)
+}
+
 func (s *SemanticAnalysis) checkContainerTypeExist(ctx context.Context,
 	ss *cache.Snapshot, file uri.URI, pf *cache.ParsedFile, ft *parser.FieldType) (res []protocol.Diagnostic) {
 
@@ -108,6 +108,11 @@ struct TestContainer {
 						Severity: protocol.DiagnosticSeverityError,
 						Source:   "thrift-ls",
 						Message:  "struct name conflict with other struct",
+						Data: &DiagnosticData{
+							Checker: "SemanticAnalysis",
+							Kind:    DataKindNameConflict,
+							NewName: "Student2",
+						},
 					},
 					{
 						Range: protocol.Range{
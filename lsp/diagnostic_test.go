@@ -0,0 +1,133 @@
+package lsp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/memoize"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func Test_DiagnosticDependents(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.thrift": "include \"b.thrift\"\n\nstruct A {\n    1: b.B b\n}\n",
+		"b.thrift": "include \"c.thrift\"\n\nstruct B {\n    1: c.C c\n}\n",
+		"c.thrift": "struct C {\n    1: string id\n}\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	aURI, bURI, cURI := uri.File(filepath.Join(dir, "a.thrift")), uri.File(filepath.Join(dir, "b.thrift")),
+		uri.File(filepath.Join(dir, "c.thrift"))
+
+	client := &recordClient{}
+	srv := NewServer(cache.New(&memoize.Store{}), client)
+	srv.dependentDiagnostics.delay = 50 * time.Millisecond
+	_, err := srv.Initialize(ctx, &protocol.InitializeParams{RootURI: uri.File(dir)})
+	assert.NoError(t, err)
+	client.takeDiagnostics()
+	client.published = nil
+
+	err = srv.DidOpen(ctx, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{
+			URI:        cURI,
+			LanguageID: "thrift",
+			Text:       files["c.thrift"],
+		},
+	})
+	assert.NoError(t, err)
+	// a burst of keystrokes renames struct C to C2
+	for i, text := range []string{"struct  {\n", "struct C {\n", "struct C2 {\n"} {
+		err = srv.DidChange(ctx, &protocol.DidChangeTextDocumentParams{
+			TextDocument: protocol.VersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: cURI},
+				Version:                int32(i + 1),
+			},
+			ContentChanges: []protocol.TextDocumentContentChangeEvent{
+				{
+					Range: protocol.Range{
+						Start: protocol.Position{Line: 0, Character: 0},
+						End:   protocol.Position{Line: 1, Character: 0},
+					},
+					Text: text,
+				},
+			},
+		})
+		assert.NoError(t, err)
+	}
+
+	// files including c.thrift directly or indirectly are diagnosed once
+	diagnostics := client.waitDiagnostics(t, aURI)
+	time.Sleep(100 * time.Millisecond)
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	assert.Equal(t, map[uri.URI]int{aURI: 1, bURI: 1, cURI: 4}, client.published)
+	assert.Empty(t, diagnostics[aURI])
+	if assert.Len(t, diagnostics[bURI], 1) {
+		assert.Equal(t, "field type doesn't exist", diagnostics[bURI][0].Message)
+	}
+}
+
+func Test_DiagnosticWorkspace(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.thrift":         "include \"b.thrift\"\n\nstruct A {\n    1: b.B b\n}\n",
+		"b.thrift":         "struct B {\n    1: string id\n}\n",
+		"broken.thrift":    "struct Broken {\n    1: string\n}\n",
+		"sub/user.thrift":  "include \"../b.thrift\"\n\nstruct User {\n    1: b.Unknown b\n}\n",
+		"sub/empty.thrift": "",
+	}
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	client := &recordClient{}
+	srv := NewServer(cache.New(&memoize.Store{}), client)
+	_, err := srv.Initialize(ctx, &protocol.InitializeParams{
+		RootURI: uri.File(dir),
+		Capabilities: protocol.ClientCapabilities{
+			Window: &protocol.WindowClientCapabilities{WorkDoneProgress: true},
+		},
+	})
+	assert.NoError(t, err)
+	// files are diagnosed after all of them are indexed
+	assert.Empty(t, client.takeDiagnostics())
+
+	assert.NoError(t, srv.Initialized(ctx, &protocol.InitializedParams{}))
+	srv.background.Wait()
+
+	diagnostics := client.takeDiagnostics()
+	assert.Len(t, diagnostics, len(files))
+	for name := range files {
+		assert.Contains(t, diagnostics, uri.File(filepath.Join(dir, name)))
+	}
+	assert.Empty(t, diagnostics[uri.File(filepath.Join(dir, "a.thrift"))])
+	assert.NotEmpty(t, diagnostics[uri.File(filepath.Join(dir, "broken.thrift"))])
+	userDiagnostics := diagnostics[uri.File(filepath.Join(dir, "sub/user.thrift"))]
+	if assert.Len(t, userDiagnostics, 1) {
+		assert.Equal(t, "field type doesn't exist", userDiagnostics[0].Message)
+	}
+
+	// progress begins, reports percentage of diagnosed files and ends
+	if assert.Len(t, client.progress, len(files)+2) {
+		begin := client.progress[0].(*protocol.WorkDoneProgressBegin)
+		assert.Equal(t, protocol.WorkDoneProgressKindBegin, begin.Kind)
+		for i, value := range client.progress[1 : len(files)+1] {
+			report := value.(*protocol.WorkDoneProgressReport)
+			assert.Equal(t, protocol.WorkDoneProgressKindReport, report.Kind)
+			assert.Equal(t, uint32((i+1)*100/len(files)), report.Percentage)
+		}
+		end := client.progress[len(files)+1].(*protocol.WorkDoneProgressEnd)
+		assert.Equal(t, protocol.WorkDoneProgressKindEnd, end.Kind)
+	}
+}
@@ -0,0 +1,20 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/joyme123/thrift-ls/lsp/documentlink"
+	"go.lsp.dev/protocol"
+)
+
+func (s *Server) documentLink(ctx context.Context, params *protocol.DocumentLinkParams) ([]protocol.DocumentLink, error) {
+	file := params.TextDocument.URI
+	view, err := s.session.ViewOf(file)
+	if err != nil {
+		return nil, err
+	}
+	ss, release := view.Snapshot()
+	defer release()
+
+	return documentlink.DocumentLinks(ctx, ss, file)
+}
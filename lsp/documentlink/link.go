@@ -0,0 +1,128 @@
+package documentlink
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/lsputils"
+	"github.com/joyme123/thrift-ls/parser"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+var urlRegexp = regexp.MustCompile("(https?|ftp)://[^\\s<>\"'`]+")
+
+// DocumentLinks returns links of include and cpp_include paths, and urls in comments
+func DocumentLinks(ctx context.Context, ss *cache.Snapshot, file uri.URI) ([]protocol.DocumentLink, error) {
+	pf, err := ss.Parse(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	if pf.AST() == nil {
+		return nil, errors.New("parse ast failed")
+	}
+
+	res := make([]protocol.DocumentLink, 0)
+	for _, node := range pf.AST().Nodes {
+		if node.IsBadNode() {
+			continue
+		}
+		var path *parser.Literal
+		switch node.Type() {
+		case "Include":
+			path = node.(*parser.Include).Path
+		case "CPPInclude":
+			path = node.(*parser.CPPInclude).Path
+		default:
+			continue
+		}
+
+		if link, ok := includeLink(ctx, ss, file, path); ok {
+			res = append(res, link)
+		}
+	}
+
+	for _, comment := range lsputils.Comments(pf.AST()) {
+		res = append(res, commentLinks(comment)...)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Range.Start.Line != res[j].Range.Start.Line {
+			return res[i].Range.Start.Line < res[j].Range.Start.Line
+		}
+		return res[i].Range.Start.Character < res[j].Range.Start.Character
+	})
+
+	return res, nil
+}
+
+// includeLink returns link of include path. path which can't be resolved has no link
+func includeLink(ctx context.Context, ss *cache.Snapshot, file uri.URI, path *parser.Literal) (protocol.DocumentLink, bool) {
+	if path == nil || path.BadNode || path.Value == nil || path.Value.Text == "" {
+		return protocol.DocumentLink{}, false
+	}
+
+	target := lsputils.IncludeURI(file, path.Value.Text, ss.IncludeDirs()...)
+	fh, err := ss.ReadFile(ctx, target)
+	if err != nil {
+		return protocol.DocumentLink{}, false
+	}
+	if _, err := fh.Content(); err != nil {
+		return protocol.DocumentLink{}, false
+	}
+
+	return protocol.DocumentLink{
+		Range:  lsputils.ASTNodeToRange(path.Value),
+		Target: protocol.DocumentURI(target),
+	}, true
+}
+
+// commentLinks returns links of urls in comment. comment may contain multiple lines
+func commentLinks(comment *parser.Comment) []protocol.DocumentLink {
+	var res []protocol.DocumentLink
+	for _, match := range urlRegexp.FindAllStringIndex(comment.Text, -1) {
+		start, end := match[0], match[1]
+		link := comment.Text[start:end]
+		if comment.Style == parser.CommentStyleMultiLine {
+			link = strings.TrimSuffix(link, "*/")
+		}
+		link = strings.TrimRight(link, ".,;:!?)]}")
+		if link == "" {
+			continue
+		}
+		end = start + len(link)
+
+		res = append(res, protocol.DocumentLink{
+			Range: protocol.Range{
+				Start: textPosition(comment, start),
+				End:   textPosition(comment, end),
+			},
+			Target: protocol.DocumentURI(link),
+		})
+	}
+
+	return res
+}
+
+// textPosition converts byte offset in comment text to lsp position
+func textPosition(comment *parser.Comment, offset int) protocol.Position {
+	text := comment.Text[:offset]
+	line := comment.Pos().Line - 1
+	col := comment.Pos().Col - 1
+	if index := strings.LastIndexByte(text, '\n'); index != -1 {
+		line += strings.Count(text, "\n")
+		col = 0
+		text = text[index+1:]
+	}
+
+	return protocol.Position{
+		Line:      uint32(line),
+		Character: uint32(col + utf8.RuneCountInString(text)),
+	}
+}
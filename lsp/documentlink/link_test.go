@@ -0,0 +1,74 @@
+package documentlink
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestDocumentLinks(t *testing.T) {
+	file := `include "base.thrift"
+include "not_exist.thrift"
+
+// see https://thrift.apache.org/docs/idl.
+/*
+ * 文档: http://example.com/a?b=c */
+struct Test {
+  1: required string name, # https://example.com/name
+}`
+
+	ss := cache.BuildSnapshotForTest([]*cache.FileChange{
+		{
+			URI:     "file:///tmp/test.thrift",
+			Version: 0,
+			Content: []byte(file),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+		{
+			URI:     "file:///tmp/base.thrift",
+			Version: 0,
+			Content: []byte(`struct Base {}`),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	})
+
+	link := func(startLine, startChar, endLine, endChar uint32, target string) protocol.DocumentLink {
+		return protocol.DocumentLink{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: startLine, Character: startChar},
+				End:   protocol.Position{Line: endLine, Character: endChar},
+			},
+			Target: protocol.DocumentURI(target),
+		}
+	}
+
+	tests := []struct {
+		name      string
+		file      uri.URI
+		want      []protocol.DocumentLink
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name: "include and comment links",
+			file: "file:///tmp/test.thrift",
+			want: []protocol.DocumentLink{
+				link(0, 9, 0, 20, "file:///tmp/base.thrift"),
+				link(3, 7, 3, 41, "https://thrift.apache.org/docs/idl"),
+				link(5, 7, 5, 31, "http://example.com/a?b=c"),
+				link(7, 29, 7, 53, "https://example.com/name"),
+			},
+			assertion: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DocumentLinks(context.TODO(), ss, tt.file)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/joyme123/thrift-ls/lsp/folding"
+	"go.lsp.dev/protocol"
+)
+
+func (s *Server) foldingRanges(ctx context.Context, params *protocol.FoldingRangeParams) ([]protocol.FoldingRange, error) {
+	file := params.TextDocument.URI
+	view, err := s.session.ViewOf(file)
+	if err != nil {
+		return nil, err
+	}
+	ss, release := view.Snapshot()
+	defer release()
+
+	return folding.FoldingRanges(ctx, ss, file)
+}
@@ -0,0 +1,303 @@
+package folding
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/lsputils"
+	"github.com/joyme123/thrift-ls/parser"
+	"github.com/joyme123/thrift-ls/utils"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// FoldingRanges returns folding ranges of definition blocks, multi-line const values and annotations,
+// comments and header section
+func FoldingRanges(ctx context.Context, ss *cache.Snapshot, file uri.URI) ([]protocol.FoldingRange, error) {
+	pf, err := ss.Parse(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	if pf.AST() == nil {
+		return nil, errors.New("parse ast failed")
+	}
+
+	fh, err := ss.ReadFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	content, err := fh.Content()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := pf.AST()
+	res := make([]protocol.FoldingRange, 0)
+	res = append(res, headerRanges(doc)...)
+
+	for _, node := range doc.Nodes {
+		if node.IsBadNode() {
+			continue
+		}
+		switch node.Type() {
+		case "Struct":
+			st := node.(*parser.Struct)
+			res = append(res, blockRange(st.LCurKeyword, st.RCurKeyword)...)
+			res = append(res, fieldsRanges(st.Fields)...)
+			res = append(res, annotationsRange(st.Annotations)...)
+		case "Union":
+			union := node.(*parser.Union)
+			res = append(res, blockRange(union.LCurKeyword, union.RCurKeyword)...)
+			res = append(res, fieldsRanges(union.Fields)...)
+			res = append(res, annotationsRange(union.Annotations)...)
+		case "Exception":
+			excep := node.(*parser.Exception)
+			res = append(res, blockRange(excep.LCurKeyword, excep.RCurKeyword)...)
+			res = append(res, fieldsRanges(excep.Fields)...)
+			res = append(res, annotationsRange(excep.Annotations)...)
+		case "Enum":
+			enum := node.(*parser.Enum)
+			res = append(res, blockRange(enum.LCurKeyword, enum.RCurKeyword)...)
+			for _, value := range enum.Values {
+				if value.BadNode {
+					continue
+				}
+				res = append(res, annotationsRange(value.Annotations)...)
+			}
+			res = append(res, annotationsRange(enum.Annotations)...)
+		case "Service":
+			svc := node.(*parser.Service)
+			res = append(res, blockRange(svc.LCurKeyword, svc.RCurKeyword)...)
+			for _, fn := range svc.Functions {
+				res = append(res, functionRanges(fn)...)
+			}
+			res = append(res, annotationsRange(svc.Annotations)...)
+		case "Const":
+			cst := node.(*parser.Const)
+			res = append(res, constValueRanges(cst.Value)...)
+			res = append(res, annotationsRange(cst.Annotations)...)
+		case "Typedef":
+			td := node.(*parser.Typedef)
+			res = append(res, annotationsRange(td.Annotations)...)
+		case "Namespace":
+			ns := node.(*parser.Namespace)
+			res = append(res, annotationsRange(ns.Annotations)...)
+		}
+	}
+
+	res = append(res, commentRanges(content, lsputils.Comments(doc))...)
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].StartLine != res[j].StartLine {
+			return res[i].StartLine < res[j].StartLine
+		}
+		return res[i].EndLine > res[j].EndLine
+	})
+
+	return res, nil
+}
+
+// headerRanges folds all includes, cpp_includes and namespaces
+func headerRanges(doc *parser.Document) []protocol.FoldingRange {
+	start, end := -1, -1
+	for _, node := range doc.Nodes {
+		var keyword parser.Node
+		switch node.Type() {
+		case "Include":
+			keyword = node.(*parser.Include).IncludeKeyword
+		case "CPPInclude":
+			keyword = node.(*parser.CPPInclude).CPPIncludeKeyword
+		case "Namespace":
+			keyword = node.(*parser.Namespace).NamespaceKeyword
+		default:
+			continue
+		}
+		if node.IsBadNode() || utils.IsNil(keyword) {
+			continue
+		}
+
+		line := keywordLine(keyword)
+		if start == -1 || line < start {
+			start = line
+		}
+		if node.End().Line > end {
+			end = node.End().Line
+		}
+	}
+
+	return lineRange(start, end, protocol.ImportsFoldingRange)
+}
+
+// blockRange folds content between '{' and '}'. line of '}' is kept
+func blockRange(lcur *parser.LCurKeyword, rcur *parser.RCurKeyword) []protocol.FoldingRange {
+	if lcur == nil || rcur == nil || lcur.BadNode || rcur.BadNode {
+		return nil
+	}
+
+	return lineRange(keywordLine(lcur), keywordLine(rcur)-1, protocol.RegionFoldingRange)
+}
+
+func functionRanges(fn *parser.Function) []protocol.FoldingRange {
+	if fn.BadNode || fn.LParKeyword == nil {
+		return nil
+	}
+
+	var res []protocol.FoldingRange
+	end := fn.RParKeyword
+	if fn.Throws != nil && !fn.Throws.BadNode && fn.Throws.RParKeyword != nil {
+		end = fn.Throws.RParKeyword
+	}
+	if end != nil {
+		// arguments are usually written in the line of ')', so it is folded too
+		start := keywordLine(fn.LParKeyword)
+		if fn.Name != nil && !fn.Name.BadNode && fn.Name.Name != nil {
+			start = fn.Name.Name.Pos().Line
+		}
+		res = append(res, lineRange(start, keywordLine(end), protocol.RegionFoldingRange)...)
+	}
+
+	res = append(res, fieldsRanges(fn.Arguments)...)
+	if fn.Throws != nil {
+		res = append(res, fieldsRanges(fn.Throws.Fields)...)
+	}
+	res = append(res, annotationsRange(fn.Annotations)...)
+
+	return res
+}
+
+func fieldsRanges(fields []*parser.Field) []protocol.FoldingRange {
+	var res []protocol.FoldingRange
+	for _, field := range fields {
+		if field.BadNode {
+			continue
+		}
+		res = append(res, constValueRanges(field.ConstValue)...)
+		res = append(res, annotationsRange(field.Annotations)...)
+	}
+
+	return res
+}
+
+// constValueRanges folds multi-line map and list, including nested ones
+func constValueRanges(cv *parser.ConstValue) []protocol.FoldingRange {
+	if cv == nil || cv.BadNode {
+		return nil
+	}
+
+	var res []protocol.FoldingRange
+	switch cv.TypeName {
+	case "list":
+		if cv.LBrkKeyword != nil && cv.RBrkKeyword != nil {
+			res = append(res, lineRange(keywordLine(cv.LBrkKeyword), keywordLine(cv.RBrkKeyword)-1, protocol.RegionFoldingRange)...)
+		}
+		if values, ok := cv.Value.([]*parser.ConstValue); ok {
+			for i := range values {
+				res = append(res, constValueRanges(values[i])...)
+			}
+		}
+	case "map":
+		if cv.LCurKeyword != nil && cv.RCurKeyword != nil {
+			res = append(res, lineRange(keywordLine(cv.LCurKeyword), keywordLine(cv.RCurKeyword)-1, protocol.RegionFoldingRange)...)
+		}
+		if values, ok := cv.Value.([]*parser.ConstValue); ok {
+			for i := range values {
+				res = append(res, constValueRanges(values[i])...)
+			}
+		}
+	case "pair":
+		if key, ok := cv.Key.(*parser.ConstValue); ok {
+			res = append(res, constValueRanges(key)...)
+		}
+		if value, ok := cv.Value.(*parser.ConstValue); ok {
+			res = append(res, constValueRanges(value)...)
+		}
+	}
+
+	return res
+}
+
+func annotationsRange(annos *parser.Annotations) []protocol.FoldingRange {
+	if annos == nil || annos.BadNode || annos.LParKeyword == nil || annos.RParKeyword == nil {
+		return nil
+	}
+
+	return lineRange(keywordLine(annos.LParKeyword), keywordLine(annos.RParKeyword)-1, protocol.RegionFoldingRange)
+}
+
+// commentRanges folds multi-line comments and runs of consecutive single line comments.
+// single line comments which follow code are not part of a run
+func commentRanges(content []byte, comments []*parser.Comment) []protocol.FoldingRange {
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].Pos().Offset < comments[j].Pos().Offset
+	})
+
+	var res []protocol.FoldingRange
+	runStart, runEnd := -1, -1
+	flush := func() {
+		res = append(res, lineRange(runStart, runEnd, protocol.CommentFoldingRange)...)
+		runStart, runEnd = -1, -1
+	}
+
+	for _, comment := range comments {
+		if comment.Style == parser.CommentStyleMultiLine {
+			flush()
+			res = append(res, lineRange(comment.Pos().Line, comment.End().Line, protocol.CommentFoldingRange)...)
+			continue
+		}
+
+		if !startOfLine(content, comment.Pos().Offset) {
+			flush()
+			continue
+		}
+
+		line := comment.Pos().Line
+		if runEnd != -1 && line == runEnd+1 {
+			runEnd = line
+			continue
+		}
+		flush()
+		runStart, runEnd = line, line
+	}
+	flush()
+
+	return res
+}
+
+func startOfLine(content []byte, offset int) bool {
+	if offset < 0 || offset > len(content) {
+		return false
+	}
+	lineStart := bytes.LastIndexByte(content[:offset], '\n') + 1
+	return strings.TrimSpace(string(content[lineStart:offset])) == ""
+}
+
+func keywordLine(node parser.Node) int {
+	if kn, ok := node.(interface{ GetKeyword() *parser.Keyword }); ok {
+		kw := kn.GetKeyword()
+		if kw.Literal != nil {
+			return kw.Literal.Pos().Line
+		}
+	}
+	return node.Pos().Line
+}
+
+// lineRange converts 1-based start and end line to folding range. range isn't returned if it only
+// contains one line
+func lineRange(start, end int, kind protocol.FoldingRangeKind) []protocol.FoldingRange {
+	if start < 1 || end <= start {
+		return nil
+	}
+
+	return []protocol.FoldingRange{
+		{
+			StartLine: uint32(start - 1),
+			EndLine:   uint32(end - 1),
+			Kind:      kind,
+		},
+	}
+}
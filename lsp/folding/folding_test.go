@@ -0,0 +1,93 @@
+package folding
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestFoldingRanges(t *testing.T) {
+	file := `include "base.thrift"
+include "user.thrift"
+namespace go api
+
+// line comment 1
+// line comment 2
+struct Test {
+  1: required string name, // end line comment
+  2: required string email, // end line comment
+  3: list<string> tags = [
+    "a",
+    "b",
+  ]
+} (
+  a = "1",
+  b = "2",
+)
+
+/*
+ * block comment
+ */
+const map<string, i32> M = {
+  "a": 1,
+  "b": 2,
+}
+
+service Demo {
+  void Api(1: string a,
+    2: string b)
+  void Api2()
+}
+
+enum Status {
+  OK = 1,
+  // removed values
+  // are reserved
+}`
+
+	ss := cache.BuildSnapshotForTest([]*cache.FileChange{
+		{
+			URI:     "file:///tmp/test.thrift",
+			Version: 0,
+			Content: []byte(file),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	})
+
+	tests := []struct {
+		name      string
+		file      uri.URI
+		want      []protocol.FoldingRange
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name: "folding ranges",
+			file: "file:///tmp/test.thrift",
+			want: []protocol.FoldingRange{
+				{StartLine: 0, EndLine: 2, Kind: protocol.ImportsFoldingRange},
+				{StartLine: 4, EndLine: 5, Kind: protocol.CommentFoldingRange},
+				{StartLine: 6, EndLine: 12, Kind: protocol.RegionFoldingRange},
+				{StartLine: 9, EndLine: 11, Kind: protocol.RegionFoldingRange},
+				{StartLine: 13, EndLine: 15, Kind: protocol.RegionFoldingRange},
+				{StartLine: 18, EndLine: 20, Kind: protocol.CommentFoldingRange},
+				{StartLine: 21, EndLine: 23, Kind: protocol.RegionFoldingRange},
+				{StartLine: 26, EndLine: 29, Kind: protocol.RegionFoldingRange},
+				{StartLine: 27, EndLine: 28, Kind: protocol.RegionFoldingRange},
+				{StartLine: 32, EndLine: 35, Kind: protocol.RegionFoldingRange},
+				{StartLine: 34, EndLine: 35, Kind: protocol.CommentFoldingRange},
+			},
+			assertion: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FoldingRanges(context.TODO(), ss, tt.file)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -5,13 +5,11 @@ import (
 
 	"github.com/joyme123/thrift-ls/format"
 	"github.com/joyme123/thrift-ls/lsp/mapper"
+	"github.com/joyme123/thrift-ls/lsp/types"
 	"go.lsp.dev/protocol"
 )
 
 func (s *Server) formatting(ctx context.Context, params *protocol.DocumentFormattingParams) (result []protocol.TextEdit, err error) {
-
-	// TODO: 支持 format options
-
 	document := params.TextDocument
 	fileURI := document.URI
 	view, err := s.session.ViewOf(fileURI)
@@ -36,33 +34,134 @@ func (s *Server) formatting(ctx context.Context, params *protocol.DocumentFormat
 	if err != nil {
 		return nil, err
 	}
-	if len(pf.Errors()) > 0 || pf.AST() == nil {
+	// nodes with syntax error are kept as they are, other nodes are still formatted
+	if pf.AST() == nil || (len(pf.Errors()) > 0 && !pf.AST().ChildrenBadNode()) {
 		return nil, pf.AggregatedError()
 	}
 
-	formatted, err := format.FormatDocument(pf.AST())
+	formatted, err := format.FormatDocumentWithOptions(pf.AST(), bytes, s.formatOptions(view, params.Options))
 	if err != nil {
 		return nil, err
 	}
 
-	mp := mapper.NewMapper(fileURI, bytes)
-	endPos := mp.GetLSPEndPosition()
-	textEdit := protocol.TextEdit{
-		Range: protocol.Range{
-			Start: protocol.Position{
-				Line:      0,
-				Character: 0,
-			},
-			End: protocol.Position{
-				Line:      endPos.Line,
-				Character: endPos.Character,
+	// only changed parts are replaced, so cursor and undo history of editor are kept
+	edits := format.DiffEdits(string(bytes), formatted)
+
+	return offsetEditsToTextEdits(mapper.NewMapper(fileURI, bytes), edits)
+}
+
+func (s *Server) rangeFormatting(ctx context.Context, params *protocol.DocumentRangeFormattingParams) (result []protocol.TextEdit, err error) {
+	fileURI := params.TextDocument.URI
+	view, err := s.session.ViewOf(fileURI)
+	if err != nil {
+		return nil, err
+	}
+
+	ss, release := view.Snapshot()
+	defer release()
+
+	fh, err := ss.ReadFile(ctx, fileURI)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := fh.Content()
+	if err != nil {
+		return nil, err
+	}
+
+	pf, err := ss.Parse(ctx, fileURI)
+	if err != nil {
+		return nil, err
+	}
+	if pf.AST() == nil {
+		return nil, pf.AggregatedError()
+	}
+
+	mp := mapper.NewMapper(fileURI, content)
+	start, err := mp.LSPPosToOffset(types.Position{Line: params.Range.Start.Line, Character: params.Range.Start.Character})
+	if err != nil {
+		return nil, err
+	}
+	end, err := mp.LSPPosToOffset(types.Position{Line: params.Range.End.Line, Character: params.Range.End.Character})
+	if err != nil {
+		return nil, err
+	}
+
+	// definitions with syntax error are skipped by range formatting
+	edits, err := format.FormatRange(pf.AST(), content, start, end, s.formatOptions(view, params.Options))
+	if err != nil {
+		return nil, err
+	}
+
+	return offsetEditsToTextEdits(mp, edits)
+}
+
+func offsetEditsToTextEdits(mp *mapper.Mapper, edits []format.TextEdit) ([]protocol.TextEdit, error) {
+	res := make([]protocol.TextEdit, 0, len(edits))
+	for _, edit := range edits {
+		start, err := mp.OffsetToLSPPosition(edit.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := mp.OffsetToLSPPosition(edit.End)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, protocol.TextEdit{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: start.Line, Character: start.Character},
+				End:   protocol.Position{Line: end.Line, Character: end.Character},
 			},
-		},
-		NewText: formatted,
+			NewText: edit.NewText,
+		})
+	}
+
+	return res, nil
+}
+
+func (s *Server) onTypeFormatting(ctx context.Context, params *protocol.DocumentOnTypeFormattingParams) (result []protocol.TextEdit, err error) {
+	if params.Ch != "}" {
+		return nil, nil
+	}
+
+	fileURI := params.TextDocument.URI
+	view, err := s.session.ViewOf(fileURI)
+	if err != nil {
+		return nil, err
+	}
+
+	ss, release := view.Snapshot()
+	defer release()
+
+	fh, err := ss.ReadFile(ctx, fileURI)
+	if err != nil {
+		return nil, err
 	}
 
-	result = append(result, textEdit)
+	content, err := fh.Content()
+	if err != nil {
+		return nil, err
+	}
 
-	return
+	pf, err := ss.Parse(ctx, fileURI)
+	if err != nil {
+		return nil, err
+	}
+	if pf.AST() == nil {
+		return nil, pf.AggregatedError()
+	}
+
+	mp := mapper.NewMapper(fileURI, content)
+	offset, err := mp.LSPPosToOffset(types.Position{Line: params.Position.Line, Character: params.Position.Character})
+	if err != nil {
+		return nil, err
+	}
+
+	edits, err := format.FormatOnTypeRCur(pf.AST(), content, offset, s.formatOptions(view, params.Options))
+	if err != nil {
+		return nil, err
+	}
 
+	return offsetEditsToTextEdits(mp, edits)
 }
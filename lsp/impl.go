@@ -2,6 +2,7 @@ package lsp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path"
 	"strings"
@@ -28,6 +29,10 @@ func (s *Server) didOpen(ctx context.Context, params *protocol.DidOpenTextDocume
 		From:    cache.FileChangeTypeDidOpen,
 	}
 
+	s.openMu.Lock()
+	s.openFiles[fileURI] = true
+	s.openMu.Unlock()
+
 	s.session.Initialize(func() {
 		file := change.URI
 		dirPos := strings.LastIndexByte(string(file), '/')
@@ -36,6 +41,7 @@ func (s *Server) didOpen(ctx context.Context, params *protocol.DidOpenTextDocume
 		}
 		dir := file[0:dirPos]
 		s.walkFoldersThriftFile(dir)
+		s.runInBackground(s.diagnosticWorkspace)
 	})
 
 	return s.openFile(ctx, change)
@@ -52,24 +58,45 @@ func (s *Server) openFile(ctx context.Context, change *cache.FileChange) error {
 		// create view for this folder
 		filename := change.URI.Filename()
 		dir := uri.New(path.Dir(filename))
-		s.session.CreateView(dir)
+		s.session.CreateView(dir, s.viewOptions(dir))
 	}
 
 	view, _ := s.session.ViewOf(change.URI)
 	view.FileChange(ctx, []*cache.FileChange{change}, func() {
+		// files of workspace are diagnosed together after all of them are indexed
+		if change.From == cache.FileChangeTypeInitialize {
+			return
+		}
 		ss, release := view.Snapshot()
 		defer release()
-		err := s.diagnostic(ctx, ss, change)
+		err := s.diagnostic(ctx, ss, []uri.URI{change.URI})
 		if err != nil {
 			log.Errorf("diagnostic error: %v", err)
 		}
+		s.diagnosticDependents(ss, []uri.URI{change.URI})
 	})
 
 	return nil
 }
 
+func (s *Server) didClose(ctx context.Context, params *protocol.DidCloseTextDocumentParams) error {
+	s.openMu.Lock()
+	delete(s.openFiles, params.TextDocument.URI)
+	s.openMu.Unlock()
+	s.semanticTokens.forget(params.TextDocument.URI)
+
+	return nil
+}
+
+// isOpen reports whether file is opened in editor
+func (s *Server) isOpen(file uri.URI) bool {
+	s.openMu.Lock()
+	defer s.openMu.Unlock()
+	return s.openFiles[file]
+}
+
 func (s *Server) didChange(ctx context.Context, params *protocol.DidChangeTextDocumentParams) error {
-	changes := cache.FileChangeFromLSPDidChange(params)
+	changes := cache.FileChangeFromLSPDidChange(params, contentChangeRanges(ctx, params))
 	if err := s.session.UpdateOverlayFS(ctx, changes); err != nil {
 		return err
 	}
@@ -82,14 +109,17 @@ func (s *Server) didChange(ctx context.Context, params *protocol.DidChangeTextDo
 	}
 
 	view.FileChange(ctx, changes, func() {
+		if len(changes) == 0 {
+			return
+		}
 		ss, release := view.Snapshot()
 		defer release()
-		for i := range changes {
-			err := s.diagnostic(ctx, ss, changes[i])
-			if err != nil {
-				log.Error("diagnostic error", err)
-			}
+		// all changes are made to the same document, it's diagnosed once
+		err := s.diagnostic(ctx, ss, []uri.URI{fileURI})
+		if err != nil {
+			log.Error("diagnostic error", err)
 		}
+		s.diagnosticDependents(ss, []uri.URI{fileURI})
 	})
 
 	return nil
@@ -102,16 +132,25 @@ func (s *Server) completion(ctx context.Context, params *protocol.CompletionPara
 	}
 	defer release()
 
-	items, rng, err := completion.DefaultTokenCompletion.Completion(ctx, snapshot, &completion.CompletionRequest{
+	req := &completion.CompletionRequest{
 		TriggerKind: 0,
 		Pos: types.Position{
 			Line:      params.Position.Line,
 			Character: params.Position.Character,
 		},
 		Fh: fh,
-	})
+	}
+	items, rng, err := completion.DefaultSemanticBasedCompletion.Completion(ctx, snapshot, req)
 	if err != nil {
-		return nil, err
+		if !errors.Is(err, completion.UnknownPositionError) {
+			log.Errorf("semantic based completion failed: %v", err)
+		}
+		// position is unknown, fallback to token based completion. no candidate in known position means
+		// nothing can be completed there
+		items, rng, err = completion.DefaultTokenCompletion.Completion(ctx, snapshot, req)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return toLspCompletionList(items, rng), nil
@@ -136,6 +175,8 @@ func toLspCompletionList(items []*completion.CompletionItem, rng protocol.Range)
 			Preselect:        i == 0,
 			Deprecated:       items[i].Deprecated,
 			Documentation:    items[i].Documentation,
+
+			AdditionalTextEdits: items[i].AdditionalTextEdits,
 		}
 		list.Items = append(list.Items, item)
 	}
@@ -2,12 +2,14 @@ package lsp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/joyme123/thrift-ls/lsp/cache"
 	"github.com/joyme123/thrift-ls/lsp/memoize"
 	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/jsonrpc2"
 	"go.lsp.dev/protocol"
 	"go.lsp.dev/uri"
 )
@@ -107,6 +109,51 @@ struct Test {
 	assert.Equal(t, gotContent, []byte(fileContent))
 }
 
+func Test_DidChangeIncremental(t *testing.T) {
+	ctx := context.TODO()
+	fileURI, err := uri.Parse("file:///tmp/file.thrift")
+	assert.NoError(t, err)
+
+	store := &memoize.Store{}
+	cache := cache.New(store)
+	srv := NewServer(cache, nil)
+
+	err = srv.DidOpen(ctx, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{
+			URI:        fileURI,
+			LanguageID: "thrift",
+			Version:    0,
+			Text:       "struct Test {\n}\n",
+		},
+	})
+	assert.NoError(t, err)
+
+	// the second change has no range, it replaces full content
+	params := `{
+	"textDocument": {"uri": "file:///tmp/file.thrift", "version": 1},
+	"contentChanges": [
+		{"range": {"start": {"line": 1, "character": 0}, "end": {"line": 1, "character": 0}}, "text": "\t1: string Name\n"},
+		{"text": "struct User {\n}\n"},
+		{"range": {"start": {"line": 0, "character": 0}, "end": {"line": 0, "character": 0}}, "text": "// user\n"},
+		{"range": {"start": {"line": 2, "character": 0}, "end": {"line": 2, "character": 0}}, "text": "\t1: string Name\n"}
+	]
+}`
+	req, err := jsonrpc2.NewNotification(protocol.MethodTextDocumentDidChange, json.RawMessage(params))
+	assert.NoError(t, err)
+	handler := TextSyncHandler(protocol.ServerHandler(srv, jsonrpc2.MethodNotFoundHandler))
+	err = handler(ctx, func(ctx context.Context, result interface{}, err error) error {
+		return err
+	}, req)
+	assert.NoError(t, err)
+
+	fh, err := srv.session.ReadFile(ctx, fileURI)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, int(fh.Version()))
+	gotContent, err := fh.Content()
+	assert.NoError(t, err)
+	assert.Equal(t, "// user\nstruct User {\n\t1: string Name\n}\n", string(gotContent))
+}
+
 func Test_Completion(t *testing.T) {
 	ctx := context.TODO()
 	fileURI, err := uri.Parse("file:///tmp/file.thrift")
@@ -189,3 +236,61 @@ struct Test {
 	assert.Equal(t, expectCompletionList.Items[0].TextEdit, completionList.Items[0].TextEdit)
 	assert.Equal(t, expectCompletionList, completionList)
 }
+
+func Test_CompletionInKnownPosition(t *testing.T) {
+	ctx := context.TODO()
+	fileURI := uri.URI("file:///tmp/file.thrift")
+	fileContent := `struct Test {
+	1: required string Name,
+	2: required Na
+}`
+
+	srv := NewServer(cache.New(&memoize.Store{}), nil)
+	err := srv.DidOpen(ctx, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{
+			URI:        fileURI,
+			LanguageID: "thrift",
+			Text:       fileContent,
+		},
+	})
+	assert.NoError(t, err)
+
+	// no type matches prefix in field type position, keywords and identifiers aren't listed
+	completionList, err := srv.Completion(ctx, &protocol.CompletionParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+			Position:     protocol.Position{Line: 2, Character: 15},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, completionList.Items)
+}
+
+func Test_DidCloseForgetsSemanticTokens(t *testing.T) {
+	ctx := context.TODO()
+	fileURI := uri.URI("file:///tmp/file.thrift")
+
+	srv := NewServer(cache.New(&memoize.Store{}), nil)
+	err := srv.DidOpen(ctx, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{
+			URI:        fileURI,
+			LanguageID: "thrift",
+			Text:       "struct Test {\n\t1: required string Name\n}\n",
+		},
+	})
+	assert.NoError(t, err)
+
+	tokens, err := srv.SemanticTokensFull(ctx, &protocol.SemanticTokensParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+	})
+	assert.NoError(t, err)
+	_, ok := srv.semanticTokens.get(fileURI, tokens.ResultID)
+	assert.True(t, ok)
+
+	err = srv.DidClose(ctx, &protocol.DidCloseTextDocumentParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, srv.semanticTokens.results)
+	assert.False(t, srv.isOpen(fileURI))
+}
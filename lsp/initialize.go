@@ -7,6 +7,9 @@ import (
 	"strings"
 
 	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/diagnostic"
+	"github.com/joyme123/thrift-ls/lsp/semantictoken"
+	"github.com/joyme123/thrift-ls/lsp/signaturehelp"
 	log "github.com/sirupsen/logrus"
 	"go.lsp.dev/protocol"
 	"go.lsp.dev/uri"
@@ -27,6 +30,20 @@ func (s *Server) initialize(ctx context.Context, params *protocol.InitializePara
 		folders = append(folders, uri.URI(ws.URI))
 	}
 
+	if workspace := params.Capabilities.Workspace; workspace != nil && workspace.DidChangeWatchedFiles != nil {
+		s.watchFilesSupported = workspace.DidChangeWatchedFiles.DynamicRegistration
+	}
+	if window := params.Capabilities.Window; window != nil {
+		s.workDoneProgressSupported = window.WorkDoneProgress
+	}
+	s.pullDiagnostics = pullDiagnosticsSupported(ctx)
+	s.diagnosticRefreshSupported = diagnosticRefreshSupported(ctx)
+	if params.InitializationOptions != nil {
+		if err := decodeParams(params.InitializationOptions, &s.initOptions); err != nil {
+			log.Errorf("invalid initialization options: %v", err)
+		}
+	}
+
 	log.Debugln("initialized folders: ", folders)
 	if len(folders) > 0 {
 		s.session.Initialize(func() {
@@ -39,7 +56,26 @@ func (s *Server) initialize(ctx context.Context, params *protocol.InitializePara
 	return initializeResult(), nil
 }
 
+func (s *Server) initialized(ctx context.Context, params *protocol.InitializedParams) error {
+	// files of workspace folders are indexed in initialize
+	s.runInBackground(s.diagnosticWorkspace)
+
+	if s.client == nil || !s.watchFilesSupported {
+		return nil
+	}
+
+	return s.client.RegisterCapability(ctx, &protocol.RegistrationParams{
+		Registrations: []protocol.Registration{watchedFilesRegistration()},
+	})
+}
+
 func (s *Server) walkFoldersThriftFile(folder uri.URI) {
+	// view is created with config of folder before files are opened, otherwise it's created at directory of
+	// the first file
+	if _, err := s.session.ViewOf(folder); err != nil {
+		s.session.CreateView(folder, s.viewOptions(folder))
+	}
+
 	log.Debugln("walk dir 2: ", folder.Filename())
 	// WalkDir walk files with lexical order
 	filepath.WalkDir(folder.Filename(), func(path string, d fs.DirEntry, err error) error {
@@ -49,10 +85,13 @@ func (s *Server) walkFoldersThriftFile(folder uri.URI) {
 		}
 
 		if d.IsDir() {
+			if s.excluded(folder, path) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		if !strings.HasSuffix(path, ".thrift") {
+		if !strings.HasSuffix(path, ".thrift") || s.excluded(folder, path) {
 			return nil
 		}
 
@@ -75,12 +114,14 @@ func (s *Server) walkFoldersThriftFile(folder uri.URI) {
 }
 
 func initializeResult() *protocol.InitializeResult {
+	semanticTokensRange := true
+	semanticTokensDelta := true
 	res := &protocol.InitializeResult{
 		Capabilities: protocol.ServerCapabilities{
 			TextDocumentSync: &protocol.TextDocumentSyncOptions{
 				OpenClose: true,
-				// full is easy to implement. consider to use incremental for performance
-				Change:            protocol.TextDocumentSyncKindFull,
+				// only changed ranges are sent, large files don't need to be sent on every keystroke
+				Change:            protocol.TextDocumentSyncKindIncremental,
 				WillSave:          true,
 				WillSaveWaitUntil: true,
 				Save: &protocol.SaveOptions{
@@ -110,7 +151,7 @@ func initializeResult() *protocol.InitializeResult {
 				},
 			},
 			SignatureHelpProvider: &protocol.SignatureHelpOptions{
-				TriggerCharacters:   []string{},
+				TriggerCharacters:   signaturehelp.TriggerCharacters,
 				RetriggerCharacters: []string{},
 			},
 			DeclarationProvider: &protocol.DeclarationRegistrationOptions{
@@ -157,7 +198,7 @@ func initializeResult() *protocol.InitializeResult {
 					WorkDoneProgress: true,
 				},
 			},
-			DocumentHighlightProvider: false,
+			DocumentHighlightProvider: true,
 			DocumentSymbolProvider: &protocol.DocumentSymbolOptions{
 				WorkDoneProgressOptions: protocol.WorkDoneProgressOptions{
 					WorkDoneProgress: true,
@@ -165,8 +206,7 @@ func initializeResult() *protocol.InitializeResult {
 				Label: "thriftls",
 			},
 			CodeActionProvider: &protocol.CodeActionOptions{
-				// TODO(jpf): should support code actions
-				CodeActionKinds: []protocol.CodeActionKind{},
+				CodeActionKinds: diagnostic.CodeActionKinds,
 				ResolveProvider: false,
 			},
 			CodeLensProvider: &protocol.CodeLensOptions{
@@ -175,7 +215,8 @@ func initializeResult() *protocol.InitializeResult {
 			DocumentLinkProvider: &protocol.DocumentLinkOptions{
 				ResolveProvider: false,
 			},
-			ColorProvider: false,
+			ColorProvider:        false,
+			FoldingRangeProvider: true,
 			WorkspaceSymbolProvider: &protocol.WorkspaceSymbolOptions{
 				WorkDoneProgressOptions: protocol.WorkDoneProgressOptions{
 					WorkDoneProgress: true,
@@ -201,7 +242,7 @@ func initializeResult() *protocol.InitializeResult {
 			ExecuteCommandProvider: &protocol.ExecuteCommandOptions{
 				Commands: []string{},
 			},
-			CallHierarchyProvider:      false,
+			CallHierarchyProvider:      true,
 			LinkedEditingRangeProvider: false,
 			SemanticTokensProvider: &protocol.SemanticTokensRegistrationOptions{
 				TextDocumentRegistrationOptions: protocol.TextDocumentRegistrationOptions{
@@ -215,9 +256,10 @@ func initializeResult() *protocol.InitializeResult {
 					WorkDoneProgressOptions: protocol.WorkDoneProgressOptions{
 						WorkDoneProgress: true,
 					},
-					Legend: protocol.SemanticTokensLegend{
-						TokenTypes:     []protocol.SemanticTokenTypes{},
-						TokenModifiers: []protocol.SemanticTokenModifiers{},
+					Legend: semantictoken.Legend,
+					Range:  &semanticTokensRange,
+					Full: &protocol.SemanticTokensFullOptions{
+						Delta: &semanticTokensDelta,
 					},
 				},
 				StaticRegistrationOptions: protocol.StaticRegistrationOptions{
@@ -1,10 +1,13 @@
 package lsputils
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/joyme123/thrift-ls/parser"
+	"github.com/joyme123/thrift-ls/utils"
 	"go.lsp.dev/protocol"
 	"go.lsp.dev/uri"
 )
@@ -59,12 +62,193 @@ func GetIncludePath(ast *parser.Document, includeName string) string {
 
 // cur is current file uri. for example file:///tmp/user.thrift
 // includePath is include name used in code. for example: base.thrift
-func IncludeURI(cur uri.URI, includePath string) uri.URI {
+// includeDirs are searched in order if includePath doesn't exist relative to cur, like `thrift -I`
+func IncludeURI(cur uri.URI, includePath string, includeDirs ...string) uri.URI {
 	filePath := cur.Filename()
 	items := strings.Split(filePath, string(filepath.Separator))
 	basePath := strings.TrimSuffix(filePath, items[len(items)-1])
 
 	path := filepath.Join(basePath, includePath)
+	if len(includeDirs) > 0 && !fileExists(path) {
+		if found, ok := parser.SearchIncludeDirs(includePath, includeDirs); ok {
+			path = found
+		}
+	}
 
 	return uri.File(path)
 }
+
+// IncludePath returns include path used in cur to include target. It is the reverse of IncludeURI.
+// for example: cur is file:///tmp/api.thrift, target is file:///tmp/common/user.thrift, then
+// common/user.thrift is returned. path relative to include dir is preferred to path out of directory of cur
+func IncludePath(cur uri.URI, target uri.URI, includeDirs ...string) (string, bool) {
+	path, err := filepath.Rel(filepath.Dir(cur.Filename()), target.Filename())
+	if err != nil {
+		return "", false
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "../") {
+		return path, true
+	}
+
+	for _, dir := range includeDirs {
+		rel, err := filepath.Rel(dir, target.Filename())
+		if err != nil || strings.HasPrefix(filepath.ToSlash(rel), "../") {
+			continue
+		}
+		// file with the same path relative to cur shadows the file in include dir
+		if IncludeURI(cur, rel, includeDirs...) == target {
+			return filepath.ToSlash(rel), true
+		}
+	}
+
+	return path, true
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// ConstValueIdentifierLocation returns location of identifier in const value, such as `Status.OK`.
+// location of const value contains leading comments and trailing indents, so identifier is located
+// by the end of const value.
+func ConstValueIdentifierLocation(content []byte, cv *parser.ConstValue) (parser.Location, bool) {
+	text, ok := cv.Value.(string)
+	if cv.TypeName != "identifier" || !ok || text == "" {
+		return parser.Location{}, false
+	}
+
+	start, end := cv.Pos().Offset, cv.End().Offset
+	if start < 0 || end > len(content) || start > end {
+		return parser.Location{}, false
+	}
+	raw := string(content[start:end])
+	trailing := len(raw) - len(strings.TrimRight(raw, " \t\v"))
+	if !strings.HasSuffix(raw[:len(raw)-trailing], text) {
+		return parser.Location{}, false
+	}
+
+	endPos := parser.Position{
+		Line:   cv.End().Line,
+		Col:    cv.End().Col - trailing,
+		Offset: end - trailing,
+	}
+	startPos := parser.Position{
+		Line:   endPos.Line,
+		Col:    endPos.Col - utf8.RuneCountInString(text),
+		Offset: endPos.Offset - len(text),
+	}
+
+	return parser.NewLocationFromPos(startPos, endPos), true
+}
+
+// Comments returns all comments in ast, including comments attached to keywords
+func Comments(doc *parser.Document) []*parser.Comment {
+	var comments []*parser.Comment
+	seen := make(map[*parser.Comment]struct{})
+
+	type keywordNode interface {
+		GetKeyword() *parser.Keyword
+	}
+
+	add := func(items []*parser.Comment) {
+		for _, item := range items {
+			if item == nil || item.BadNode {
+				continue
+			}
+			if _, ok := seen[item]; ok {
+				continue
+			}
+			seen[item] = struct{}{}
+			comments = append(comments, item)
+		}
+	}
+
+	var walk func(node parser.Node)
+	walk = func(node parser.Node) {
+		if utils.IsNil(node) {
+			return
+		}
+		switch n := node.(type) {
+		case *parser.Comment:
+			add([]*parser.Comment{n})
+			return
+		case keywordNode:
+			add(n.GetKeyword().Comments)
+			return
+		case *parser.ConstValue:
+			add(n.Comments)
+			walk(n.LBrkKeyword)
+			walk(n.RBrkKeyword)
+			walk(n.LCurKeyword)
+			walk(n.RCurKeyword)
+			walk(n.ColonKeyword)
+			walk(n.ListSeparatorKeyword)
+			if literal, ok := n.Value.(*parser.Literal); ok {
+				walk(literal)
+			}
+			if values, ok := n.Value.([]*parser.ConstValue); ok {
+				for i := range values {
+					walk(values[i])
+				}
+			}
+			if value, ok := n.Value.(*parser.ConstValue); ok {
+				walk(value)
+			}
+			if key, ok := n.Key.(*parser.ConstValue); ok {
+				walk(key)
+			}
+			return
+		case *parser.Enum:
+			// keywords of enum aren't children of it
+			walk(n.EnumKeyword)
+			walk(n.LCurKeyword)
+			walk(n.RCurKeyword)
+		case *parser.Field:
+			if n.Index != nil {
+				add(n.Index.Comments)
+				walk(n.Index.ColonKeyword)
+			}
+		}
+
+		for _, child := range node.Children() {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	return comments
+}
+
+// CommentsDocumentation converts comments to documentation text. comment markers are removed
+func CommentsDocumentation(comments []*parser.Comment) string {
+	lines := make([]string, 0)
+	for _, comment := range comments {
+		if comment == nil || comment.BadNode {
+			continue
+		}
+		text := comment.Text
+		switch comment.Style {
+		case parser.CommentStyleSingleLine:
+			text = strings.TrimPrefix(text, "//")
+		case parser.CommentStyleShell:
+			text = strings.TrimPrefix(text, "#")
+		case parser.CommentStyleMultiLine:
+			text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+			text = strings.TrimPrefix(text, "*") // doc comment: /** xxx */
+		}
+		for _, line := range strings.Split(text, "\n") {
+			line = strings.TrimSpace(line)
+			if comment.Style == parser.CommentStyleMultiLine {
+				line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+			}
+			if line == "" {
+				continue
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -1,6 +1,8 @@
 package lsputils
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/joyme123/thrift-ls/parser"
@@ -50,6 +52,72 @@ func Test_IncludeURI(t *testing.T) {
 	}
 }
 
+func TestIncludePath(t *testing.T) {
+	tests := []struct {
+		name   string
+		cur    uri.URI
+		target uri.URI
+		want   string
+	}{
+		{
+			name:   "same dir",
+			cur:    uri.File("/tmp/workspace/app.thrift"),
+			target: uri.File("/tmp/workspace/user.thrift"),
+			want:   "user.thrift",
+		},
+		{
+			name:   "sub dir",
+			cur:    uri.File("/tmp/workspace/app.thrift"),
+			target: uri.File("/tmp/workspace/common/user.thrift"),
+			want:   "common/user.thrift",
+		},
+		{
+			name:   "parent dir",
+			cur:    uri.File("/tmp/workspace/app.thrift"),
+			target: uri.File("/tmp/user.thrift"),
+			want:   "../user.thrift",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := IncludePath(tt.cur, tt.target)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.target, IncludeURI(tt.cur, got))
+		})
+	}
+}
+
+func TestIncludeDirs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"idl/api.thrift", "idl/user.thrift", "common/base.thrift", "common/user.thrift", "shared/base.thrift"} {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	cur := uri.File(filepath.Join(dir, "idl/api.thrift"))
+	includeDirs := []string{filepath.Join(dir, "common"), filepath.Join(dir, "shared")}
+
+	// file relative to current file is preferred, then include dirs in order
+	assert.Equal(t, uri.File(filepath.Join(dir, "idl/user.thrift")), IncludeURI(cur, "user.thrift", includeDirs...))
+	assert.Equal(t, uri.File(filepath.Join(dir, "common/base.thrift")), IncludeURI(cur, "base.thrift", includeDirs...))
+	assert.Equal(t, uri.File(filepath.Join(dir, "idl/other.thrift")), IncludeURI(cur, "other.thrift", includeDirs...))
+
+	tests := []struct {
+		target uri.URI
+		want   string
+	}{
+		{target: uri.File(filepath.Join(dir, "common/base.thrift")), want: "base.thrift"},
+		{target: uri.File(filepath.Join(dir, "shared/base.thrift")), want: "../shared/base.thrift"},
+		{target: uri.File(filepath.Join(dir, "common/user.thrift")), want: "../common/user.thrift"},
+	}
+	for _, tt := range tests {
+		got, ok := IncludePath(cur, tt.target, includeDirs...)
+		assert.True(t, ok)
+		assert.Equal(t, tt.want, got)
+		assert.Equal(t, tt.target, IncludeURI(cur, got, includeDirs...))
+	}
+}
+
 func TestGetIncludePath(t *testing.T) {
 	file := `include "../../user.thrift"
 	include "../../com.github.api.thrift"
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"unicode/utf8"
 
@@ -138,6 +139,63 @@ func (m *Mapper) LSPPosToParserPosition(pos types.Position) (parser.Position, er
 	}, nil
 }
 
+// LSPPosToOffset converts utf16-based position to 0-based byte offset. character exceeding line length
+// is converted to the end of line
+func (m *Mapper) LSPPosToOffset(pos types.Position) (int, error) {
+	m.initLineStart()
+	if int(pos.Line) >= len(m.lineStart) {
+		return 0, fmt.Errorf("invalid position line, request line: %d, total line: %d", pos.Line+1, len(m.lineStart))
+	}
+
+	offset := m.lineStart[pos.Line]
+	lineEnd := len(m.content)
+	if int(pos.Line)+1 < len(m.lineStart) {
+		lineEnd = m.lineStart[pos.Line+1] - 1 // exclude '\n'
+	}
+
+	if !m.nonASCII {
+		if offset+int(pos.Character) > lineEnd {
+			return lineEnd, nil
+		}
+		return offset + int(pos.Character), nil
+	}
+
+	utf16Col := 0
+	for offset < lineEnd && utf16Col < int(pos.Character) {
+		r, size := utf8.DecodeRune(m.content[offset:])
+		utf16Col++
+		if r >= 0x10000 {
+			utf16Col++
+		}
+		offset += size
+	}
+
+	return offset, nil
+}
+
+// OffsetToLSPPosition converts 0-based byte offset to utf16-based position
+func (m *Mapper) OffsetToLSPPosition(offset int) (types.Position, error) {
+	m.initLineStart()
+	if offset < 0 || offset > len(m.content) {
+		return types.Position{}, fmt.Errorf("invalid offset: %d, total content: %d", offset, len(m.content))
+	}
+
+	// index of the last line which starts before or at offset
+	line := sort.Search(len(m.lineStart), func(i int) bool {
+		return m.lineStart[i] > offset
+	}) - 1
+
+	character := offset - m.lineStart[line]
+	if m.nonASCII {
+		character = utf16Count(m.content[m.lineStart[line]:offset])
+	}
+
+	return types.Position{
+		Line:      uint32(line),
+		Character: uint32(character),
+	}, nil
+}
+
 func utf16Count(contents []byte) int {
 	utf16Len := 0
 	for len(contents) > 0 {
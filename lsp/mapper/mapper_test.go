@@ -210,3 +210,148 @@ func Test_utf16Count(t *testing.T) {
 		})
 	}
 }
+
+func TestMapper_OffsetToLSPPosition(t *testing.T) {
+	content := `struct demo {
+  1: required string name,
+}`
+
+	runeContent := `struct 😀😂 {
+  1: required string 名字,
+}`
+
+	tests := []struct {
+		name      string
+		content   string
+		offset    int
+		want      types.Position
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "ascii",
+			content:   content,
+			offset:    16,
+			want:      types.Position{Line: 1, Character: 2},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "line start",
+			content:   content,
+			offset:    14,
+			want:      types.Position{Line: 1, Character: 0},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "end of content",
+			content:   content,
+			offset:    len(content),
+			want:      types.Position{Line: 2, Character: 1},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "rune",
+			content:   runeContent,
+			offset:    16,
+			want:      types.Position{Line: 0, Character: 12},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "rune after multi bytes character",
+			content:   runeContent,
+			offset:    len("struct 😀😂 {\n  1: required string 名字"),
+			want:      types.Position{Line: 1, Character: 23},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "offset exceeded",
+			content:   content,
+			offset:    len(content) + 1,
+			want:      types.Position{},
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMapper("test/test.thrift", []byte(tt.content))
+			got, err := m.OffsetToLSPPosition(tt.offset)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMapper_LSPPosToOffset(t *testing.T) {
+	content := `struct demo {
+  1: required string name,
+}`
+
+	runeContent := `struct 😀😂 {
+  1: required string 名字,
+}`
+
+	tests := []struct {
+		name      string
+		content   string
+		pos       types.Position
+		want      int
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "ascii",
+			content:   content,
+			pos:       types.Position{Line: 1, Character: 2},
+			want:      16,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "ascii end of line",
+			content:   content,
+			pos:       types.Position{Line: 0, Character: 20},
+			want:      13,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "ascii end of content",
+			content:   content,
+			pos:       types.Position{Line: 2, Character: 1},
+			want:      len(content),
+			assertion: assert.NoError,
+		},
+		{
+			name:      "rune",
+			content:   runeContent,
+			pos:       types.Position{Line: 0, Character: 12},
+			want:      16,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "rune after multi bytes character",
+			content:   runeContent,
+			pos:       types.Position{Line: 1, Character: 23},
+			want:      len("struct 😀😂 {\n  1: required string 名字"),
+			assertion: assert.NoError,
+		},
+		{
+			name:      "rune end of content",
+			content:   runeContent,
+			pos:       types.Position{Line: 2, Character: 1},
+			want:      len(runeContent),
+			assertion: assert.NoError,
+		},
+		{
+			name:      "line exceeded",
+			content:   content,
+			pos:       types.Position{Line: 3, Character: 0},
+			want:      0,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMapper("test/test.thrift", []byte(tt.content))
+			got, err := m.LSPPosToOffset(tt.pos)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	log "github.com/sirupsen/logrus"
+	"go.lsp.dev/protocol"
+)
+
+// workDoneProgress reports progress of a job started by server through $/progress notifications. nothing
+// is reported if client doesn't support it
+type workDoneProgress struct {
+	client protocol.Client
+	token  *protocol.ProgressToken
+
+	percentage uint32
+}
+
+func (s *Server) newWorkDoneProgress(ctx context.Context, title string) *workDoneProgress {
+	if s.client == nil || !s.workDoneProgressSupported {
+		return &workDoneProgress{}
+	}
+
+	token := protocol.NewProgressToken(fmt.Sprintf("%s-%d", ServerName, rand.Int63()))
+	if err := s.client.WorkDoneProgressCreate(ctx, &protocol.WorkDoneProgressCreateParams{Token: *token}); err != nil {
+		log.Errorf("create work done progress failed: %v", err)
+		return &workDoneProgress{}
+	}
+
+	p := &workDoneProgress{
+		client: s.client,
+		token:  token,
+	}
+	p.notify(ctx, &protocol.WorkDoneProgressBegin{
+		Kind:       protocol.WorkDoneProgressKindBegin,
+		Title:      title,
+		Percentage: 0,
+	})
+
+	return p
+}
+
+// Report reports message and percentage of job. percentage is reported only if it's increased
+func (p *workDoneProgress) Report(ctx context.Context, message string, percentage uint32) {
+	if p.token == nil || percentage <= p.percentage {
+		return
+	}
+	p.percentage = percentage
+
+	p.notify(ctx, &protocol.WorkDoneProgressReport{
+		Kind:       protocol.WorkDoneProgressKindReport,
+		Message:    message,
+		Percentage: percentage,
+	})
+}
+
+// End reports job is finished
+func (p *workDoneProgress) End(ctx context.Context, message string) {
+	if p.token == nil {
+		return
+	}
+
+	p.notify(ctx, &protocol.WorkDoneProgressEnd{
+		Kind:    protocol.WorkDoneProgressKindEnd,
+		Message: message,
+	})
+}
+
+func (p *workDoneProgress) notify(ctx context.Context, value interface{}) {
+	err := p.client.Progress(ctx, &protocol.ProgressParams{
+		Token: *p.token,
+		Value: value,
+	})
+	if err != nil {
+		log.Errorf("report work done progress failed: %v", err)
+	}
+}
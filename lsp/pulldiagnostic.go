@@ -0,0 +1,314 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/diagnostic"
+	log "github.com/sirupsen/logrus"
+	"go.lsp.dev/jsonrpc2"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// methods of pull diagnostics introduced in LSP 3.17. they aren't supported by protocol package, and are
+// dispatched by Server.Request
+const (
+	methodTextDocumentDiagnostic = "textDocument/diagnostic"
+	methodWorkspaceDiagnostic    = "workspace/diagnostic"
+	// methodWorkspaceDiagnosticRefresh is sent to client, it asks client to pull diagnostics again
+	methodWorkspaceDiagnosticRefresh = "workspace/diagnostic/refresh"
+)
+
+type documentDiagnosticReportKind string
+
+const (
+	documentDiagnosticReportKindFull      documentDiagnosticReportKind = "full"
+	documentDiagnosticReportKindUnchanged documentDiagnosticReportKind = "unchanged"
+)
+
+type diagnosticOptions struct {
+	Identifier            string `json:"identifier,omitempty"`
+	InterFileDependencies bool   `json:"interFileDependencies"`
+	WorkspaceDiagnostics  bool   `json:"workspaceDiagnostics"`
+}
+
+type documentDiagnosticParams struct {
+	TextDocument     protocol.TextDocumentIdentifier `json:"textDocument"`
+	Identifier       string                          `json:"identifier,omitempty"`
+	PreviousResultID string                          `json:"previousResultId,omitempty"`
+}
+
+// documentDiagnosticReport is a full report if Kind is full, otherwise Items is omitted. Items is a pointer,
+// so empty items of full report aren't omitted
+type documentDiagnosticReport struct {
+	Kind     documentDiagnosticReportKind `json:"kind"`
+	ResultID string                       `json:"resultId,omitempty"`
+	Items    *[]protocol.Diagnostic       `json:"items,omitempty"`
+}
+
+type previousResultID struct {
+	URI   uri.URI `json:"uri"`
+	Value string  `json:"value"`
+}
+
+type workspaceDiagnosticParams struct {
+	Identifier        string             `json:"identifier,omitempty"`
+	PreviousResultIDs []previousResultID `json:"previousResultIds"`
+}
+
+type workspaceDocumentDiagnosticReport struct {
+	documentDiagnosticReport
+	URI uri.URI `json:"uri"`
+	// Version is null if file isn't opened by client
+	Version *int32 `json:"version"`
+}
+
+type workspaceDiagnosticReport struct {
+	Items []workspaceDocumentDiagnosticReport `json:"items"`
+}
+
+// pullDiagnosticsKey is the context key of whether client supports pull diagnostics
+type pullDiagnosticsKey struct{}
+
+// diagnosticRefreshKey is the context key of whether client supports refresh requests of pull diagnostics
+type diagnosticRefreshKey struct{}
+
+// clientCaller sends requests to client. requests not supported by protocol package are sent by it
+type clientCaller interface {
+	Call(ctx context.Context, method string, params, result interface{}) (jsonrpc2.ID, error)
+}
+
+// PullDiagnosticsHandler advertises pull diagnostics to client supporting them. capabilities of pull
+// diagnostics aren't decoded by protocol package, so they are read from raw initialize request, and
+// diagnosticProvider is added to initialize result
+func PullDiagnosticsHandler(handler jsonrpc2.Handler) jsonrpc2.Handler {
+	return func(ctx context.Context, reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+		if req.Method() != protocol.MethodInitialize {
+			return handler(ctx, reply, req)
+		}
+
+		var params struct {
+			Capabilities struct {
+				TextDocument struct {
+					Diagnostic *json.RawMessage `json:"diagnostic"`
+				} `json:"textDocument"`
+				Workspace struct {
+					Diagnostics struct {
+						RefreshSupport bool `json:"refreshSupport"`
+					} `json:"diagnostics"`
+				} `json:"workspace"`
+			} `json:"capabilities"`
+		}
+		if err := json.Unmarshal(req.Params(), &params); err != nil || params.Capabilities.TextDocument.Diagnostic == nil {
+			return handler(ctx, reply, req)
+		}
+
+		ctx = context.WithValue(ctx, pullDiagnosticsKey{}, true)
+		ctx = context.WithValue(ctx, diagnosticRefreshKey{}, params.Capabilities.Workspace.Diagnostics.RefreshSupport)
+		return handler(ctx, func(ctx context.Context, result interface{}, err error) error {
+			if res, ok := result.(*protocol.InitializeResult); ok && err == nil {
+				result = initializeResultWithDiagnostic(res)
+			}
+			return reply(ctx, result, err)
+		}, req)
+	}
+}
+
+// pullDiagnosticsSupported reports whether client supports pull diagnostics
+func pullDiagnosticsSupported(ctx context.Context) bool {
+	supported, _ := ctx.Value(pullDiagnosticsKey{}).(bool)
+	return supported
+}
+
+// diagnosticRefreshSupported reports whether client supports refresh requests of pull diagnostics
+func diagnosticRefreshSupported(ctx context.Context) bool {
+	supported, _ := ctx.Value(diagnosticRefreshKey{}).(bool)
+	return supported
+}
+
+// refreshDiagnostics asks client to pull diagnostics again. it's used when diagnostics are changed without
+// requests of client, such as files changed on disk and project config reloaded
+func (s *Server) refreshDiagnostics() {
+	if !s.pullDiagnostics || !s.diagnosticRefreshSupported {
+		return
+	}
+	caller, ok := s.client.(clientCaller)
+	if !ok {
+		return
+	}
+
+	// handler isn't blocked until client replies
+	s.runInBackground(func() {
+		if _, err := caller.Call(context.Background(), methodWorkspaceDiagnosticRefresh, nil, nil); err != nil {
+			log.Errorf("refresh diagnostics error: %v", err)
+		}
+	})
+}
+
+type serverCapabilitiesWithDiagnostic struct {
+	protocol.ServerCapabilities
+	DiagnosticProvider *diagnosticOptions `json:"diagnosticProvider,omitempty"`
+}
+
+type initializeResultWithDiagnosticProvider struct {
+	Capabilities serverCapabilitiesWithDiagnostic `json:"capabilities"`
+	ServerInfo   *protocol.ServerInfo             `json:"serverInfo,omitempty"`
+}
+
+func initializeResultWithDiagnostic(res *protocol.InitializeResult) *initializeResultWithDiagnosticProvider {
+	return &initializeResultWithDiagnosticProvider{
+		Capabilities: serverCapabilitiesWithDiagnostic{
+			ServerCapabilities: res.Capabilities,
+			DiagnosticProvider: &diagnosticOptions{
+				Identifier: ServerName,
+				// diagnostics of a file are changed by files it includes
+				InterFileDependencies: true,
+				WorkspaceDiagnostics:  true,
+			},
+		},
+		ServerInfo: res.ServerInfo,
+	}
+}
+
+// decodeParams decodes params of non standard request, which is decoded as interface{} by protocol package
+func decodeParams(params interface{}, v interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("%w: %v", jsonrpc2.ErrInvalidParams, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %v", jsonrpc2.ErrInvalidParams, err)
+	}
+
+	return nil
+}
+
+func (s *Server) documentDiagnostic(ctx context.Context, params *documentDiagnosticParams) (*documentDiagnosticReport, error) {
+	ss, release, _, err := s.getFileContext(ctx, params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.diagnosticReport(ctx, ss, params.TextDocument.URI, params.PreviousResultID)
+}
+
+func (s *Server) workspaceDiagnostic(ctx context.Context, params *workspaceDiagnosticParams) (*workspaceDiagnosticReport, error) {
+	previous := make(map[uri.URI]string)
+	for _, item := range params.PreviousResultIDs {
+		previous[item.URI] = item.Value
+	}
+
+	res := &workspaceDiagnosticReport{
+		Items: make([]workspaceDocumentDiagnosticReport, 0),
+	}
+	for _, view := range s.session.Views() {
+		ss, release := view.Snapshot()
+		for _, file := range s.workspaceFiles(view, ss) {
+			report, err := s.diagnosticReport(ctx, ss, file, previous[file])
+			if err != nil {
+				release()
+				return nil, err
+			}
+			item := workspaceDocumentDiagnosticReport{
+				documentDiagnosticReport: *report,
+				URI:                      file,
+			}
+			if fh, err := ss.ReadFile(ctx, file); err == nil && !fh.Saved() {
+				version := fh.Version()
+				item.Version = &version
+			}
+			res.Items = append(res.Items, item)
+		}
+		release()
+	}
+
+	return res, nil
+}
+
+// diagnosticReport returns unchanged report if result id of file is the same as previousResultID
+func (s *Server) diagnosticReport(ctx context.Context, ss *cache.Snapshot, file uri.URI, previousResultID string) (*documentDiagnosticReport, error) {
+	rules := s.diagnosticRules(ss.Folder())
+	resultID, err := diagnosticResultID(ctx, ss, file, rules)
+	if err != nil {
+		return nil, err
+	}
+	if resultID == previousResultID {
+		return &documentDiagnosticReport{
+			Kind:     documentDiagnosticReportKindUnchanged,
+			ResultID: resultID,
+		}, nil
+	}
+
+	diagRes, err := diagnostic.NewDiagnosticWithRules(rules).Diagnostic(ctx, ss, []uri.URI{file})
+	if err != nil {
+		log.Errorf("diagnostic failed: %v", err)
+	}
+	items := diagRes[file]
+	if items == nil {
+		items = make([]protocol.Diagnostic, 0)
+	}
+
+	return &documentDiagnosticReport{
+		Kind:     documentDiagnosticReportKindFull,
+		ResultID: resultID,
+		Items:    &items,
+	}, nil
+}
+
+// diagnosticResultID returns hash of contents of file and files it includes directly or indirectly, and
+// config of diagnostics. diagnostics of file are unchanged if none of them is changed
+func diagnosticResultID(ctx context.Context, ss *cache.Snapshot, file uri.URI, rules map[string]diagnostic.Rule) (string, error) {
+	files := append([]uri.URI{file}, includedFiles(ss, file)...)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i] < files[j]
+	})
+
+	var b strings.Builder
+	// maps are printed in key-sorted order
+	fmt.Fprintf(&b, "%s %v\n", ss.Dialect(), rules)
+	for _, f := range files {
+		fh, err := ss.ReadFile(ctx, f)
+		if err != nil {
+			if f == file {
+				return "", err
+			}
+			// included file doesn't exist
+			b.WriteString(string(f))
+		} else {
+			b.WriteString(fh.FileIdentity().String())
+		}
+		b.WriteString("\n")
+	}
+
+	return cache.HashOf([]byte(b.String())).String(), nil
+}
+
+// includedFiles returns files included by file directly or indirectly, file itself is excluded
+func includedFiles(ss *cache.Snapshot, file uri.URI) []uri.URI {
+	visited := map[uri.URI]bool{file: true}
+	res := make([]uri.URI, 0)
+	queue := []uri.URI{file}
+	for len(queue) > 0 {
+		node := ss.Graph().Get(queue[0])
+		queue = queue[1:]
+		if node == nil {
+			continue
+		}
+		for _, included := range node.OutDegree() {
+			if visited[included] {
+				continue
+			}
+			visited[included] = true
+			res = append(res, included)
+			queue = append(queue, included)
+		}
+	}
+
+	return res
+}
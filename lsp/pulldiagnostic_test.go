@@ -0,0 +1,217 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/memoize"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/jsonrpc2"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func Test_PullDiagnosticsHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities string
+		want         bool
+		refresh      bool
+	}{
+		{
+			name:         "client supports pull diagnostics",
+			capabilities: `{"textDocument": {"diagnostic": {"dynamicRegistration": false}}}`,
+			want:         true,
+		},
+		{
+			name: "client supports refresh of pull diagnostics",
+			capabilities: `{"textDocument": {"diagnostic": {"dynamicRegistration": false}},
+				"workspace": {"diagnostics": {"refreshSupport": true}}}`,
+			want:    true,
+			refresh: true,
+		},
+		{
+			name:         "client doesn't support pull diagnostics",
+			capabilities: `{"textDocument": {"hover": {}}}`,
+			want:         false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := jsonrpc2.NewCall(jsonrpc2.NewNumberID(1), protocol.MethodInitialize,
+				json.RawMessage(`{"capabilities": `+tt.capabilities+`}`))
+			assert.NoError(t, err)
+
+			var result interface{}
+			handler := PullDiagnosticsHandler(func(ctx context.Context, reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+				assert.Equal(t, tt.want, pullDiagnosticsSupported(ctx))
+				assert.Equal(t, tt.refresh, diagnosticRefreshSupported(ctx))
+				return reply(ctx, initializeResult(), nil)
+			})
+			err = handler(context.TODO(), func(ctx context.Context, res interface{}, err error) error {
+				result = res
+				return err
+			}, req)
+			assert.NoError(t, err)
+
+			data, err := json.Marshal(result)
+			assert.NoError(t, err)
+			var got struct {
+				Capabilities map[string]json.RawMessage `json:"capabilities"`
+				ServerInfo   *protocol.ServerInfo       `json:"serverInfo"`
+			}
+			assert.NoError(t, json.Unmarshal(data, &got))
+			assert.Contains(t, got.Capabilities, "hoverProvider")
+			assert.Equal(t, ServerName, got.ServerInfo.Name)
+			if !tt.want {
+				assert.NotContains(t, got.Capabilities, "diagnosticProvider")
+				return
+			}
+			assert.JSONEq(t, `{"identifier": "thriftls", "interFileDependencies": true, "workspaceDiagnostics": true}`,
+				string(got.Capabilities["diagnosticProvider"]))
+		})
+	}
+}
+
+func Test_PullDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.thrift":      "include \"b.thrift\"\n\nstruct A {\n    1: b.B b\n}\n",
+		"b.thrift":      "struct B {\n    1: string id\n}\n",
+		"broken.thrift": "struct Broken {\n    1: string\n}\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	aURI, bURI, brokenURI := uri.File(filepath.Join(dir, "a.thrift")), uri.File(filepath.Join(dir, "b.thrift")),
+		uri.File(filepath.Join(dir, "broken.thrift"))
+
+	client := &recordClient{}
+	srv := NewServer(cache.New(&memoize.Store{}), client)
+	ctx := context.WithValue(context.TODO(), pullDiagnosticsKey{}, true)
+	_, err := srv.Initialize(ctx, &protocol.InitializeParams{RootURI: uri.File(dir)})
+	assert.NoError(t, err)
+	assert.NoError(t, srv.Initialized(ctx, &protocol.InitializedParams{}))
+	srv.background.Wait()
+
+	documentDiagnostic := func(file uri.URI, previousResultID string) *documentDiagnosticReport {
+		res, err := srv.Request(ctx, methodTextDocumentDiagnostic, map[string]interface{}{
+			"textDocument":     map[string]interface{}{"uri": file},
+			"previousResultId": previousResultID,
+		})
+		assert.NoError(t, err)
+		return res.(*documentDiagnosticReport)
+	}
+
+	report := documentDiagnostic(aURI, "")
+	assert.Equal(t, documentDiagnosticReportKindFull, report.Kind)
+	assert.NotEmpty(t, report.ResultID)
+	assert.Equal(t, []protocol.Diagnostic{}, *report.Items)
+	aResultID := report.ResultID
+
+	report = documentDiagnostic(brokenURI, "")
+	assert.Equal(t, documentDiagnosticReportKindFull, report.Kind)
+	assert.NotEmpty(t, *report.Items)
+
+	// result of unchanged file is reported as unchanged
+	report = documentDiagnostic(aURI, aResultID)
+	assert.Equal(t, &documentDiagnosticReport{Kind: documentDiagnosticReportKindUnchanged, ResultID: aResultID}, report)
+
+	// workspace report contains all files, and files not changed are reported as unchanged
+	res, err := srv.Request(ctx, methodWorkspaceDiagnostic, map[string]interface{}{
+		"previousResultIds": []map[string]interface{}{{"uri": aURI, "value": aResultID}},
+	})
+	assert.NoError(t, err)
+	kinds := make(map[uri.URI]documentDiagnosticReportKind)
+	for _, item := range res.(*workspaceDiagnosticReport).Items {
+		kinds[item.URI] = item.Kind
+		assert.Nil(t, item.Version)
+	}
+	assert.Equal(t, map[uri.URI]documentDiagnosticReportKind{
+		aURI:      documentDiagnosticReportKindUnchanged,
+		bURI:      documentDiagnosticReportKindFull,
+		brokenURI: documentDiagnosticReportKindFull,
+	}, kinds)
+
+	// struct B is renamed, diagnostics of file including it are changed
+	err = srv.DidOpen(ctx, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{
+			URI:        bURI,
+			LanguageID: "thrift",
+			Version:    1,
+			Text:       "struct B2 {\n    1: string id\n}\n",
+		},
+	})
+	assert.NoError(t, err)
+	report = documentDiagnostic(aURI, aResultID)
+	assert.Equal(t, documentDiagnosticReportKindFull, report.Kind)
+	assert.NotEqual(t, aResultID, report.ResultID)
+	if assert.Len(t, *report.Items, 1) {
+		assert.Equal(t, "field type doesn't exist", (*report.Items)[0].Message)
+	}
+
+	// diagnostics aren't published when client pulls them
+	assert.Empty(t, client.takeDiagnostics())
+}
+
+func Test_PullDiagnosticsRefresh(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.thrift": "include \"b.thrift\"\n\nstruct A {\n    1: b.B b\n}\n",
+		"b.thrift": "struct B {\n    1: string id\n}\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	bURI := uri.File(filepath.Join(dir, "b.thrift"))
+
+	client := &recordClient{}
+	srv := NewServer(cache.New(&memoize.Store{}), client)
+	ctx := context.WithValue(context.TODO(), pullDiagnosticsKey{}, true)
+	ctx = context.WithValue(ctx, diagnosticRefreshKey{}, true)
+	_, err := srv.Initialize(ctx, &protocol.InitializeParams{RootURI: uri.File(dir)})
+	assert.NoError(t, err)
+	assert.NoError(t, srv.Initialized(ctx, &protocol.InitializedParams{}))
+	srv.background.Wait()
+	assert.Empty(t, client.takeCalls())
+
+	// client is asked to pull diagnostics again after files are changed on disk
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.thrift"), []byte("struct B2 {\n}\n"), 0644))
+	err = srv.DidChangeWatchedFiles(ctx, &protocol.DidChangeWatchedFilesParams{
+		Changes: []*protocol.FileEvent{
+			{URI: bURI, Type: protocol.FileChangeTypeChanged},
+		},
+	})
+	assert.NoError(t, err)
+	srv.background.Wait()
+	assert.Equal(t, []string{methodWorkspaceDiagnosticRefresh}, client.takeCalls())
+
+	// diagnostics of deleted files aren't published
+	assert.NoError(t, os.Remove(filepath.Join(dir, "b.thrift")))
+	err = srv.DidChangeWatchedFiles(ctx, &protocol.DidChangeWatchedFilesParams{
+		Changes: []*protocol.FileEvent{
+			{URI: bURI, Type: protocol.FileChangeTypeDeleted},
+		},
+	})
+	assert.NoError(t, err)
+	srv.background.Wait()
+	assert.Equal(t, []string{methodWorkspaceDiagnosticRefresh}, client.takeCalls())
+
+	// rules of diagnostics may be changed by project config
+	configPath := filepath.Join(dir, ProjectConfigFile)
+	assert.NoError(t, os.WriteFile(configPath, []byte("rules:\n  CycleCheck:\n    disabled: true\n"), 0644))
+	err = srv.DidChangeWatchedFiles(ctx, &protocol.DidChangeWatchedFilesParams{
+		Changes: []*protocol.FileEvent{
+			{URI: uri.File(configPath), Type: protocol.FileChangeTypeCreated},
+		},
+	})
+	assert.NoError(t, err)
+	srv.background.Wait()
+	assert.Equal(t, []string{methodWorkspaceDiagnosticRefresh}, client.takeCalls())
+
+	assert.Empty(t, client.takeDiagnostics())
+}
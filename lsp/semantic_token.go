@@ -0,0 +1,136 @@
+package lsp
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/joyme123/thrift-ls/lsp/semantictoken"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// semanticTokensResults records the latest full result of each document,
+// it is used to compute delta for SemanticTokensFullDelta
+type semanticTokensResults struct {
+	mu      sync.Mutex
+	nextID  uint64
+	results map[uri.URI]*semanticTokensResult
+}
+
+type semanticTokensResult struct {
+	id   string
+	data []uint32
+}
+
+func newSemanticTokensResults() *semanticTokensResults {
+	return &semanticTokensResults{
+		results: make(map[uri.URI]*semanticTokensResult),
+	}
+}
+
+// save records data as latest result of file, and returns its result id
+func (r *semanticTokensResults) save(file uri.URI, data []uint32) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	id := strconv.FormatUint(r.nextID, 10)
+	r.results[file] = &semanticTokensResult{
+		id:   id,
+		data: data,
+	}
+
+	return id
+}
+
+func (r *semanticTokensResults) get(file uri.URI, id string) ([]uint32, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, ok := r.results[file]
+	if !ok || res.id != id {
+		return nil, false
+	}
+
+	return res.data, true
+}
+
+// forget drops result of file, it's called when file is closed
+func (r *semanticTokensResults) forget(file uri.URI) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.results, file)
+}
+
+func (s *Server) semanticTokensFull(ctx context.Context, params *protocol.SemanticTokensParams) (*protocol.SemanticTokens, error) {
+	file := params.TextDocument.URI
+	data, err := s.semanticTokensData(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &protocol.SemanticTokens{
+		ResultID: s.semanticTokens.save(file, data),
+		Data:     data,
+	}, nil
+}
+
+func (s *Server) semanticTokensFullDelta(ctx context.Context, params *protocol.SemanticTokensDeltaParams) (interface{}, error) {
+	file := params.TextDocument.URI
+	data, err := s.semanticTokensData(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	prev, ok := s.semanticTokens.get(file, params.PreviousResultID)
+	id := s.semanticTokens.save(file, data)
+	if !ok {
+		// previous result is unknown, fallback to full result
+		return &protocol.SemanticTokens{
+			ResultID: id,
+			Data:     data,
+		}, nil
+	}
+
+	return &protocol.SemanticTokensDelta{
+		ResultID: id,
+		Edits:    semantictoken.Diff(prev, data),
+	}, nil
+}
+
+func (s *Server) semanticTokensRange(ctx context.Context, params *protocol.SemanticTokensRangeParams) (*protocol.SemanticTokens, error) {
+	file := params.TextDocument.URI
+	view, err := s.session.ViewOf(file)
+	if err != nil {
+		return nil, err
+	}
+	ss, release := view.Snapshot()
+	defer release()
+
+	tokens, err := semantictoken.Tokens(ctx, ss, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &protocol.SemanticTokens{
+		Data: semantictoken.Encode(semantictoken.InRange(tokens, params.Range)),
+	}, nil
+}
+
+func (s *Server) semanticTokensData(ctx context.Context, file uri.URI) ([]uint32, error) {
+	view, err := s.session.ViewOf(file)
+	if err != nil {
+		return nil, err
+	}
+	ss, release := view.Snapshot()
+	defer release()
+
+	tokens, err := semantictoken.Tokens(ctx, ss, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return semantictoken.Encode(tokens), nil
+}
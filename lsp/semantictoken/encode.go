@@ -0,0 +1,73 @@
+package semantictoken
+
+import (
+	"go.lsp.dev/protocol"
+)
+
+// Encode encodes tokens into relative format. see:
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_semanticTokens
+// tokens must be sorted by position
+func Encode(tokens []*Token) []uint32 {
+	data := make([]uint32, 0, len(tokens)*5)
+	var prevLine, prevChar uint32
+	for _, token := range tokens {
+		deltaLine := token.Line - prevLine
+		deltaChar := token.Character
+		if deltaLine == 0 {
+			deltaChar = token.Character - prevChar
+		}
+		data = append(data, deltaLine, deltaChar, token.Length, uint32(token.Type), uint32(token.Modifiers))
+		prevLine, prevChar = token.Line, token.Character
+	}
+
+	return data
+}
+
+// InRange returns tokens which overlap with rng
+func InRange(tokens []*Token, rng protocol.Range) []*Token {
+	res := make([]*Token, 0)
+	for _, token := range tokens {
+		start := protocol.Position{Line: token.Line, Character: token.Character}
+		end := protocol.Position{Line: token.Line, Character: token.Character + token.Length}
+		if !less(rng.Start, end) || !less(start, rng.End) {
+			continue
+		}
+		res = append(res, token)
+	}
+
+	return res
+}
+
+func less(a, b protocol.Position) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Character < b.Character
+}
+
+// Diff computes edits which transform prev into cur. Only one edit is returned,
+// which replaces the elements between the common prefix and common suffix
+func Diff(prev, cur []uint32) []protocol.SemanticTokensEdit {
+	prefix := 0
+	for prefix < len(prev) && prefix < len(cur) && prev[prefix] == cur[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(prev)-prefix && suffix < len(cur)-prefix &&
+		prev[len(prev)-1-suffix] == cur[len(cur)-1-suffix] {
+		suffix++
+	}
+
+	if prefix == len(prev) && prefix == len(cur) {
+		return []protocol.SemanticTokensEdit{}
+	}
+
+	return []protocol.SemanticTokensEdit{
+		{
+			Start:       uint32(prefix),
+			DeleteCount: uint32(len(prev) - prefix - suffix),
+			Data:        cur[prefix : len(cur)-suffix],
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package semantictoken
+
+import "go.lsp.dev/protocol"
+
+// TokenType is the index of a token type in Legend.TokenTypes
+type TokenType uint32
+
+const (
+	TokenTypeKeyword TokenType = iota
+	TokenTypeStruct
+	TokenTypeEnum
+	TokenTypeUnion
+	TokenTypeException
+	TokenTypeTypedef
+	TokenTypeService
+	TokenTypeFunction
+	TokenTypeParameter
+	TokenTypeField
+	TokenTypeEnumMember
+	TokenTypeConst
+	TokenTypeNamespace
+	TokenTypeAnnotation
+	TokenTypeComment
+	TokenTypeString
+	TokenTypeNumber
+)
+
+// TokenModifier is the bit of a token modifier in Legend.TokenModifiers
+type TokenModifier uint32
+
+const (
+	TokenModifierDeclaration TokenModifier = 1 << iota
+	TokenModifierDeprecated
+	TokenModifierReadonly
+	TokenModifierDefaultLibrary
+)
+
+// Legend is advertised in server capabilities. The order of TokenTypes and
+// TokenModifiers must match TokenType and TokenModifier.
+//
+// thrift has some kinds of definition which lsp doesn't define a token type for,
+// union and exception are reported as custom types. typedef and basic types use
+// `type`, basic types are marked with defaultLibrary. include names and namespaces
+// both use `namespace`.
+var Legend = protocol.SemanticTokensLegend{
+	TokenTypes: []protocol.SemanticTokenTypes{
+		protocol.SemanticTokenKeyword,
+		protocol.SemanticTokenStruct,
+		protocol.SemanticTokenEnum,
+		protocol.SemanticTokenTypes("union"),
+		protocol.SemanticTokenTypes("exception"),
+		protocol.SemanticTokenType,
+		protocol.SemanticTokenInterface,
+		protocol.SemanticTokenMethod,
+		protocol.SemanticTokenParameter,
+		protocol.SemanticTokenProperty,
+		protocol.SemanticTokenEnumMember,
+		protocol.SemanticTokenVariable,
+		protocol.SemanticTokenNamespace,
+		protocol.SemanticTokenMacro,
+		protocol.SemanticTokenComment,
+		protocol.SemanticTokenString,
+		protocol.SemanticTokenNumber,
+	},
+	TokenModifiers: []protocol.SemanticTokenModifiers{
+		protocol.SemanticTokenModifierDeclaration,
+		protocol.SemanticTokenModifierDeprecated,
+		protocol.SemanticTokenModifierReadonly,
+		protocol.SemanticTokenModifierDefaultLibrary,
+	},
+}
@@ -0,0 +1,594 @@
+package semantictoken
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/codejump"
+	"github.com/joyme123/thrift-ls/lsp/lsputils"
+	"github.com/joyme123/thrift-ls/parser"
+	"github.com/joyme123/thrift-ls/utils"
+	"go.lsp.dev/uri"
+)
+
+// Token is a semantic token with absolute position. Line and Character are 0-based
+type Token struct {
+	Line      uint32
+	Character uint32
+	Length    uint32
+	Type      TokenType
+	Modifiers TokenModifier
+}
+
+// Tokens returns all semantic tokens of file, sorted by position
+func Tokens(ctx context.Context, ss *cache.Snapshot, file uri.URI) ([]*Token, error) {
+	pf, err := ss.Parse(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	if pf.AST() == nil {
+		return nil, errors.New("parse ast failed")
+	}
+
+	fh, err := ss.ReadFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	content, err := fh.Content()
+	if err != nil {
+		return nil, err
+	}
+
+	b := &builder{
+		ctx:     ctx,
+		ss:      ss,
+		file:    file,
+		ast:     pf.AST(),
+		content: content,
+	}
+	b.document(pf.AST())
+
+	sort.SliceStable(b.tokens, func(i, j int) bool {
+		if b.tokens[i].Line != b.tokens[j].Line {
+			return b.tokens[i].Line < b.tokens[j].Line
+		}
+		return b.tokens[i].Character < b.tokens[j].Character
+	})
+
+	return b.tokens, nil
+}
+
+type builder struct {
+	ctx     context.Context
+	ss      *cache.Snapshot
+	file    uri.URI
+	ast     *parser.Document
+	content []byte
+
+	tokens []*Token
+}
+
+func (b *builder) document(doc *parser.Document) {
+	for _, include := range doc.Includes {
+		if include.BadNode {
+			continue
+		}
+		b.comments(include.Comments)
+		b.keyword(include.IncludeKeyword)
+		b.literal(include.Path)
+		b.comments(include.EndLineComments)
+	}
+
+	for _, include := range doc.CPPIncludes {
+		if include.BadNode {
+			continue
+		}
+		b.comments(include.Comments)
+		b.keyword(include.CPPIncludeKeyword)
+		b.literal(include.Path)
+		b.comments(include.EndLineComments)
+	}
+
+	for _, ns := range doc.Namespaces {
+		if ns.BadNode {
+			continue
+		}
+		b.comments(ns.Comments)
+		b.keyword(ns.NamespaceKeyword)
+		if ns.Language != nil {
+			b.identifier(&ns.Language.Identifier, TokenTypeKeyword, 0)
+		}
+		b.identifier(ns.Name, TokenTypeNamespace, TokenModifierDeclaration)
+		b.annotations(ns.Annotations)
+		b.comments(ns.EndLineComments)
+	}
+
+	for _, cst := range doc.Consts {
+		if cst.BadNode {
+			continue
+		}
+		b.comments(cst.Comments)
+		b.keyword(cst.ConstKeyword)
+		b.fieldType(cst.ConstType)
+		b.identifier(cst.Name, TokenTypeConst, TokenModifierDeclaration|TokenModifierReadonly|deprecated(cst.Annotations, cst.Comments))
+		b.keyword(cst.EqualKeyword)
+		b.constValue(cst.Value)
+		b.keyword(cst.ListSeparatorKeyword)
+		b.annotations(cst.Annotations)
+		b.comments(cst.EndLineComments)
+	}
+
+	for _, td := range doc.Typedefs {
+		if td.BadNode {
+			continue
+		}
+		b.comments(td.Comments)
+		b.keyword(td.TypedefKeyword)
+		b.fieldType(td.T)
+		b.identifier(td.Alias, TokenTypeTypedef, TokenModifierDeclaration|deprecated(td.Annotations, td.Comments))
+		b.annotations(td.Annotations)
+		b.comments(td.EndLineComments)
+	}
+
+	for _, enum := range doc.Enums {
+		if enum.BadNode {
+			continue
+		}
+		b.comments(enum.Comments)
+		b.keyword(enum.EnumKeyword)
+		b.identifier(enum.Name, TokenTypeEnum, TokenModifierDeclaration|deprecated(enum.Annotations, enum.Comments))
+		b.keyword(enum.LCurKeyword)
+		for _, value := range enum.Values {
+			if value.BadNode {
+				continue
+			}
+			b.comments(value.Comments)
+			b.identifier(value.Name, TokenTypeEnumMember, TokenModifierDeclaration|deprecated(value.Annotations, value.Comments))
+			b.keyword(value.EqualKeyword)
+			b.constValue(value.ValueNode)
+			b.annotations(value.Annotations)
+			b.keyword(value.ListSeparatorKeyword)
+			b.comments(value.EndLineComments)
+		}
+		b.keyword(enum.RCurKeyword)
+		b.annotations(enum.Annotations)
+		b.comments(enum.EndLineComments)
+	}
+
+	for _, svc := range doc.Services {
+		if svc.BadNode {
+			continue
+		}
+		b.comments(svc.Comments)
+		b.keyword(svc.ServiceKeyword)
+		b.identifier(svc.Name, TokenTypeService, TokenModifierDeclaration|deprecated(svc.Annotations, svc.Comments))
+		b.keyword(svc.ExtendsKeyword)
+		b.serviceReference(svc.Extends)
+		b.keyword(svc.LCurKeyword)
+		for _, fn := range svc.Functions {
+			b.function(fn)
+		}
+		b.keyword(svc.RCurKeyword)
+		b.annotations(svc.Annotations)
+		b.comments(svc.EndLineComments)
+	}
+
+	for _, st := range doc.Structs {
+		if st.BadNode {
+			continue
+		}
+		b.comments(st.Comments)
+		b.keyword(st.StructKeyword)
+		b.identifier(st.Identifier, TokenTypeStruct, TokenModifierDeclaration|deprecated(st.Annotations, st.Comments))
+		b.keyword(st.LCurKeyword)
+		b.fields(st.Fields, TokenTypeField)
+		b.keyword(st.RCurKeyword)
+		b.annotations(st.Annotations)
+		b.comments(st.EndLineComments)
+	}
+
+	for _, union := range doc.Unions {
+		if union.BadNode {
+			continue
+		}
+		b.comments(union.Comments)
+		b.keyword(union.UnionKeyword)
+		b.identifier(union.Name, TokenTypeUnion, TokenModifierDeclaration|deprecated(union.Annotations, union.Comments))
+		b.keyword(union.LCurKeyword)
+		b.fields(union.Fields, TokenTypeField)
+		b.keyword(union.RCurKeyword)
+		b.annotations(union.Annotations)
+		b.comments(union.EndLineComments)
+	}
+
+	for _, excep := range doc.Exceptions {
+		if excep.BadNode {
+			continue
+		}
+		b.comments(excep.Comments)
+		b.keyword(excep.ExceptionKeyword)
+		b.identifier(excep.Name, TokenTypeException, TokenModifierDeclaration|deprecated(excep.Annotations, excep.Comments))
+		b.keyword(excep.LCurKeyword)
+		b.fields(excep.Fields, TokenTypeField)
+		b.keyword(excep.RCurKeyword)
+		b.annotations(excep.Annotations)
+		b.comments(excep.EndLineComments)
+	}
+
+	b.comments(doc.Comments)
+}
+
+func (b *builder) function(fn *parser.Function) {
+	if fn.BadNode {
+		return
+	}
+	b.comments(fn.Comments)
+	b.keyword(fn.Oneway)
+	b.keyword(fn.Void)
+	b.fieldType(fn.FunctionType)
+	b.identifier(fn.Name, TokenTypeFunction, TokenModifierDeclaration|deprecated(fn.Annotations, fn.Comments))
+	b.keyword(fn.LParKeyword)
+	b.fields(fn.Arguments, TokenTypeParameter)
+	b.keyword(fn.RParKeyword)
+	if fn.Throws != nil && !fn.Throws.BadNode {
+		b.keyword(fn.Throws.ThrowsKeyword)
+		b.keyword(fn.Throws.LParKeyword)
+		b.fields(fn.Throws.Fields, TokenTypeParameter)
+		b.keyword(fn.Throws.RParKeyword)
+	}
+	b.annotations(fn.Annotations)
+	b.keyword(fn.ListSeparatorKeyword)
+	b.comments(fn.EndLineComments)
+}
+
+func (b *builder) fields(fields []*parser.Field, typ TokenType) {
+	for _, field := range fields {
+		if field.BadNode {
+			continue
+		}
+		b.comments(field.Comments)
+		if field.Index != nil && !field.Index.BadNode {
+			b.comments(field.Index.Comments)
+			b.add(field.Index, TokenTypeNumber, 0)
+			b.keyword(field.Index.ColonKeyword)
+		}
+		b.keyword(field.RequiredKeyword)
+		b.fieldType(field.FieldType)
+		b.identifier(field.Identifier, typ, TokenModifierDeclaration|deprecated(field.Annotations, field.Comments))
+		b.keyword(field.EqualKeyword)
+		b.constValue(field.ConstValue)
+		b.annotations(field.Annotations)
+		b.keyword(field.ListSeparatorKeyword)
+		b.comments(field.EndLineComments)
+	}
+}
+
+func (b *builder) fieldType(ft *parser.FieldType) {
+	if ft == nil || ft.BadNode {
+		return
+	}
+
+	if ft.TypeName != nil && !ft.TypeName.BadNode {
+		b.comments(ft.TypeName.Comments)
+		b.typeName(ft.TypeName)
+	}
+	b.keyword(ft.LPointKeyword)
+	b.fieldType(ft.KeyType)
+	b.keyword(ft.CommaKeyword)
+	b.fieldType(ft.ValueType)
+	b.keyword(ft.RPointKeyword)
+	if ft.CppType != nil {
+		b.keyword(ft.CppType.CppTypeKeyword)
+		b.literal(ft.CppType.Literal)
+	}
+	b.annotations(ft.Annotations)
+}
+
+func (b *builder) typeName(tn *parser.TypeName) {
+	if codejump.IsBasicType(tn.Name) {
+		b.add(tn, TokenTypeTypedef, TokenModifierDefaultLibrary)
+		return
+	}
+
+	dstAst, include, name := b.resolve(tn.Name)
+	pos := tn.Pos()
+	if include != "" {
+		b.addPos(pos, include, TokenTypeNamespace, 0)
+		pos = moveInLine(pos, include+".")
+	}
+	if dstAst == nil {
+		return
+	}
+
+	if st := codejump.GetStructNode(dstAst, name); st != nil {
+		b.addPos(pos, name, TokenTypeStruct, deprecated(st.Annotations, st.Comments))
+	} else if enum := codejump.GetEnumNode(dstAst, name); enum != nil {
+		b.addPos(pos, name, TokenTypeEnum, deprecated(enum.Annotations, enum.Comments))
+	} else if union := codejump.GetUnionNode(dstAst, name); union != nil {
+		b.addPos(pos, name, TokenTypeUnion, deprecated(union.Annotations, union.Comments))
+	} else if excep := codejump.GetExceptionNode(dstAst, name); excep != nil {
+		b.addPos(pos, name, TokenTypeException, deprecated(excep.Annotations, excep.Comments))
+	} else if td := codejump.GetTypedefNode(dstAst, name); td != nil {
+		b.addPos(pos, name, TokenTypeTypedef, deprecated(td.Annotations, td.Comments))
+	}
+}
+
+func (b *builder) serviceReference(id *parser.Identifier) {
+	if id == nil || id.BadNode || id.Name == nil {
+		return
+	}
+	b.comments(id.Comments)
+
+	dstAst, include, name := b.resolve(id.Name.Text)
+	pos := id.Name.Pos()
+	if include != "" {
+		b.addPos(pos, include, TokenTypeNamespace, 0)
+		pos = moveInLine(pos, include+".")
+	}
+	if dstAst == nil {
+		return
+	}
+
+	if svc := codejump.GetServiceNode(dstAst, name); svc != nil {
+		b.addPos(pos, name, TokenTypeService, deprecated(svc.Annotations, svc.Comments))
+	}
+}
+
+func (b *builder) constValue(cv *parser.ConstValue) {
+	if cv == nil || cv.BadNode {
+		return
+	}
+
+	b.comments(cv.Comments)
+	switch cv.TypeName {
+	case "string":
+		if literal, ok := cv.Value.(*parser.Literal); ok {
+			b.literal(literal)
+		}
+	case "i64", "double":
+		b.add(cv, TokenTypeNumber, 0)
+	case "identifier":
+		b.constIdentifier(cv)
+	case "list":
+		b.keyword(cv.LBrkKeyword)
+		if values, ok := cv.Value.([]*parser.ConstValue); ok {
+			for i := range values {
+				b.constValue(values[i])
+			}
+		}
+		b.keyword(cv.RBrkKeyword)
+	case "map":
+		b.keyword(cv.LCurKeyword)
+		if values, ok := cv.Value.([]*parser.ConstValue); ok {
+			for i := range values {
+				b.constValue(values[i])
+			}
+		}
+		b.keyword(cv.RCurKeyword)
+	case "pair":
+		if key, ok := cv.Key.(*parser.ConstValue); ok {
+			b.constValue(key)
+		}
+		b.keyword(cv.ColonKeyword)
+		if value, ok := cv.Value.(*parser.ConstValue); ok {
+			b.constValue(value)
+		}
+	}
+	b.keyword(cv.ListSeparatorKeyword)
+}
+
+// constIdentifier handles const value references, they can be:
+// CONST, include.CONST, Enum.VALUE, include.Enum.VALUE
+func (b *builder) constIdentifier(cv *parser.ConstValue) {
+	loc, ok := lsputils.ConstValueIdentifierLocation(b.content, cv)
+	if !ok {
+		return
+	}
+	text := cv.Value.(string)
+	pos := loc.StartPos
+
+	dstAst, include, name := b.resolve(text)
+	if include != "" {
+		b.addPos(pos, include, TokenTypeNamespace, 0)
+		pos = moveInLine(pos, include+".")
+	}
+
+	enumName, valueName, found := strings.Cut(name, ".")
+	if !found {
+		var mods TokenModifier = TokenModifierReadonly
+		if dstAst != nil {
+			if cst := codejump.GetConstNode(dstAst, name); cst != nil {
+				mods |= deprecated(cst.Annotations, cst.Comments)
+			}
+		}
+		b.addPos(pos, name, TokenTypeConst, mods)
+		return
+	}
+
+	var enumMods, valueMods TokenModifier
+	if dstAst != nil {
+		if enum := codejump.GetEnumNode(dstAst, enumName); enum != nil {
+			enumMods = deprecated(enum.Annotations, enum.Comments)
+			for _, value := range enum.Values {
+				if value.Name != nil && value.Name.Name != nil && value.Name.Name.Text == valueName {
+					valueMods = deprecated(value.Annotations, value.Comments)
+				}
+			}
+		}
+	}
+	b.addPos(pos, enumName, TokenTypeEnum, enumMods)
+	b.addPos(moveInLine(pos, enumName+"."), valueName, TokenTypeEnumMember, valueMods)
+}
+
+// resolve splits include prefix from name and returns the ast where name is defined.
+// include is empty if name isn't prefixed by an include name
+func (b *builder) resolve(fullName string) (ast *parser.Document, include string, name string) {
+	include, name, found := strings.Cut(fullName, ".")
+	if !found {
+		return b.ast, "", fullName
+	}
+
+	path := lsputils.GetIncludePath(b.ast, include)
+	if path == "" {
+		// maybe Enum.VALUE in current file
+		return b.ast, "", fullName
+	}
+
+	pf, err := b.ss.Parse(b.ctx, lsputils.IncludeURI(b.file, path, b.ss.IncludeDirs()...))
+	if err != nil {
+		return nil, include, name
+	}
+
+	return pf.AST(), include, name
+}
+
+func (b *builder) annotations(annos *parser.Annotations) {
+	if annos == nil || annos.BadNode {
+		return
+	}
+	b.keyword(annos.LParKeyword)
+	for _, anno := range annos.Annotations {
+		if anno.BadNode {
+			continue
+		}
+		b.identifier(anno.Identifier, TokenTypeAnnotation, 0)
+		b.keyword(anno.EqualKeyword)
+		b.literal(anno.Value)
+		b.keyword(anno.ListSeparatorKeyword)
+	}
+	b.keyword(annos.RParKeyword)
+}
+
+func (b *builder) identifier(id *parser.Identifier, typ TokenType, mods TokenModifier) {
+	if id == nil || id.BadNode || id.Name == nil {
+		return
+	}
+	b.comments(id.Comments)
+	b.add(id.Name, typ, mods)
+}
+
+func (b *builder) literal(literal *parser.Literal) {
+	if literal == nil || literal.BadNode {
+		return
+	}
+	b.comments(literal.Comments)
+	if literal.Value == nil || literal.Value.BadNode {
+		return
+	}
+
+	// quotes are not included in literal value
+	pos := literal.Value.Pos()
+	pos.Col -= 1
+	pos.Offset -= 1
+	b.addPos(pos, literal.Quote+literal.Value.Text+literal.Quote, TokenTypeString, 0)
+}
+
+type keywordNode interface {
+	GetKeyword() *parser.Keyword
+}
+
+func (b *builder) keyword(node parser.Node) {
+	if utils.IsNil(node) {
+		return
+	}
+	kn, ok := node.(keywordNode)
+	if !ok {
+		return
+	}
+	kw := kn.GetKeyword()
+	b.comments(kw.Comments)
+	if kw.BadNode || kw.Literal == nil || kw.Literal.BadNode {
+		return
+	}
+
+	// punctuations like '{', ',' are not highlighted
+	r, _ := utf8.DecodeRuneInString(kw.Literal.Text)
+	if !unicode.IsLetter(r) {
+		return
+	}
+	b.add(kw.Literal, TokenTypeKeyword, 0)
+}
+
+func (b *builder) comments(comments []*parser.Comment) {
+	for _, comment := range comments {
+		if comment == nil || comment.BadNode {
+			continue
+		}
+		// multi line comment is split into tokens by line, because not all clients
+		// support multiline tokens
+		pos := comment.Pos()
+		for i, line := range strings.Split(comment.Text, "\n") {
+			line = strings.TrimSuffix(line, "\r")
+			if i > 0 {
+				pos = parser.Position{Line: pos.Line + 1, Col: 1}
+			}
+			b.addPos(pos, line, TokenTypeComment, 0)
+		}
+	}
+}
+
+func (b *builder) add(node parser.Node, typ TokenType, mods TokenModifier) {
+	if utils.IsNil(node) {
+		return
+	}
+	start, end := node.Pos(), node.End()
+	if start.Invalid() || start.Line != end.Line || end.Col <= start.Col {
+		return
+	}
+	b.tokens = append(b.tokens, &Token{
+		Line:      uint32(start.Line - 1),
+		Character: uint32(start.Col - 1),
+		Length:    uint32(end.Col - start.Col),
+		Type:      typ,
+		Modifiers: mods,
+	})
+}
+
+func (b *builder) addPos(pos parser.Position, text string, typ TokenType, mods TokenModifier) {
+	length := utf8.RuneCountInString(text)
+	if pos.Line < 1 || pos.Col < 1 || length == 0 {
+		return
+	}
+	b.tokens = append(b.tokens, &Token{
+		Line:      uint32(pos.Line - 1),
+		Character: uint32(pos.Col - 1),
+		Length:    uint32(length),
+		Type:      typ,
+		Modifiers: mods,
+	})
+}
+
+func moveInLine(pos parser.Position, text string) parser.Position {
+	pos.Col += utf8.RuneCountInString(text)
+	pos.Offset += len(text)
+	return pos
+}
+
+// deprecated returns TokenModifierDeprecated if definition is annotated by `deprecated`
+// or its doc comments contains `@deprecated`
+func deprecated(annos *parser.Annotations, comments []*parser.Comment) TokenModifier {
+	if annos != nil {
+		for _, anno := range annos.Annotations {
+			if anno.BadNode || anno.Identifier == nil || anno.Identifier.Name == nil {
+				continue
+			}
+			if strings.EqualFold(anno.Identifier.Name.Text, "deprecated") {
+				return TokenModifierDeprecated
+			}
+		}
+	}
+
+	for _, comment := range comments {
+		if comment != nil && strings.Contains(comment.Text, "@deprecated") {
+			return TokenModifierDeprecated
+		}
+	}
+
+	return 0
+}
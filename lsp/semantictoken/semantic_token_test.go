@@ -0,0 +1,164 @@
+package semantictoken
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestTokens(t *testing.T) {
+	file1 := `enum Status {
+  OK = 1, // ok
+  FAIL (deprecated="1")
+}
+struct User {
+  1: required string name,
+  2: optional Status st = Status.OK
+}`
+
+	file2 := `include "user.thrift"
+service Demo {
+  user.User Get(1: i64 id) throws (1: user.User err)
+}`
+
+	ss := cache.BuildSnapshotForTest([]*cache.FileChange{
+		{
+			URI:     "file:///tmp/user.thrift",
+			Version: 0,
+			Content: []byte(file1),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+		{
+			URI:     "file:///tmp/api.thrift",
+			Version: 0,
+			Content: []byte(file2),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	})
+
+	tests := []struct {
+		name      string
+		file      uri.URI
+		want      []*Token
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name: "enum and struct",
+			file: "file:///tmp/user.thrift",
+			want: []*Token{
+				{Line: 0, Character: 0, Length: 4, Type: TokenTypeKeyword},
+				{Line: 0, Character: 5, Length: 6, Type: TokenTypeEnum, Modifiers: TokenModifierDeclaration},
+				{Line: 1, Character: 2, Length: 2, Type: TokenTypeEnumMember, Modifiers: TokenModifierDeclaration},
+				{Line: 1, Character: 7, Length: 1, Type: TokenTypeNumber},
+				{Line: 1, Character: 10, Length: 5, Type: TokenTypeComment},
+				{Line: 2, Character: 2, Length: 4, Type: TokenTypeEnumMember, Modifiers: TokenModifierDeclaration | TokenModifierDeprecated},
+				{Line: 2, Character: 8, Length: 10, Type: TokenTypeAnnotation},
+				{Line: 2, Character: 19, Length: 3, Type: TokenTypeString},
+				{Line: 4, Character: 0, Length: 6, Type: TokenTypeKeyword},
+				{Line: 4, Character: 7, Length: 4, Type: TokenTypeStruct, Modifiers: TokenModifierDeclaration},
+				{Line: 5, Character: 2, Length: 1, Type: TokenTypeNumber},
+				{Line: 5, Character: 5, Length: 8, Type: TokenTypeKeyword},
+				{Line: 5, Character: 14, Length: 6, Type: TokenTypeTypedef, Modifiers: TokenModifierDefaultLibrary},
+				{Line: 5, Character: 21, Length: 4, Type: TokenTypeField, Modifiers: TokenModifierDeclaration},
+				{Line: 6, Character: 2, Length: 1, Type: TokenTypeNumber},
+				{Line: 6, Character: 5, Length: 8, Type: TokenTypeKeyword},
+				{Line: 6, Character: 14, Length: 6, Type: TokenTypeEnum},
+				{Line: 6, Character: 21, Length: 2, Type: TokenTypeField, Modifiers: TokenModifierDeclaration},
+				{Line: 6, Character: 26, Length: 6, Type: TokenTypeEnum},
+				{Line: 6, Character: 33, Length: 2, Type: TokenTypeEnumMember},
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "service with include",
+			file: "file:///tmp/api.thrift",
+			want: []*Token{
+				{Line: 0, Character: 0, Length: 7, Type: TokenTypeKeyword},
+				{Line: 0, Character: 8, Length: 13, Type: TokenTypeString},
+				{Line: 1, Character: 0, Length: 7, Type: TokenTypeKeyword},
+				{Line: 1, Character: 8, Length: 4, Type: TokenTypeService, Modifiers: TokenModifierDeclaration},
+				{Line: 2, Character: 2, Length: 4, Type: TokenTypeNamespace},
+				{Line: 2, Character: 7, Length: 4, Type: TokenTypeStruct},
+				{Line: 2, Character: 12, Length: 3, Type: TokenTypeFunction, Modifiers: TokenModifierDeclaration},
+				{Line: 2, Character: 16, Length: 1, Type: TokenTypeNumber},
+				{Line: 2, Character: 19, Length: 3, Type: TokenTypeTypedef, Modifiers: TokenModifierDefaultLibrary},
+				{Line: 2, Character: 23, Length: 2, Type: TokenTypeParameter, Modifiers: TokenModifierDeclaration},
+				{Line: 2, Character: 27, Length: 6, Type: TokenTypeKeyword},
+				{Line: 2, Character: 35, Length: 1, Type: TokenTypeNumber},
+				{Line: 2, Character: 38, Length: 4, Type: TokenTypeNamespace},
+				{Line: 2, Character: 43, Length: 4, Type: TokenTypeStruct},
+				{Line: 2, Character: 48, Length: 3, Type: TokenTypeParameter, Modifiers: TokenModifierDeclaration},
+			},
+			assertion: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Tokens(context.TODO(), ss, tt.file)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tokens := []*Token{
+		{Line: 0, Character: 0, Length: 6, Type: TokenTypeKeyword},
+		{Line: 0, Character: 7, Length: 4, Type: TokenTypeStruct, Modifiers: TokenModifierDeclaration},
+		{Line: 2, Character: 2, Length: 1, Type: TokenTypeNumber},
+	}
+
+	assert.Equal(t, []uint32{
+		0, 0, 6, uint32(TokenTypeKeyword), 0,
+		0, 7, 4, uint32(TokenTypeStruct), uint32(TokenModifierDeclaration),
+		2, 2, 1, uint32(TokenTypeNumber), 0,
+	}, Encode(tokens))
+
+	assert.Equal(t, tokens[1:2], InRange(tokens, protocol.Range{
+		Start: protocol.Position{Line: 0, Character: 6},
+		End:   protocol.Position{Line: 1, Character: 0},
+	}))
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		prev []uint32
+		cur  []uint32
+		want []protocol.SemanticTokensEdit
+	}{
+		{
+			name: "same",
+			prev: []uint32{1, 2, 3},
+			cur:  []uint32{1, 2, 3},
+			want: []protocol.SemanticTokensEdit{},
+		},
+		{
+			name: "insert",
+			prev: []uint32{1, 2, 3},
+			cur:  []uint32{1, 2, 4, 5, 3},
+			want: []protocol.SemanticTokensEdit{{Start: 2, DeleteCount: 0, Data: []uint32{4, 5}}},
+		},
+		{
+			name: "delete",
+			prev: []uint32{1, 2, 4, 5, 3},
+			cur:  []uint32{1, 3},
+			want: []protocol.SemanticTokensEdit{{Start: 1, DeleteCount: 3, Data: []uint32{}}},
+		},
+		{
+			name: "replace",
+			prev: []uint32{1, 2, 3},
+			cur:  []uint32{1, 7, 3},
+			want: []protocol.SemanticTokensEdit{{Start: 1, DeleteCount: 1, Data: []uint32{7}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Diff(tt.prev, tt.cur))
+		})
+	}
+}
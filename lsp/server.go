@@ -2,10 +2,13 @@ package lsp
 
 import (
 	"context"
+	"sync"
 
 	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/symbols"
 	log "github.com/sirupsen/logrus"
 	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
 )
 
 type Server struct {
@@ -13,14 +16,57 @@ type Server struct {
 	session *cache.Session
 
 	client protocol.Client
+	// watchFilesSupported reports whether client supports to register file watchers dynamically
+	watchFilesSupported bool
+	// workDoneProgressSupported reports whether client supports progress created by server
+	workDoneProgressSupported bool
+	// pullDiagnostics reports whether diagnostics are pulled by client instead of published by server
+	pullDiagnostics bool
+	// diagnosticRefreshSupported reports whether client supports to be asked to pull diagnostics again
+	diagnosticRefreshSupported bool
+	// configMu guards initOptions and configs
+	configMu sync.Mutex
+	// initOptions are options from initialize request
+	initOptions InitializationOptions
+	// configs caches project config of workspace folders
+	configs map[string]*ProjectConfig
+
+	// openMu guards openFiles
+	openMu sync.Mutex
+	// openFiles records documents opened in editor, their content is owned by client
+	openFiles map[uri.URI]bool
+
+	semanticTokens   *semanticTokensResults
+	workspaceSymbols *symbols.WorkspaceIndex
+
+	// dependentDiagnostics diagnoses files including changed files
+	dependentDiagnostics *filesDebouncer
+
+	// background tracks jobs running in background, such as diagnostics of workspace
+	background sync.WaitGroup
 }
 
 func NewServer(c *cache.Cache, client protocol.Client) *Server {
-	return &Server{
-		cache:   c,
-		session: cache.NewSession(c),
-		client:  client,
+	s := &Server{
+		cache:            c,
+		session:          cache.NewSession(c),
+		client:           client,
+		openFiles:        make(map[uri.URI]bool),
+		semanticTokens:   newSemanticTokensResults(),
+		workspaceSymbols: symbols.NewWorkspaceIndex(),
 	}
+	s.dependentDiagnostics = newFilesDebouncer(dependentDiagnosticsDelay, s.diagnosticFiles)
+
+	return s
+}
+
+// runInBackground runs fn in a new goroutine, it's tracked by background
+func (s *Server) runInBackground(fn func()) {
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		fn()
+	}()
 }
 
 func (s *Server) Initialize(ctx context.Context, params *protocol.InitializeParams) (result *protocol.InitializeResult, err error) {
@@ -30,7 +76,7 @@ func (s *Server) Initialize(ctx context.Context, params *protocol.InitializePara
 }
 
 func (s *Server) Initialized(ctx context.Context, params *protocol.InitializedParams) (err error) {
-	return nil
+	return s.initialized(ctx, params)
 }
 
 func (s *Server) Shutdown(ctx context.Context) (err error) {
@@ -54,7 +100,9 @@ func (s *Server) SetTrace(ctx context.Context, params *protocol.SetTraceParams)
 }
 
 func (s *Server) CodeAction(ctx context.Context, params *protocol.CodeActionParams) (result []protocol.CodeAction, err error) {
-	return nil, nil
+	log.Debugln("-----------CodeAction called-----------")
+	defer log.Debugln("-----------CodeAction finish-----------")
+	return s.codeAction(ctx, params)
 }
 
 func (s *Server) CodeLens(ctx context.Context, params *protocol.CodeLensParams) (result []protocol.CodeLens, err error) {
@@ -96,11 +144,14 @@ func (s *Server) DidChange(ctx context.Context, params *protocol.DidChangeTextDo
 }
 
 func (s *Server) DidChangeConfiguration(ctx context.Context, params *protocol.DidChangeConfigurationParams) (err error) {
-	return nil
+	log.Debugln("-----------DidChangeConfiguration called-----------")
+	return s.didChangeConfiguration(ctx, params)
 }
 
 func (s *Server) DidChangeWatchedFiles(ctx context.Context, params *protocol.DidChangeWatchedFilesParams) (err error) {
-	return nil
+	log.Debugln("-----------DidChangeWatchedFiles called-----------")
+	defer log.Debugln("-----------DidChangeWatchedFiles finish-----------")
+	return s.didChangeWatchedFiles(ctx, params)
 }
 
 func (s *Server) DidChangeWorkspaceFolders(ctx context.Context, params *protocol.DidChangeWorkspaceFoldersParams) (err error) {
@@ -108,7 +159,9 @@ func (s *Server) DidChangeWorkspaceFolders(ctx context.Context, params *protocol
 }
 
 func (s *Server) DidClose(ctx context.Context, params *protocol.DidCloseTextDocumentParams) (err error) {
-	return nil
+	log.Debugln("-----------DidClose called-----------")
+	defer log.Debugln("-----------DidClose finish-----------")
+	return s.didClose(ctx, params)
 }
 
 func (s *Server) DidOpen(ctx context.Context, params *protocol.DidOpenTextDocumentParams) (err error) {
@@ -126,11 +179,15 @@ func (s *Server) DocumentColor(ctx context.Context, params *protocol.DocumentCol
 }
 
 func (s *Server) DocumentHighlight(ctx context.Context, params *protocol.DocumentHighlightParams) (result []protocol.DocumentHighlight, err error) {
-	return nil, nil
+	log.Debugln("-----------DocumentHighlight called-----------")
+	defer log.Debugln("-----------DocumentHighlight finish-----------")
+	return s.documentHighlight(ctx, params)
 }
 
 func (s *Server) DocumentLink(ctx context.Context, params *protocol.DocumentLinkParams) (result []protocol.DocumentLink, err error) {
-	return nil, nil
+	log.Debugln("-----------DocumentLink called-----------")
+	defer log.Debugln("-----------DocumentLink finish-----------")
+	return s.documentLink(ctx, params)
 }
 
 func (s *Server) DocumentLinkResolve(ctx context.Context, params *protocol.DocumentLink) (result *protocol.DocumentLink, err error) {
@@ -148,7 +205,9 @@ func (s *Server) ExecuteCommand(ctx context.Context, params *protocol.ExecuteCom
 }
 
 func (s *Server) FoldingRanges(ctx context.Context, params *protocol.FoldingRangeParams) (result []protocol.FoldingRange, err error) {
-	return nil, nil
+	log.Debugln("-----------FoldingRanges called-----------")
+	defer log.Debugln("-----------FoldingRanges finish-----------")
+	return s.foldingRanges(ctx, params)
 }
 
 func (s *Server) Formatting(ctx context.Context, params *protocol.DocumentFormattingParams) (result []protocol.TextEdit, err error) {
@@ -168,7 +227,9 @@ func (s *Server) Implementation(ctx context.Context, params *protocol.Implementa
 }
 
 func (s *Server) OnTypeFormatting(ctx context.Context, params *protocol.DocumentOnTypeFormattingParams) (result []protocol.TextEdit, err error) {
-	return nil, nil
+	log.Debugln("-----------OnTypeFormatting called-----------")
+	defer log.Debugln("-----------OnTypeFormatting finish-----------")
+	return s.onTypeFormatting(ctx, params)
 }
 
 func (s *Server) PrepareRename(ctx context.Context, params *protocol.PrepareRenameParams) (result *protocol.Range, err error) {
@@ -178,7 +239,9 @@ func (s *Server) PrepareRename(ctx context.Context, params *protocol.PrepareRena
 }
 
 func (s *Server) RangeFormatting(ctx context.Context, params *protocol.DocumentRangeFormattingParams) (result []protocol.TextEdit, err error) {
-	return nil, nil
+	log.Debugln("-----------RangeFormatting called-----------")
+	defer log.Debugln("-----------RangeFormatting finish-----------")
+	return s.rangeFormatting(ctx, params)
 }
 
 func (s *Server) References(ctx context.Context, params *protocol.ReferenceParams) (result []protocol.Location, err error) {
@@ -194,11 +257,15 @@ func (s *Server) Rename(ctx context.Context, params *protocol.RenameParams) (res
 }
 
 func (s *Server) SignatureHelp(ctx context.Context, params *protocol.SignatureHelpParams) (result *protocol.SignatureHelp, err error) {
-	return nil, nil
+	log.Debugln("-----------SignatureHelp called-----------")
+	defer log.Debugln("-----------SignatureHelp finish-----------")
+	return s.signatureHelp(ctx, params)
 }
 
 func (s *Server) Symbols(ctx context.Context, params *protocol.WorkspaceSymbolParams) (result []protocol.SymbolInformation, err error) {
-	return nil, nil
+	log.Debugln("-----------Symbols called-----------")
+	defer log.Debugln("-----------Symbols finish-----------")
+	return s.workspaceSymbol(ctx, params)
 }
 
 func (s *Server) TypeDefinition(ctx context.Context, params *protocol.TypeDefinitionParams) (result []protocol.Location, err error) {
@@ -248,27 +315,39 @@ func (s *Server) CodeLensRefresh(ctx context.Context) (err error) {
 }
 
 func (s *Server) PrepareCallHierarchy(ctx context.Context, params *protocol.CallHierarchyPrepareParams) (result []protocol.CallHierarchyItem, err error) {
-	return nil, nil
+	log.Debugln("-----------PrepareCallHierarchy called-----------")
+	defer log.Debugln("-----------PrepareCallHierarchy finish-----------")
+	return s.prepareCallHierarchy(ctx, params)
 }
 
 func (s *Server) IncomingCalls(ctx context.Context, params *protocol.CallHierarchyIncomingCallsParams) (result []protocol.CallHierarchyIncomingCall, err error) {
-	return nil, nil
+	log.Debugln("-----------IncomingCalls called-----------")
+	defer log.Debugln("-----------IncomingCalls finish-----------")
+	return s.incomingCalls(ctx, params)
 }
 
 func (s *Server) OutgoingCalls(ctx context.Context, params *protocol.CallHierarchyOutgoingCallsParams) (result []protocol.CallHierarchyOutgoingCall, err error) {
-	return nil, nil
+	log.Debugln("-----------OutgoingCalls called-----------")
+	defer log.Debugln("-----------OutgoingCalls finish-----------")
+	return s.outgoingCalls(ctx, params)
 }
 
 func (s *Server) SemanticTokensFull(ctx context.Context, params *protocol.SemanticTokensParams) (result *protocol.SemanticTokens, err error) {
-	return nil, nil
+	log.Debugln("-----------SemanticTokensFull called-----------")
+	defer log.Debugln("-----------SemanticTokensFull finish-----------")
+	return s.semanticTokensFull(ctx, params)
 }
 
 func (s *Server) SemanticTokensFullDelta(ctx context.Context, params *protocol.SemanticTokensDeltaParams) (result interface{}, err error) {
-	return nil, nil
+	log.Debugln("-----------SemanticTokensFullDelta called-----------")
+	defer log.Debugln("-----------SemanticTokensFullDelta finish-----------")
+	return s.semanticTokensFullDelta(ctx, params)
 }
 
 func (s *Server) SemanticTokensRange(ctx context.Context, params *protocol.SemanticTokensRangeParams) (result *protocol.SemanticTokens, err error) {
-	return nil, nil
+	log.Debugln("-----------SemanticTokensRange called-----------")
+	defer log.Debugln("-----------SemanticTokensRange finish-----------")
+	return s.semanticTokensRange(ctx, params)
 }
 
 func (s *Server) SemanticTokensRefresh(ctx context.Context) (err error) {
@@ -285,5 +364,22 @@ func (s *Server) Moniker(ctx context.Context, params *protocol.MonikerParams) (r
 
 // Request handles all no standard request
 func (s *Server) Request(ctx context.Context, method string, params interface{}) (result interface{}, err error) {
+	switch method {
+	case methodTextDocumentDiagnostic:
+		log.Debugln("-----------DocumentDiagnostic called-----------")
+		var p documentDiagnosticParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.documentDiagnostic(ctx, &p)
+	case methodWorkspaceDiagnostic:
+		log.Debugln("-----------WorkspaceDiagnostic called-----------")
+		var p workspaceDiagnosticParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.workspaceDiagnostic(ctx, &p)
+	}
+
 	return nil, nil
 }
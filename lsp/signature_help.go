@@ -0,0 +1,20 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/joyme123/thrift-ls/lsp/signaturehelp"
+	"go.lsp.dev/protocol"
+)
+
+func (s *Server) signatureHelp(ctx context.Context, params *protocol.SignatureHelpParams) (*protocol.SignatureHelp, error) {
+	file := params.TextDocument.URI
+	view, err := s.session.ViewOf(file)
+	if err != nil {
+		return nil, err
+	}
+	ss, release := view.Snapshot()
+	defer release()
+
+	return signaturehelp.SignatureHelp(ctx, ss, file, params.Position)
+}
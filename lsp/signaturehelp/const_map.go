@@ -0,0 +1,210 @@
+package signaturehelp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/codejump"
+	"github.com/joyme123/thrift-ls/lsp/lsputils"
+	"github.com/joyme123/thrift-ls/parser"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// structLike is struct, union or exception which can be initialized by const map
+type structLike struct {
+	file     uri.URI
+	ast      *parser.Document
+	keyword  string
+	name     string
+	fields   []*parser.Field
+	comments []*parser.Comment
+}
+
+func constSignatureHelp(ctx context.Context, ss *cache.Snapshot, file uri.URI, ast *parser.Document, cst *parser.Const, offset int) (*protocol.SignatureHelp, error) {
+	if cst.ConstType == nil || cst.ConstType.TypeName == nil || cst.Value == nil {
+		return nil, nil
+	}
+
+	return structSignatureHelp(ctx, ss, file, ast, cst.ConstType, cst.Value, offset)
+}
+
+// structSignatureHelp returns signature of struct if cursor is in value which is map. fields of struct
+// are parameters, and field of key under cursor is active parameter. value of field can be map too,
+// such as `{"user": {"name": "x"}}`, signature of the innermost map is returned.
+func structSignatureHelp(ctx context.Context, ss *cache.Snapshot, file uri.URI, ast *parser.Document, ft *parser.FieldType, value *parser.ConstValue, offset int) (*protocol.SignatureHelp, error) {
+	if value.TypeName != "map" || !inCurs(value, offset) {
+		return nil, nil
+	}
+
+	st, err := resolveStruct(ctx, ss, file, ast, ft)
+	if err != nil || st == nil {
+		return nil, err
+	}
+
+	fields := make([]*parser.Field, 0, len(st.fields))
+	for _, field := range st.fields {
+		if validField(field) {
+			fields = append(fields, field)
+		}
+	}
+
+	pairs, _ := value.Value.([]*parser.ConstValue)
+	for _, pair := range pairs {
+		if pair == nil {
+			continue
+		}
+		v, ok := pair.Value.(*parser.ConstValue)
+		if !ok || v.TypeName != "map" || !inCurs(v, offset) {
+			continue
+		}
+		// cursor is in value of field
+		field := fieldByName(fields, keyName(pair.Key))
+		if field == nil {
+			return nil, nil
+		}
+		return structSignatureHelp(ctx, ss, st.file, st.ast, field.FieldType, v, offset)
+	}
+
+	params := make([]protocol.ParameterInformation, 0)
+	label := st.keyword + " " + st.name + " {" + fieldsLabel(fields, &params) + "}"
+
+	return &protocol.SignatureHelp{
+		Signatures: []protocol.SignatureInformation{
+			{
+				Label:         label,
+				Documentation: lsputils.CommentsDocumentation(st.comments),
+				Parameters:    params,
+			},
+		},
+		ActiveParameter: uint32(activePair(pairs, fields, offset)),
+	}, nil
+}
+
+// resolveStruct returns struct, union or exception of field type. nil is returned for other types
+func resolveStruct(ctx context.Context, ss *cache.Snapshot, file uri.URI, ast *parser.Document, ft *parser.FieldType) (*structLike, error) {
+	if ft.TypeName == nil {
+		return nil, nil
+	}
+	dstFile, id, kind, err := codejump.TypeNameDefinitionIdentifier(ctx, ss, file, ast, ft.TypeName)
+	if err != nil || id == nil {
+		return nil, err
+	}
+
+	pf, err := ss.Parse(ctx, dstFile)
+	if err != nil {
+		return nil, err
+	}
+	if pf.AST() == nil {
+		return nil, nil
+	}
+
+	st := &structLike{
+		file:    dstFile,
+		ast:     pf.AST(),
+		keyword: strings.ToLower(kind),
+		name:    id.Name.Text,
+	}
+	switch kind {
+	case "Struct":
+		node := codejump.GetStructNode(pf.AST(), id.Name.Text)
+		if node == nil {
+			return nil, nil
+		}
+		st.fields, st.comments = node.Fields, node.Comments
+	case "Union":
+		node := codejump.GetUnionNode(pf.AST(), id.Name.Text)
+		if node == nil {
+			return nil, nil
+		}
+		st.fields, st.comments = node.Fields, node.Comments
+	case "Exception":
+		node := codejump.GetExceptionNode(pf.AST(), id.Name.Text)
+		if node == nil {
+			return nil, nil
+		}
+		st.fields, st.comments = node.Fields, node.Comments
+	default:
+		return nil, nil
+	}
+
+	return st, nil
+}
+
+// activePair returns index of field which is being filled. If cursor is in a new pair, the first field
+// that hasn't been assigned is returned
+func activePair(pairs []*parser.ConstValue, fields []*parser.Field, offset int) int {
+	var cur *parser.ConstValue
+	assigned := make(map[string]struct{})
+	for _, pair := range pairs {
+		if pair == nil {
+			continue
+		}
+		assigned[keyName(pair.Key)] = struct{}{}
+		if pair.Pos().Offset > offset {
+			continue
+		}
+		cur = pair
+		sep := pair.ListSeparatorKeyword
+		if sep != nil && sep.Literal != nil && sep.Literal.End().Offset <= offset {
+			cur = nil
+		}
+	}
+
+	if cur != nil {
+		name := keyName(cur.Key)
+		for i, field := range fields {
+			if field.Identifier.Name.Text == name {
+				return i
+			}
+		}
+	}
+
+	for i, field := range fields {
+		if _, ok := assigned[field.Identifier.Name.Text]; !ok {
+			return i
+		}
+	}
+
+	return len(fields)
+}
+
+// keyName returns text of string key in const map
+func keyName(key any) string {
+	cv, ok := key.(*parser.ConstValue)
+	if !ok || cv == nil || cv.TypeName != "string" {
+		return ""
+	}
+	literal, ok := cv.Value.(*parser.Literal)
+	if !ok || literal == nil || literal.Value == nil {
+		return ""
+	}
+
+	return literal.Value.Text
+}
+
+func fieldByName(fields []*parser.Field, name string) *parser.Field {
+	if name == "" {
+		return nil
+	}
+	for _, field := range fields {
+		if field.Identifier.Name.Text == name {
+			return field
+		}
+	}
+
+	return nil
+}
+
+func inCurs(value *parser.ConstValue, offset int) bool {
+	lcur, rcur := value.LCurKeyword, value.RCurKeyword
+	if lcur == nil || lcur.Literal == nil || lcur.Literal.End().Offset > offset {
+		return false
+	}
+	if rcur == nil || rcur.Literal == nil || rcur.BadNode {
+		return true
+	}
+
+	return offset <= rcur.Literal.Pos().Offset
+}
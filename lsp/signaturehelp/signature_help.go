@@ -0,0 +1,257 @@
+package signaturehelp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/joyme123/thrift-ls/format"
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/codejump"
+	"github.com/joyme123/thrift-ls/lsp/lsputils"
+	"github.com/joyme123/thrift-ls/lsp/types"
+	"github.com/joyme123/thrift-ls/parser"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// TriggerCharacters are characters which trigger signature help
+var TriggerCharacters = []string{"(", ",", "{", ":"}
+
+// SignatureHelp returns signature under cursor. Two kinds of signature are supported:
+//  1. arguments and throws of function which overrides function of extended service. signature of
+//     parent function is returned.
+//  2. value of const map whose type is struct. fields of struct are returned as parameters.
+func SignatureHelp(ctx context.Context, ss *cache.Snapshot, file uri.URI, pos protocol.Position) (*protocol.SignatureHelp, error) {
+	pf, err := ss.Parse(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	if pf.AST() == nil {
+		return nil, errors.New("parse ast failed")
+	}
+
+	astPos, err := pf.Mapper().LSPPosToParserPosition(types.Position{Line: pos.Line, Character: pos.Character})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range pf.AST().Nodes {
+		if !contains(node, astPos.Offset) {
+			continue
+		}
+		switch node := node.(type) {
+		case *parser.Service:
+			return functionSignatureHelp(ctx, ss, file, pf.AST(), node, astPos.Offset)
+		case *parser.Const:
+			return constSignatureHelp(ctx, ss, file, pf.AST(), node, astPos.Offset)
+		}
+	}
+
+	return nil, nil
+}
+
+func functionSignatureHelp(ctx context.Context, ss *cache.Snapshot, file uri.URI, ast *parser.Document, svc *parser.Service, offset int) (*protocol.SignatureHelp, error) {
+	for _, fn := range svc.Functions {
+		if fn == nil || !contains(fn, offset) || !validIdentifier(fn.Name) {
+			continue
+		}
+
+		var (
+			fields   []*parser.Field
+			inThrows bool
+		)
+		if inParens(fn.LParKeyword, fn.RParKeyword, offset) {
+			fields = fn.Arguments
+		} else if fn.Throws != nil && inParens(fn.Throws.LParKeyword, fn.Throws.RParKeyword, offset) {
+			fields = fn.Throws.Fields
+			inThrows = true
+		} else {
+			return nil, nil
+		}
+
+		parent, err := parentFunction(ctx, ss, file, ast, svc, fn.Name.Name.Text)
+		if err != nil || parent == nil {
+			return nil, err
+		}
+
+		signature := functionSignature(parent)
+		parentFields := parent.Arguments
+		base := 0
+		if inThrows {
+			parentFields = nil
+			if parent.Throws != nil {
+				parentFields = parent.Throws.Fields
+			}
+			base = len(parent.Arguments)
+		}
+
+		return &protocol.SignatureHelp{
+			Signatures:      []protocol.SignatureInformation{signature},
+			ActiveParameter: uint32(base + activeField(fields, parentFields, offset)),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// parentFunction searches function with name in extended services of svc recursively
+func parentFunction(ctx context.Context, ss *cache.Snapshot, file uri.URI, ast *parser.Document, svc *parser.Service, name string) (*parser.Function, error) {
+	visited := make(map[string]struct{})
+	for svc.Extends != nil && svc.Extends.Name != nil {
+		dstFile, id, _, err := codejump.ServiceDefinitionIdentifier(ctx, ss, file, ast, svc.Extends.Name)
+		if err != nil || id == nil {
+			return nil, err
+		}
+
+		key := fmt.Sprintf("%s#%s", dstFile, id.Name.Text)
+		if _, ok := visited[key]; ok {
+			// cycle extends
+			return nil, nil
+		}
+		visited[key] = struct{}{}
+
+		pf, err := ss.Parse(ctx, dstFile)
+		if err != nil {
+			return nil, err
+		}
+		if pf.AST() == nil {
+			return nil, nil
+		}
+		svc = codejump.GetServiceNode(pf.AST(), id.Name.Text)
+		if svc == nil {
+			return nil, nil
+		}
+		file, ast = dstFile, pf.AST()
+
+		for _, fn := range svc.Functions {
+			if fn != nil && validIdentifier(fn.Name) && fn.Name.Name.Text == name {
+				return fn, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// functionSignature returns signature of function. arguments and throws are parameters of signature
+func functionSignature(fn *parser.Function) protocol.SignatureInformation {
+	var sb strings.Builder
+	if fn.Oneway != nil {
+		sb.WriteString("oneway ")
+	}
+	if fn.Void != nil || fn.FunctionType == nil {
+		sb.WriteString("void")
+	} else {
+		sb.WriteString(format.MustFormatFieldType(fn.FunctionType))
+	}
+	sb.WriteString(" " + fn.Name.Name.Text + "(")
+
+	params := make([]protocol.ParameterInformation, 0)
+	sb.WriteString(fieldsLabel(fn.Arguments, &params))
+	sb.WriteString(")")
+
+	if fn.Throws != nil {
+		sb.WriteString(" throws (")
+		sb.WriteString(fieldsLabel(fn.Throws.Fields, &params))
+		sb.WriteString(")")
+	}
+
+	return protocol.SignatureInformation{
+		Label:         sb.String(),
+		Documentation: lsputils.CommentsDocumentation(fn.Comments),
+		Parameters:    params,
+	}
+}
+
+// fieldsLabel joins labels of fields and appends them to params
+func fieldsLabel(fields []*parser.Field, params *[]protocol.ParameterInformation) string {
+	labels := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if !validField(field) {
+			continue
+		}
+		label := fieldLabel(field)
+		labels = append(labels, label)
+		*params = append(*params, protocol.ParameterInformation{
+			Label:         label,
+			Documentation: lsputils.CommentsDocumentation(field.Comments),
+		})
+	}
+
+	return strings.Join(labels, ", ")
+}
+
+// fieldLabel returns field without comments, annotations and default value. such as `1: required string name`
+func fieldLabel(field *parser.Field) string {
+	var sb strings.Builder
+	if field.Index != nil && !field.Index.BadNode {
+		sb.WriteString(fmt.Sprintf("%d: ", field.Index.Value))
+	}
+	if field.RequiredKeyword != nil && field.RequiredKeyword.Literal != nil {
+		sb.WriteString(field.RequiredKeyword.Literal.Text + " ")
+	}
+	sb.WriteString(format.MustFormatFieldType(field.FieldType))
+	sb.WriteString(" " + field.Identifier.Name.Text)
+
+	return sb.String()
+}
+
+// activeField returns index of parameter in parentFields which cursor is on. Field under cursor is
+// matched by field id, and by position if field id is missing.
+func activeField(fields []*parser.Field, parentFields []*parser.Field, offset int) int {
+	index := 0
+	var cur *parser.Field
+	for i, field := range fields {
+		if field == nil || field.Pos().Offset > offset {
+			break
+		}
+		index, cur = i, field
+		sep := field.ListSeparatorKeyword
+		if sep != nil && sep.Literal != nil && sep.Literal.End().Offset <= offset {
+			// cursor is after separator, next field is being written
+			index, cur = i+1, nil
+		}
+	}
+
+	if cur != nil && cur.Index != nil && !cur.Index.BadNode {
+		i := 0
+		for _, field := range parentFields {
+			if !validField(field) {
+				continue
+			}
+			if field.Index != nil && field.Index.Value == cur.Index.Value {
+				return i
+			}
+			i++
+		}
+	}
+
+	return index
+}
+
+func inParens(lpar *parser.LParKeyword, rpar *parser.RParKeyword, offset int) bool {
+	if lpar == nil || lpar.Literal == nil || lpar.Literal.End().Offset > offset {
+		return false
+	}
+	if rpar == nil || rpar.Literal == nil || rpar.BadNode {
+		return true
+	}
+
+	return offset <= rpar.Literal.Pos().Offset
+}
+
+func contains(node parser.Node, offset int) bool {
+	return node.Pos().Offset <= offset && offset <= node.End().Offset
+}
+
+func validIdentifier(id *parser.Identifier) bool {
+	return id != nil && !id.BadNode && id.Name != nil && id.Name.Text != ""
+}
+
+func validField(field *parser.Field) bool {
+	return field != nil && !field.BadNode && field.FieldType != nil && field.FieldType.TypeName != nil &&
+		validIdentifier(field.Identifier)
+}
@@ -0,0 +1,151 @@
+package signaturehelp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestSignatureHelp(t *testing.T) {
+	baseFile := `exception NotFound {}
+
+service BaseService {
+  // get user by id
+  string Get(
+    // id of user
+    1: required i64 id,
+    2: string name) throws (1: NotFound e)
+}`
+
+	apiFile := `include "base.thrift"
+
+/** user info */
+struct User {
+  // name of user
+  1: string name,
+  2: i32 age,
+  3: Group group,
+}
+
+struct Group {
+  1: string name
+}
+
+const User u = {"name": "a", "age": 1, "group": {"name": "g"}}
+const User u2 = {"age": 1, }
+
+service Middle extends base.BaseService {}
+
+service Api extends Middle {
+  string Get(1: i64 id, 2: string name) throws (1: base.NotFound e)
+  void Other(1: i64 id)
+}`
+
+	ss := cache.BuildSnapshotForTest([]*cache.FileChange{
+		{
+			URI:     "file:///tmp/base.thrift",
+			Version: 0,
+			Content: []byte(baseFile),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+		{
+			URI:     "file:///tmp/api.thrift",
+			Version: 0,
+			Content: []byte(apiFile),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	})
+
+	getSignature := protocol.SignatureInformation{
+		Label:         "string Get(1: required i64 id, 2: string name) throws (1: NotFound e)",
+		Documentation: "get user by id",
+		Parameters: []protocol.ParameterInformation{
+			{Label: "1: required i64 id", Documentation: "id of user"},
+			{Label: "2: string name", Documentation: ""},
+			{Label: "1: NotFound e", Documentation: ""},
+		},
+	}
+	userSignature := protocol.SignatureInformation{
+		Label:         "struct User {1: string name, 2: i32 age, 3: Group group}",
+		Documentation: "user info",
+		Parameters: []protocol.ParameterInformation{
+			{Label: "1: string name", Documentation: "name of user"},
+			{Label: "2: i32 age", Documentation: ""},
+			{Label: "3: Group group", Documentation: ""},
+		},
+	}
+	groupSignature := protocol.SignatureInformation{
+		Label:         "struct Group {1: string name}",
+		Documentation: "",
+		Parameters: []protocol.ParameterInformation{
+			{Label: "1: string name", Documentation: ""},
+		},
+	}
+
+	tests := []struct {
+		name string
+		file uri.URI
+		pos  protocol.Position
+		want *protocol.SignatureHelp
+	}{
+		{
+			name: "first argument of overridden function",
+			file: "file:///tmp/api.thrift",
+			pos:  protocol.Position{Line: 20, Character: 13},
+			want: &protocol.SignatureHelp{Signatures: []protocol.SignatureInformation{getSignature}, ActiveParameter: 0},
+		},
+		{
+			name: "second argument of overridden function",
+			file: "file:///tmp/api.thrift",
+			pos:  protocol.Position{Line: 20, Character: 26},
+			want: &protocol.SignatureHelp{Signatures: []protocol.SignatureInformation{getSignature}, ActiveParameter: 1},
+		},
+		{
+			name: "throws of overridden function",
+			file: "file:///tmp/api.thrift",
+			pos:  protocol.Position{Line: 20, Character: 48},
+			want: &protocol.SignatureHelp{Signatures: []protocol.SignatureInformation{getSignature}, ActiveParameter: 2},
+		},
+		{
+			name: "function not overridden",
+			file: "file:///tmp/api.thrift",
+			pos:  protocol.Position{Line: 21, Character: 13},
+			want: nil,
+		},
+		{
+			name: "outside of arguments",
+			file: "file:///tmp/api.thrift",
+			pos:  protocol.Position{Line: 20, Character: 5},
+			want: nil,
+		},
+		{
+			name: "struct const map",
+			file: "file:///tmp/api.thrift",
+			pos:  protocol.Position{Line: 14, Character: 31},
+			want: &protocol.SignatureHelp{Signatures: []protocol.SignatureInformation{userSignature}, ActiveParameter: 1},
+		},
+		{
+			name: "nested struct const map",
+			file: "file:///tmp/api.thrift",
+			pos:  protocol.Position{Line: 14, Character: 57},
+			want: &protocol.SignatureHelp{Signatures: []protocol.SignatureInformation{groupSignature}, ActiveParameter: 0},
+		},
+		{
+			name: "new pair of struct const map",
+			file: "file:///tmp/api.thrift",
+			pos:  protocol.Position{Line: 15, Character: 26},
+			want: &protocol.SignatureHelp{Signatures: []protocol.SignatureInformation{userSignature}, ActiveParameter: 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SignatureHelp(context.TODO(), ss, tt.file, tt.pos)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
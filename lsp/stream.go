@@ -44,7 +44,9 @@ func (s *StreamServer) ServeStream(ctx context.Context, conn jsonrpc2.Conn) erro
 	conn.Go(ctx,
 		DebugHandler(
 			protocol.Handlers(
-				protocol.ServerHandler(server, jsonrpc2.MethodNotFoundHandler))))
+				PullDiagnosticsHandler(
+					TextSyncHandler(
+						protocol.ServerHandler(server, jsonrpc2.MethodNotFoundHandler))))))
 	<-conn.Done()
 	return conn.Err()
 }
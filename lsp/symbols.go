@@ -3,6 +3,7 @@ package lsp
 import (
 	"context"
 
+	"github.com/joyme123/thrift-ls/lsp/cache"
 	"github.com/joyme123/thrift-ls/lsp/symbols"
 	"go.lsp.dev/protocol"
 )
@@ -24,3 +25,19 @@ func (s *Server) documentSymbol(ctx context.Context, params *protocol.DocumentSy
 
 	return
 }
+
+// workspaceSymbol searches symbols in all views. index is updated by latest snapshots before searching,
+// only changed files are indexed again
+func (s *Server) workspaceSymbol(ctx context.Context, params *protocol.WorkspaceSymbolParams) ([]protocol.SymbolInformation, error) {
+	views := s.session.Views()
+	snapshots := make([]*cache.Snapshot, 0, len(views))
+	for _, view := range views {
+		ss, release := view.Snapshot()
+		defer release()
+		snapshots = append(snapshots, ss)
+	}
+
+	s.workspaceSymbols.Update(ctx, snapshots)
+
+	return s.workspaceSymbols.Search(params.Query), nil
+}
@@ -0,0 +1,64 @@
+package symbols
+
+import (
+	"strings"
+	"unicode"
+)
+
+// fuzzyMatch reports whether all characters of pattern appear in candidate in order, case is ignored.
+// Exact, prefix and consecutive matches, and matches at start of word get higher score
+func fuzzyMatch(pattern, candidate string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+
+	lowerPattern := strings.ToLower(pattern)
+	lowerCandidate := strings.ToLower(candidate)
+	p := []rune(lowerPattern)
+	c := []rune(candidate)
+	lc := []rune(lowerCandidate)
+	if len(lc) != len(c) {
+		// lower case changes length of some runes, match them as they are
+		lc = c
+	}
+
+	score, pi, prev := 0, 0, -2
+	for ci := 0; ci < len(lc) && pi < len(p); ci++ {
+		if lc[ci] != p[pi] {
+			continue
+		}
+		score++
+		if ci == prev+1 {
+			score += 2
+		}
+		if isWordStart(c, ci) {
+			score += 3
+		}
+		prev = ci
+		pi++
+	}
+	if pi < len(p) {
+		return 0, false
+	}
+
+	switch {
+	case lowerCandidate == lowerPattern:
+		score += 100
+	case strings.HasPrefix(lowerCandidate, lowerPattern):
+		score += 50
+	case strings.Contains(lowerCandidate, lowerPattern):
+		score += 20
+	}
+
+	return score, true
+}
+
+// isWordStart reports whether c[i] is the first character of a word. words are separated by '.', '_'
+// or case change, such as `getUser`
+func isWordStart(c []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := c[i-1]
+	return prev == '.' || prev == '_' || (unicode.IsLower(prev) && unicode.IsUpper(c[i]))
+}
@@ -0,0 +1,207 @@
+package symbols
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/lsputils"
+	"github.com/joyme123/thrift-ls/parser"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// maxWorkspaceSymbols limits the number of symbols returned by a query
+const maxWorkspaceSymbols = 100
+
+// WorkspaceIndex is the symbol index of all parsed files in workspace. It is updated incrementally:
+// symbols of a file are collected again only when the content of file changes.
+type WorkspaceIndex struct {
+	mu    sync.Mutex
+	files map[uri.URI]*fileSymbols
+}
+
+type fileSymbols struct {
+	identity cache.FileIdentity
+	symbols  []*workspaceSymbol
+}
+
+type workspaceSymbol struct {
+	// qualified is name qualified by its container, such as UserService.getUser, Status.OK or user.User
+	qualified string
+	info      protocol.SymbolInformation
+}
+
+func NewWorkspaceIndex() *WorkspaceIndex {
+	return &WorkspaceIndex{
+		files: make(map[uri.URI]*fileSymbols),
+	}
+}
+
+// Update syncs index with parsed files of snapshots. Files which aren't parsed by any snapshot are removed
+func (w *WorkspaceIndex) Update(ctx context.Context, snapshots []*cache.Snapshot) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	seen := make(map[uri.URI]struct{})
+	for _, ss := range snapshots {
+		for _, file := range ss.ParsedFiles() {
+			if _, ok := seen[file]; ok {
+				continue
+			}
+			seen[file] = struct{}{}
+
+			pf, err := ss.Parse(ctx, file)
+			if err != nil || pf.AST() == nil {
+				delete(w.files, file)
+				continue
+			}
+			identity := pf.FileIdentity()
+			if cached, ok := w.files[file]; ok && cached.identity == identity {
+				continue
+			}
+			w.files[file] = &fileSymbols{
+				identity: identity,
+				symbols:  workspaceSymbols(file, pf.AST()),
+			}
+		}
+	}
+
+	for file := range w.files {
+		if _, ok := seen[file]; !ok {
+			delete(w.files, file)
+		}
+	}
+}
+
+// Search returns symbols which fuzzy match query. Query containing '.' is matched with qualified name,
+// such as `UserService.getUser`
+func (w *WorkspaceIndex) Search(query string) []protocol.SymbolInformation {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	query = strings.TrimSpace(query)
+	qualified := strings.Contains(query, ".")
+
+	type match struct {
+		symbol *workspaceSymbol
+		score  int
+	}
+	matches := make([]match, 0)
+	for _, file := range w.files {
+		for _, symbol := range file.symbols {
+			target := symbol.info.Name
+			if qualified {
+				target = symbol.qualified
+			}
+			score, ok := fuzzyMatch(query, target)
+			if !ok {
+				continue
+			}
+			matches = append(matches, match{symbol: symbol, score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		// shorter name is closer to query
+		if len(a.symbol.info.Name) != len(b.symbol.info.Name) {
+			return len(a.symbol.info.Name) < len(b.symbol.info.Name)
+		}
+		if a.symbol.qualified != b.symbol.qualified {
+			return a.symbol.qualified < b.symbol.qualified
+		}
+		return a.symbol.info.Location.URI < b.symbol.info.Location.URI
+	})
+
+	if len(matches) > maxWorkspaceSymbols {
+		matches = matches[:maxWorkspaceSymbols]
+	}
+
+	res := make([]protocol.SymbolInformation, 0, len(matches))
+	for _, item := range matches {
+		res = append(res, item.symbol.info)
+	}
+
+	return res
+}
+
+// workspaceSymbols returns definitions, enum values and functions in ast. Top level definitions are
+// qualified by include name of file
+func workspaceSymbols(file uri.URI, ast *parser.Document) []*workspaceSymbol {
+	res := make([]*workspaceSymbol, 0)
+	include := lsputils.GetIncludeName(file)
+
+	add := func(id *parser.Identifier, kind protocol.SymbolKind, container string) {
+		if id == nil || id.BadNode || id.Name == nil || id.Name.Text == "" {
+			return
+		}
+		res = append(res, &workspaceSymbol{
+			qualified: container + "." + id.Name.Text,
+			info: protocol.SymbolInformation{
+				Name: id.Name.Text,
+				Kind: kind,
+				Location: protocol.Location{
+					URI:   file,
+					Range: lsputils.ASTNodeToRange(id.Name),
+				},
+				ContainerName: container,
+			},
+		})
+	}
+
+	for _, st := range ast.Structs {
+		if !st.BadNode {
+			add(st.Identifier, protocol.SymbolKindStruct, include)
+		}
+	}
+	for _, union := range ast.Unions {
+		if !union.BadNode {
+			add(union.Name, protocol.SymbolKindStruct, include)
+		}
+	}
+	for _, excep := range ast.Exceptions {
+		if !excep.BadNode {
+			add(excep.Name, protocol.SymbolKindStruct, include)
+		}
+	}
+	for _, enum := range ast.Enums {
+		if enum.BadNode || enum.Name == nil || enum.Name.Name == nil {
+			continue
+		}
+		add(enum.Name, protocol.SymbolKindEnum, include)
+		for _, value := range enum.Values {
+			if !value.BadNode {
+				add(value.Name, protocol.SymbolKindNumber, enum.Name.Name.Text)
+			}
+		}
+	}
+	for _, td := range ast.Typedefs {
+		if !td.BadNode {
+			add(td.Alias, protocol.SymbolKindTypeParameter, include)
+		}
+	}
+	for _, cst := range ast.Consts {
+		if !cst.BadNode {
+			add(cst.Name, protocol.SymbolKindConstant, include)
+		}
+	}
+	for _, svc := range ast.Services {
+		if svc.BadNode || svc.Name == nil || svc.Name.Name == nil {
+			continue
+		}
+		add(svc.Name, protocol.SymbolKindInterface, include)
+		for _, fn := range svc.Functions {
+			if !fn.BadNode {
+				add(fn.Name, protocol.SymbolKindFunction, svc.Name.Name.Text)
+			}
+		}
+	}
+
+	return res
+}
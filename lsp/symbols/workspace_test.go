@@ -0,0 +1,155 @@
+package symbols
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+)
+
+func TestWorkspaceIndexSearch(t *testing.T) {
+	userFile := `enum Status {
+  OK = 1
+}
+
+struct User {}
+
+typedef User Author
+
+const i32 MaxAge = 100
+
+service UserService {
+  User getUser(1: i64 id)
+  void updateUser(1: User user)
+}`
+
+	groupFile := `struct Group {}
+
+exception GroupNotFound {}
+
+union UserOrGroup {}`
+
+	ss := cache.BuildSnapshotForTest([]*cache.FileChange{
+		{
+			URI:     "file:///tmp/user.thrift",
+			Version: 0,
+			Content: []byte(userFile),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+		{
+			URI:     "file:///tmp/group.thrift",
+			Version: 0,
+			Content: []byte(groupFile),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	})
+
+	index := NewWorkspaceIndex()
+	index.Update(context.TODO(), []*cache.Snapshot{ss})
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "prefix",
+			query: "User",
+			want:  []string{"user.User", "group.UserOrGroup", "user.UserService", "UserService.getUser", "UserService.updateUser"},
+		},
+		{
+			name:  "fuzzy",
+			query: "gnf",
+			want:  []string{"group.GroupNotFound"},
+		},
+		{
+			name:  "qualified",
+			query: "UserService.getUser",
+			want:  []string{"UserService.getUser"},
+		},
+		{
+			name:  "qualified enum value",
+			query: "status.ok",
+			want:  []string{"Status.OK"},
+		},
+		{
+			name:  "qualified by include name",
+			query: "user.max",
+			want:  []string{"user.MaxAge"},
+		},
+		{
+			name:  "no match",
+			query: "xyz",
+			want:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, item := range index.Search(tt.query) {
+				got = append(got, item.ContainerName+"."+item.Name)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	res := index.Search("getUser")
+	assert.Equal(t, []protocol.SymbolInformation{
+		{
+			Name: "getUser",
+			Kind: protocol.SymbolKindFunction,
+			Location: protocol.Location{
+				URI: "file:///tmp/user.thrift",
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 11, Character: 7},
+					End:   protocol.Position{Line: 11, Character: 14},
+				},
+			},
+			ContainerName: "UserService",
+		},
+	}, res)
+}
+
+func TestWorkspaceIndexUpdate(t *testing.T) {
+	files := []*cache.FileChange{
+		{
+			URI:     "file:///tmp/user.thrift",
+			Version: 0,
+			Content: []byte("struct User {}"),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+		{
+			URI:     "file:///tmp/group.thrift",
+			Version: 0,
+			Content: []byte("struct Group {}"),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	}
+
+	index := NewWorkspaceIndex()
+	index.Update(context.TODO(), []*cache.Snapshot{cache.BuildSnapshotForTest(files)})
+	assert.Len(t, index.Search("Group"), 1)
+	group := index.files["file:///tmp/group.thrift"]
+
+	// user.thrift is changed and group.thrift is removed
+	index.Update(context.TODO(), []*cache.Snapshot{cache.BuildSnapshotForTest([]*cache.FileChange{
+		{
+			URI:     "file:///tmp/user.thrift",
+			Version: 1,
+			Content: []byte("struct Member {}"),
+			From:    cache.FileChangeTypeDidOpen,
+		},
+	})})
+	assert.Len(t, index.Search("Group"), 0)
+	assert.Len(t, index.Search("User"), 0)
+	assert.Len(t, index.Search("Member"), 1)
+
+	// unchanged file isn't indexed again
+	index.Update(context.TODO(), []*cache.Snapshot{cache.BuildSnapshotForTest(files)})
+	user := index.files["file:///tmp/user.thrift"]
+	index.Update(context.TODO(), []*cache.Snapshot{cache.BuildSnapshotForTest(files)})
+	assert.Same(t, user, index.files["file:///tmp/user.thrift"])
+	assert.NotSame(t, group, index.files["file:///tmp/group.thrift"])
+}
@@ -0,0 +1,53 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.lsp.dev/jsonrpc2"
+	"go.lsp.dev/protocol"
+)
+
+// contentChangeRangesKey is the context key of whether content changes of didChange request have range
+type contentChangeRangesKey struct{}
+
+// TextSyncHandler records whether content changes of didChange request have range. content change without
+// range is full content of document, but it can't be distinguished from an insertion at the beginning of
+// document after decoded as protocol.TextDocumentContentChangeEvent
+func TextSyncHandler(handler jsonrpc2.Handler) jsonrpc2.Handler {
+	return func(ctx context.Context, reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+		if req.Method() != protocol.MethodTextDocumentDidChange {
+			return handler(ctx, reply, req)
+		}
+
+		var params struct {
+			ContentChanges []struct {
+				Range *protocol.Range `json:"range"`
+			} `json:"contentChanges"`
+		}
+		if err := json.Unmarshal(req.Params(), &params); err == nil {
+			hasRange := make([]bool, len(params.ContentChanges))
+			for i := range params.ContentChanges {
+				hasRange[i] = params.ContentChanges[i].Range != nil
+			}
+			ctx = context.WithValue(ctx, contentChangeRangesKey{}, hasRange)
+		}
+
+		return handler(ctx, reply, req)
+	}
+}
+
+// contentChangeRanges reports whether content changes of params have range
+func contentChangeRanges(ctx context.Context, params *protocol.DidChangeTextDocumentParams) []bool {
+	if hasRange, ok := ctx.Value(contentChangeRangesKey{}).([]bool); ok && len(hasRange) == len(params.ContentChanges) {
+		return hasRange
+	}
+
+	// params isn't from TextSyncHandler. empty range at the beginning of document is taken as full content
+	hasRange := make([]bool, len(params.ContentChanges))
+	for i := range params.ContentChanges {
+		hasRange[i] = params.ContentChanges[i].Range != protocol.Range{}
+	}
+
+	return hasRange
+}
@@ -0,0 +1,143 @@
+package lsp
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	log "github.com/sirupsen/logrus"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+const watchedFilesRegistrationID = "thriftls-watched-files"
+
+// watchedFilesRegistration registers watchers of thrift files and project config files, so files changed by
+// git checkout, code generators or other tools are notified
+func watchedFilesRegistration() protocol.Registration {
+	return protocol.Registration{
+		ID:     watchedFilesRegistrationID,
+		Method: protocol.MethodWorkspaceDidChangeWatchedFiles,
+		RegisterOptions: protocol.DidChangeWatchedFilesRegistrationOptions{
+			Watchers: []protocol.FileSystemWatcher{
+				{
+					GlobPattern: "**/*.thrift",
+					Kind:        protocol.WatchKindCreate + protocol.WatchKindChange + protocol.WatchKindDelete,
+				},
+				{
+					GlobPattern: "**/" + ProjectConfigFile,
+					Kind:        protocol.WatchKindCreate + protocol.WatchKindChange + protocol.WatchKindDelete,
+				},
+			},
+		},
+	}
+}
+
+func (s *Server) didChangeWatchedFiles(ctx context.Context, params *protocol.DidChangeWatchedFilesParams) error {
+	// files closed in editor may still have overlays, they are outdated after changed on disk
+	uris := make([]uri.URI, 0, len(params.Changes))
+	viewChanges := make(map[*cache.View][]*cache.FileChange)
+	views := make([]*cache.View, 0)
+	configFolders := make([]uri.URI, 0)
+	for _, event := range params.Changes {
+		if event == nil {
+			continue
+		}
+		if filepath.Base(event.URI.Filename()) == ProjectConfigFile {
+			configFolders = append(configFolders, uri.File(filepath.Dir(event.URI.Filename())))
+			continue
+		}
+		if !strings.HasSuffix(event.URI.Filename(), ".thrift") {
+			continue
+		}
+		// content of open documents is owned by editor, changes on disk don't apply to them
+		if s.isOpen(event.URI) {
+			continue
+		}
+
+		view, err := s.session.ViewOf(event.URI)
+		if err != nil {
+			log.Errorf("view of %s not found: %v", event.URI, err)
+			continue
+		}
+		if s.excluded(view.Folder(), event.URI.Filename()) {
+			continue
+		}
+
+		change := &cache.FileChange{
+			URI:  event.URI,
+			From: cache.FileChangeTypeWatchedChange,
+		}
+		if event.Type == protocol.FileChangeTypeDeleted {
+			change.From = cache.FileChangeTypeWatchedDelete
+		}
+		if _, ok := viewChanges[view]; !ok {
+			views = append(views, view)
+		}
+		viewChanges[view] = append(viewChanges[view], change)
+		uris = append(uris, event.URI)
+	}
+	s.session.ForgetOverlayFS(uris)
+
+	for _, view := range views {
+		changes := viewChanges[view]
+		view.FileChange(ctx, changes, func() {
+			ss, release := view.Snapshot()
+			defer release()
+
+			if err := s.diagnosticWatchedFiles(ctx, ss, changes); err != nil {
+				log.Errorf("diagnostic error: %v", err)
+			}
+		})
+	}
+
+	if len(views) > 0 {
+		// diagnostics pulled by client are outdated
+		s.refreshDiagnostics()
+	}
+	if len(configFolders) > 0 {
+		s.reloadProjectConfig(ctx, configFolders)
+	}
+
+	return nil
+}
+
+// diagnosticWatchedFiles diagnoses changed files, and schedules diagnostics of files including them.
+// diagnostics of deleted files are cleared
+func (s *Server) diagnosticWatchedFiles(ctx context.Context, ss *cache.Snapshot, changes []*cache.FileChange) error {
+	deleted := make(map[uri.URI]bool)
+	changedFiles := make([]uri.URI, 0, len(changes))
+	for _, change := range changes {
+		if _, ok := deleted[change.URI]; !ok {
+			changedFiles = append(changedFiles, change.URI)
+		}
+		deleted[change.URI] = change.From == cache.FileChangeTypeWatchedDelete
+	}
+	defer s.diagnosticDependents(ss, changedFiles)
+
+	files := make([]uri.URI, 0, len(changedFiles))
+	for _, file := range changedFiles {
+		if !deleted[file] {
+			files = append(files, file)
+			continue
+		}
+		// deleted files aren't reported by client pulling diagnostics
+		if s.client == nil || s.pullDiagnostics {
+			continue
+		}
+		err := s.client.PublishDiagnostics(ctx, &protocol.PublishDiagnosticsParams{
+			URI:         file,
+			Diagnostics: make([]protocol.Diagnostic, 0),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	return s.diagnostic(ctx, ss, files)
+}
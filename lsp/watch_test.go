@@ -0,0 +1,252 @@
+package lsp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joyme123/thrift-ls/lsp/cache"
+	"github.com/joyme123/thrift-ls/lsp/memoize"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/jsonrpc2"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// recordClient records requests sent to client. methods not implemented panic
+type recordClient struct {
+	protocol.Client
+
+	mu            sync.Mutex
+	registrations []protocol.Registration
+	diagnostics   map[uri.URI][]protocol.Diagnostic
+	published     map[uri.URI]int
+	progress      []interface{}
+	calls         []string
+}
+
+// Call records methods of requests not supported by protocol package
+func (c *recordClient) Call(ctx context.Context, method string, params, result interface{}) (jsonrpc2.ID, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, method)
+	return jsonrpc2.NewNumberID(int32(len(c.calls))), nil
+}
+
+func (c *recordClient) takeCalls() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := c.calls
+	c.calls = nil
+	return res
+}
+
+func (c *recordClient) WorkDoneProgressCreate(ctx context.Context, params *protocol.WorkDoneProgressCreateParams) error {
+	return nil
+}
+
+func (c *recordClient) Progress(ctx context.Context, params *protocol.ProgressParams) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.progress = append(c.progress, params.Value)
+	return nil
+}
+
+func (c *recordClient) RegisterCapability(ctx context.Context, params *protocol.RegistrationParams) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.registrations = append(c.registrations, params.Registrations...)
+	return nil
+}
+
+func (c *recordClient) PublishDiagnostics(ctx context.Context, params *protocol.PublishDiagnosticsParams) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.diagnostics == nil {
+		c.diagnostics = make(map[uri.URI][]protocol.Diagnostic)
+	}
+	if c.published == nil {
+		c.published = make(map[uri.URI]int)
+	}
+	c.diagnostics[params.URI] = params.Diagnostics
+	c.published[params.URI]++
+	return nil
+}
+
+func (c *recordClient) takeDiagnostics() map[uri.URI][]protocol.Diagnostic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := c.diagnostics
+	c.diagnostics = nil
+	return res
+}
+
+// waitDiagnostics waits until diagnostics of file are published, and takes all published diagnostics
+func (c *recordClient) waitDiagnostics(t *testing.T, file uri.URI) map[uri.URI][]protocol.Diagnostic {
+	res := make(map[uri.URI][]protocol.Diagnostic)
+	assert.Eventually(t, func() bool {
+		for key, items := range c.takeDiagnostics() {
+			res[key] = items
+		}
+		_, ok := res[file]
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	return res
+}
+
+func Test_DidChangeWatchedFiles(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.thrift")
+	userPath := filepath.Join(dir, "user.thrift")
+	assert.NoError(t, os.WriteFile(basePath, []byte("struct Base {\n    1: string id\n}\n"), 0644))
+	assert.NoError(t, os.WriteFile(userPath, []byte("include \"base.thrift\"\n\nstruct User {\n    1: base.Base base\n}\n"), 0644))
+	baseURI, userURI := uri.File(basePath), uri.File(userPath)
+
+	client := &recordClient{}
+	srv := NewServer(cache.New(&memoize.Store{}), client)
+	srv.dependentDiagnostics.delay = 10 * time.Millisecond
+	_, err := srv.Initialize(ctx, &protocol.InitializeParams{
+		RootURI: uri.File(dir),
+		Capabilities: protocol.ClientCapabilities{
+			Workspace: &protocol.WorkspaceClientCapabilities{
+				DidChangeWatchedFiles: &protocol.DidChangeWatchedFilesWorkspaceClientCapabilities{
+					DynamicRegistration: true,
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, srv.Initialized(ctx, &protocol.InitializedParams{}))
+	assert.Equal(t, []protocol.Registration{watchedFilesRegistration()}, client.registrations)
+	// workspace is diagnosed in background
+	srv.background.Wait()
+	diagnostics := client.takeDiagnostics()
+	assert.Contains(t, diagnostics, baseURI)
+	assert.Contains(t, diagnostics, userURI)
+	assert.Empty(t, diagnostics[userURI])
+
+	// struct Base is removed by other tools, file including it is diagnosed again
+	assert.NoError(t, os.WriteFile(basePath, []byte("struct Base2 {\n    1: string id\n}\n"), 0644))
+	err = srv.DidChangeWatchedFiles(ctx, &protocol.DidChangeWatchedFilesParams{
+		Changes: []*protocol.FileEvent{
+			{URI: baseURI, Type: protocol.FileChangeTypeChanged},
+		},
+	})
+	assert.NoError(t, err)
+	// files including changed file are diagnosed later
+	diagnostics = client.waitDiagnostics(t, userURI)
+	assert.Contains(t, diagnostics, baseURI)
+	if assert.Len(t, diagnostics[userURI], 1) {
+		assert.Equal(t, "field type doesn't exist", diagnostics[userURI][0].Message)
+	}
+
+	ss, release, _, err := srv.getFileContext(ctx, baseURI)
+	assert.NoError(t, err)
+	pf, err := ss.Parse(ctx, baseURI)
+	release()
+	assert.NoError(t, err)
+	assert.Equal(t, "Base2", pf.AST().Structs[0].Identifier.Name.Text)
+
+	// diagnostics of deleted file are cleared
+	assert.NoError(t, os.Remove(basePath))
+	err = srv.DidChangeWatchedFiles(ctx, &protocol.DidChangeWatchedFilesParams{
+		Changes: []*protocol.FileEvent{
+			{URI: baseURI, Type: protocol.FileChangeTypeDeleted},
+		},
+	})
+	assert.NoError(t, err)
+	diagnostics = client.waitDiagnostics(t, userURI)
+	assert.Equal(t, []protocol.Diagnostic{}, diagnostics[baseURI])
+	assert.NotEmpty(t, diagnostics[userURI])
+}
+
+func Test_DidChangeWatchedFilesOfOpenDocument(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.thrift")
+	assert.NoError(t, os.WriteFile(basePath, []byte("struct Base {\n    1: string id\n}\n"), 0644))
+	baseURI := uri.File(basePath)
+
+	client := &recordClient{}
+	srv := NewServer(cache.New(&memoize.Store{}), client)
+	_, err := srv.Initialize(ctx, &protocol.InitializeParams{RootURI: uri.File(dir)})
+	assert.NoError(t, err)
+	assert.NoError(t, srv.Initialized(ctx, &protocol.InitializedParams{}))
+	srv.background.Wait()
+
+	// document is edited in editor but not saved
+	err = srv.DidOpen(ctx, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{
+			URI:        baseURI,
+			LanguageID: LanguageIDThrift,
+			Version:    1,
+			Text:       "struct Base {\n    1: string id\n    2: string name\n}\n",
+		},
+	})
+	assert.NoError(t, err)
+
+	// file is changed on disk by other tools
+	assert.NoError(t, os.WriteFile(basePath, []byte("struct Base2 {\n}\n"), 0644))
+	err = srv.DidChangeWatchedFiles(ctx, &protocol.DidChangeWatchedFilesParams{
+		Changes: []*protocol.FileEvent{
+			{URI: baseURI, Type: protocol.FileChangeTypeChanged},
+		},
+	})
+	assert.NoError(t, err)
+
+	// incremental edits are applied to content in editor
+	err = srv.DidChange(ctx, &protocol.DidChangeTextDocumentParams{
+		TextDocument: protocol.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: baseURI},
+			Version:                2,
+		},
+		ContentChanges: []protocol.TextDocumentContentChangeEvent{
+			{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 0, Character: 11},
+					End:   protocol.Position{Line: 0, Character: 11},
+				},
+				Text: "Info",
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	fh, err := srv.session.ReadFile(ctx, baseURI)
+	assert.NoError(t, err)
+	content, err := fh.Content()
+	assert.NoError(t, err)
+	assert.Equal(t, "struct BaseInfo {\n    1: string id\n    2: string name\n}\n", string(content))
+
+	ss, release, _, err := srv.getFileContext(ctx, baseURI)
+	assert.NoError(t, err)
+	pf, err := ss.Parse(ctx, baseURI)
+	release()
+	assert.NoError(t, err)
+	if assert.Len(t, pf.AST().Structs, 1) {
+		assert.Equal(t, "BaseInfo", pf.AST().Structs[0].Identifier.Name.Text)
+		assert.Len(t, pf.AST().Structs[0].Fields, 2)
+	}
+
+	// file closed in editor is reloaded from disk
+	err = srv.DidClose(ctx, &protocol.DidCloseTextDocumentParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: baseURI},
+	})
+	assert.NoError(t, err)
+	err = srv.DidChangeWatchedFiles(ctx, &protocol.DidChangeWatchedFilesParams{
+		Changes: []*protocol.FileEvent{
+			{URI: baseURI, Type: protocol.FileChangeTypeChanged},
+		},
+	})
+	assert.NoError(t, err)
+	fh, err = srv.session.ReadFile(ctx, baseURI)
+	assert.NoError(t, err)
+	content, err = fh.Content()
+	assert.NoError(t, err)
+	assert.Equal(t, "struct Base2 {\n}\n", string(content))
+}
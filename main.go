@@ -9,6 +9,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/joyme123/thrift-ls/cmd"
 	"github.com/joyme123/thrift-ls/log"
 	"github.com/joyme123/thrift-ls/lsp"
 	"go.lsp.dev/jsonrpc2"
@@ -23,6 +24,15 @@ type Options struct {
 func main() {
 	rand.Seed(time.Now().UnixMilli())
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "format":
+			os.Exit(cmd.RunFormat(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		case "check":
+			os.Exit(cmd.RunCheck(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
 	opts := configInit()
 	log.Init(opts.LogLevel)
 
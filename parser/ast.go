@@ -226,6 +226,11 @@ func (i *Keyword) ChildrenBadNode() bool {
 	return false
 }
 
+// GetKeyword is promoted to all keyword nodes, such as StructKeyword, LCurKeyword
+func (i *Keyword) GetKeyword() *Keyword {
+	return i
+}
+
 type IncludeKeyword struct {
 	Keyword
 }
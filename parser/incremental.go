@@ -0,0 +1,425 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Reparse parses content which is changed from oldContent. oldDoc and oldErrs are parse result of oldContent.
+// Only top level nodes overlapping changed text are parsed again, and nodes after them are moved to their new
+// positions. Whole content is parsed if changed nodes can't be parsed alone, for example a definition isn't
+// closed after change
+func (p *PEGParser) Reparse(filename string, content []byte, oldDoc *Document, oldContent []byte, oldErrs []error) (*Document, []error) {
+	if doc, errs, ok := p.reparse(filename, content, oldDoc, oldContent, oldErrs, false); ok {
+		return doc, errs
+	}
+
+	return p.Parse(filename, content)
+}
+
+// ReparseInPlace is like Reparse, but nodes after changed text are moved in place instead of copied, so
+// its cost doesn't grow with size of document. oldDoc is owned by caller, it and its nodes must not be
+// used by others after ReparseInPlace is called
+func (p *PEGParser) ReparseInPlace(filename string, content []byte, oldDoc *Document, oldContent []byte, oldErrs []error) (*Document, []error) {
+	if doc, errs, ok := p.reparse(filename, content, oldDoc, oldContent, oldErrs, true); ok {
+		return doc, errs
+	}
+
+	return p.Parse(filename, content)
+}
+
+func (p *PEGParser) reparse(filename string, content []byte, oldDoc *Document, oldContent []byte, oldErrs []error, inPlace bool) (*Document, []error, bool) {
+	if oldDoc == nil {
+		return nil, nil, false
+	}
+
+	// changed text is oldContent[start:oldEnd], and it's replaced by content[start:newEnd]
+	start, oldEnd, newEnd := changedRange(oldContent, content)
+	if start == oldEnd && start == newEnd {
+		return oldDoc, oldErrs, true
+	}
+
+	// comments at end of document are parsed with the last definition
+	nodes := make([]Node, 0, len(oldDoc.Nodes))
+	for _, node := range oldDoc.Nodes {
+		if _, ok := node.(*Comment); !ok {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, nil, false
+	}
+
+	// nodes[first:last+1] overlap changed text. location of node contains its leading spaces
+	first := 0
+	for first < len(nodes)-1 && nodes[first].End().Offset < start {
+		first++
+	}
+	last := len(nodes) - 1
+	for last > 0 && nodes[last].Pos().Offset > oldEnd {
+		last--
+	}
+	if first > last {
+		first, last = last, first
+	}
+
+	// the node after changed nodes is parsed too. changed nodes with syntax error may be recovered by
+	// consuming following text, and it can be found if the next node isn't parsed as before
+	if last < len(nodes)-1 {
+		last++
+	}
+
+	// location of header doesn't contain its leading spaces, so region starts from end of previous node
+	regionStart := 0
+	if first > 0 {
+		regionStart = nodes[first-1].End().Offset
+	}
+	regionEnd := nodes[last].End().Offset
+	if last == len(nodes)-1 {
+		regionEnd = len(oldContent)
+	}
+	// changed text is between two nodes
+	if start < regionStart || oldEnd > regionEnd {
+		return nil, nil, false
+	}
+	toEOF := regionEnd == len(oldContent)
+
+	// nodes around region may be parsed differently if they have syntax error
+	if first > 0 && hasSyntaxError(nodes[first-1]) {
+		return nil, nil, false
+	}
+	if !toEOF && hasSyntaxError(nodes[last+1]) {
+		return nil, nil, false
+	}
+
+	delta := newEnd - oldEnd
+	chunk := content[regionStart : regionEnd+delta]
+	chunkDoc, chunkErrs := p.Parse(filename, chunk)
+	if chunkDoc == nil {
+		return nil, nil, false
+	}
+	if !toEOF {
+		// chunk should be parsed as complete definitions, otherwise its trailing comments belong to
+		// following nodes
+		if len(chunkDoc.Nodes) == 0 || len(chunkDoc.Comments) > 0 ||
+			chunkDoc.Nodes[len(chunkDoc.Nodes)-1].End().Offset != len(chunk) ||
+			hasSyntaxError(chunkDoc.Nodes[len(chunkDoc.Nodes)-1]) {
+			return nil, nil, false
+		}
+	}
+
+	// nodes in chunk are moved to start of region
+	chunkShift := newShifter(positionOf(chunk, 0), positionOf(content, regionStart), regionStart)
+	// nodes after region are moved by changed text
+	afterShift := newShifter(positionOf(oldContent, regionEnd), positionOf(content, regionEnd+delta), delta)
+
+	errs := make([]error, 0)
+	afterErrs := make([]error, 0)
+	for _, err := range oldErrs {
+		pe, ok := err.(*parserError)
+		if !ok {
+			return nil, nil, false
+		}
+		// error at boundary of region may be reported by nodes around region, for example a missing '}'
+		if pe.pos.offset == regionStart || pe.pos.offset == regionEnd {
+			return nil, nil, false
+		}
+		if pe.pos.offset < regionStart {
+			errs = append(errs, pe)
+		} else if pe.pos.offset >= regionEnd && !toEOF {
+			afterErrs = append(afterErrs, afterShift.shiftError(filename, pe))
+		}
+	}
+	for _, err := range chunkErrs {
+		pe, ok := err.(*parserError)
+		if !ok {
+			return nil, nil, false
+		}
+		errs = append(errs, chunkShift.shiftError(filename, pe))
+	}
+	errs = append(errs, afterErrs...)
+
+	newNodes := make([]Node, 0, len(oldDoc.Nodes))
+	newNodes = append(newNodes, nodes[:first]...)
+	for _, node := range chunkDoc.Nodes {
+		// nodes of chunk are new, nobody else uses them
+		chunkShift.shiftInPlace(reflect.ValueOf(node))
+		newNodes = append(newNodes, node)
+	}
+	if !toEOF {
+		for _, node := range oldDoc.Nodes[last+1:] {
+			if inPlace {
+				afterShift.shiftInPlace(reflect.ValueOf(node))
+				newNodes = append(newNodes, node)
+			} else {
+				newNodes = append(newNodes, afterShift.shiftNode(node))
+			}
+		}
+	}
+
+	headers := make([]Header, 0)
+	defs := make([]Definition, 0)
+	comments := make([]*Comment, 0)
+	for _, node := range newNodes {
+		switch node.Type() {
+		case "Comment":
+			comments = append(comments, node.(*Comment))
+		case "Include", "CPPInclude", "Namespace", "BadHeader":
+			// headers should be in front of definitions
+			if len(defs) > 0 || len(comments) > 0 {
+				return nil, nil, false
+			}
+			headers = append(headers, node.(Header))
+		default:
+			def, ok := node.(Definition)
+			if !ok || len(comments) > 0 {
+				return nil, nil, false
+			}
+			defs = append(defs, def)
+		}
+	}
+
+	if len(comments) == 0 {
+		// empty comments of document may be nil or not, keep it the same as parser
+		comments = oldDoc.Comments
+		if toEOF {
+			comments = chunkDoc.Comments
+		}
+	}
+
+	loc := oldDoc.Location
+	if first == 0 {
+		loc.StartPos = chunkShift.shift(chunkDoc.Location.StartPos)
+	}
+	if toEOF {
+		loc.EndPos = chunkShift.shift(chunkDoc.Location.EndPos)
+	} else {
+		loc.EndPos = afterShift.shift(oldDoc.Location.EndPos)
+	}
+
+	doc := NewDocument(headers, defs, comments, loc)
+	doc.Filename = oldDoc.Filename
+
+	if len(errs) == 0 {
+		errs = nil
+	}
+
+	return doc, errs, true
+}
+
+// changedRange returns range of changed text. oldContent[start:oldEnd] is replaced by content[start:newEnd]
+func changedRange(oldContent, content []byte) (start, oldEnd, newEnd int) {
+	for start < len(oldContent) && start < len(content) && oldContent[start] == content[start] {
+		start++
+	}
+	oldEnd, newEnd = len(oldContent), len(content)
+	for oldEnd > start && newEnd > start && oldContent[oldEnd-1] == content[newEnd-1] {
+		oldEnd--
+		newEnd--
+	}
+
+	return start, oldEnd, newEnd
+}
+
+// positionOf returns position of character at offset in the same way as parser. newline character is
+// counted as column 0 of next line
+func positionOf(content []byte, offset int) Position {
+	line := bytes.Count(content[:offset], []byte("\n")) + 1
+	if offset < len(content) && content[offset] == '\n' {
+		return Position{Line: line + 1, Col: 0, Offset: offset}
+	}
+
+	lineStart := bytes.LastIndexByte(content[:offset], '\n') + 1
+	return Position{Line: line, Col: utf8.RuneCount(content[lineStart:offset]) + 1, Offset: offset}
+}
+
+func hasSyntaxError(node Node) bool {
+	return node.IsBadNode() || node.ChildrenBadNode()
+}
+
+// shifter moves positions from one place to another. positions at the same line of from have column changed
+// too, positions at following lines only have line changed
+type shifter struct {
+	from   Position
+	to     Position
+	offset int
+}
+
+func newShifter(from, to Position, offset int) *shifter {
+	return &shifter{from: from, to: to, offset: offset}
+}
+
+func (s *shifter) shift(pos Position) Position {
+	if pos.Line == s.from.Line {
+		pos.Col += s.to.Col - s.from.Col
+	}
+	pos.Line += s.to.Line - s.from.Line
+	pos.Offset += s.offset
+
+	return pos
+}
+
+func (s *shifter) shiftError(filename string, err *parserError) *parserError {
+	pos := s.shift(Position{Line: err.pos.line, Col: err.pos.col, Offset: err.pos.offset})
+
+	// prefix is like "filename:line:col (offset): rule xxx"
+	prefix := fmt.Sprintf("%d:%d (%d)", pos.Line, pos.Col, pos.Offset)
+	if filename != "" {
+		prefix = filename + ":" + prefix
+	}
+	if i := strings.Index(err.prefix, ")"); i >= 0 {
+		prefix += err.prefix[i+1:]
+	}
+
+	return &parserError{
+		Inner:    err.Inner,
+		pos:      position{line: pos.Line, col: pos.Col, offset: pos.Offset},
+		prefix:   prefix,
+		expected: err.expected,
+	}
+}
+
+var positionType = reflect.TypeOf(Position{})
+
+// shiftNode returns a copy of node whose positions are shifted. node may be shared by other documents,
+// so it isn't changed
+func (s *shifter) shiftNode(node Node) Node {
+	copied := make(map[copiedKey]reflect.Value)
+	return s.copyValue(reflect.ValueOf(node), copied).Interface().(Node)
+}
+
+// shiftInPlace shifts positions in v and values it points to. every node is referenced only once in a
+// document, so no position is shifted twice
+func (s *shifter) shiftInPlace(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			s.shiftInPlace(v.Elem())
+		}
+	case reflect.Struct:
+		if v.Type() == positionType {
+			// zero position is used by nodes without location
+			if v.CanAddr() && !v.IsZero() {
+				pos := v.Addr().Interface().(*Position)
+				*pos = s.shift(*pos)
+			}
+			return
+		}
+		for _, i := range positionFields(v.Type()) {
+			s.shiftInPlace(v.Field(i))
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			s.shiftInPlace(v.Index(i))
+		}
+	}
+}
+
+// positionFieldsCache caches indexes of fields which may contain positions by struct type
+var positionFieldsCache sync.Map
+
+// positionFields returns indexes of exported fields of struct type t which may contain positions. fields
+// like names and flags are skipped when positions are shifted
+func positionFields(t reflect.Type) []int {
+	if fields, ok := positionFieldsCache.Load(t); ok {
+		return fields.([]int)
+	}
+
+	fields := make([]int, 0)
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() && mayContainPosition(t.Field(i).Type, make(map[reflect.Type]bool)) {
+			fields = append(fields, i)
+		}
+	}
+	positionFieldsCache.Store(t, fields)
+
+	return fields
+}
+
+func mayContainPosition(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Interface:
+		// dynamic type is unknown
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return mayContainPosition(t.Elem(), visiting)
+	case reflect.Struct:
+		if t == positionType {
+			return true
+		}
+		// recursive type contains position if other fields do
+		if visiting[t] {
+			return false
+		}
+		visiting[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() && mayContainPosition(t.Field(i).Type, visiting) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// copiedKey identifies a copied pointer. pointers of a struct and its first field have the same address
+type copiedKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func (s *shifter) copyValue(v reflect.Value, copied map[copiedKey]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		key := copiedKey{ptr: v.Pointer(), typ: v.Type()}
+		if res, ok := copied[key]; ok {
+			return res
+		}
+		res := reflect.New(v.Type().Elem())
+		copied[key] = res
+		res.Elem().Set(s.copyValue(v.Elem(), copied))
+		return res
+	case reflect.Struct:
+		if v.Type() == positionType {
+			// zero position is used by nodes without location
+			if v.IsZero() {
+				return v
+			}
+			return reflect.ValueOf(s.shift(v.Interface().(Position)))
+		}
+		res := reflect.New(v.Type()).Elem()
+		res.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if res.Field(i).CanSet() {
+				res.Field(i).Set(s.copyValue(v.Field(i), copied))
+			}
+		}
+		return res
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(s.copyValue(v.Index(i), copied))
+		}
+		return res
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(v.Type()).Elem()
+		res.Set(s.copyValue(v.Elem(), copied))
+		return res
+	default:
+		return v
+	}
+}
@@ -0,0 +1,236 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const incrementalDoc = `include "base.thrift"
+include "user.thrift"
+
+namespace go api
+
+const i32 MaxSize = 100 // max size
+const list<string> Names = ["a", "b"]
+
+// User is a user
+struct User {
+    1: required string name (api.query = "name")
+    2: optional i64 id = 1,
+    3: map<string, list<i32>> tags;
+}
+
+enum Status {
+    OK = 1,
+    FAILED = 2
+}
+
+typedef User Admin
+
+union Value { 1: string s; 2: i64 i }
+
+exception Error {
+    1: string message
+}
+
+service Api extends base.Base {
+    User get(1: i64 id) throws (1: Error err)
+    oneway void ping()
+}
+
+// comments at end
+`
+
+func assertReparseEqual(t *testing.T, oldContent, content string) bool {
+	psr := &PEGParser{}
+	wantDoc, wantErrs := psr.Parse("test.thrift", []byte(content))
+
+	incremental := false
+	for _, inPlace := range []bool{false, true} {
+		oldDoc, oldErrs := psr.Parse("test.thrift", []byte(oldContent))
+		doc, errs, ok := psr.reparse("test.thrift", []byte(content), oldDoc, []byte(oldContent), oldErrs, inPlace)
+		if !ok {
+			continue
+		}
+		incremental = true
+
+		assert.Equal(t, wantDoc, doc, "content: %q, in place: %v", content, inPlace)
+		assert.Equal(t, errorStrings(wantErrs), errorStrings(errs), "content: %q, in place: %v", content, inPlace)
+		for i := range errs {
+			assert.Equal(t, fmt.Sprint(wantErrs[i].(ParserError).Pos()), fmt.Sprint(errs[i].(ParserError).Pos()))
+		}
+	}
+
+	return incremental
+}
+
+func errorStrings(errs []error) []string {
+	res := make([]string, 0, len(errs))
+	for _, err := range errs {
+		res = append(res, err.Error())
+	}
+	return res
+}
+
+func TestReparse(t *testing.T) {
+	tests := []struct {
+		name        string
+		old         string
+		new         string
+		incremental bool
+	}{
+		{
+			name:        "rename field",
+			old:         "1: required string name",
+			new:         "1: required string fullName",
+			incremental: true,
+		},
+		{
+			name:        "add field",
+			old:         "    3: map<string, list<i32>> tags;\n",
+			new:         "    3: map<string, list<i32>> tags;\n    4: string 用户\n",
+			incremental: true,
+		},
+		{
+			name:        "add definition",
+			old:         "typedef User Admin\n",
+			new:         "typedef User Admin\n\nconst string A = 'a'\n",
+			incremental: true,
+		},
+		{
+			name:        "change in one line definition",
+			old:         "2: i64 i }",
+			new:         "2: i64 i; 3: double d }",
+			incremental: true,
+		},
+		{
+			name:        "change header",
+			old:         "namespace go api",
+			new:         "namespace go api.v1",
+			incremental: true,
+		},
+		{
+			name:        "change comments at end",
+			old:         "// comments at end",
+			new:         "// comments\n// at end",
+			incremental: true,
+		},
+		{
+			name:        "bad field",
+			old:         "1: string message",
+			new:         "1: string",
+			incremental: true,
+		},
+		{
+			name:        "remove right curly",
+			old:         "    FAILED = 2\n}",
+			new:         "    FAILED = 2\n",
+			incremental: true,
+		},
+		{
+			name:        "unclosed block comment",
+			old:         "typedef User Admin",
+			new:         "/* typedef User Admin",
+			incremental: false,
+		},
+		{
+			name:        "remove definitions",
+			old:         "typedef User Admin\n",
+			new:         "",
+			incremental: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Contains(t, incrementalDoc, tt.old)
+			content := strings.Replace(incrementalDoc, tt.old, tt.new, 1)
+			assert.Equal(t, tt.incremental, assertReparseEqual(t, incrementalDoc, content))
+		})
+	}
+}
+
+func TestReparseRandomEdits(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pieces := []string{"", " ", "\n", "}", "{", "1: string a\n", "struct A {}\n", "// c\n", "i32", ",", "=", "\"", "x"}
+
+	content := incrementalDoc
+	incremental := 0
+	for i := 0; i < 300; i++ {
+		// too many syntax errors make parser slow
+		if i%20 == 0 {
+			content = incrementalDoc
+		}
+		start := r.Intn(len(content) + 1)
+		end := start + r.Intn(5)
+		if end > len(content) {
+			end = len(content)
+		}
+		newContent := content[:start] + pieces[r.Intn(len(pieces))] + content[end:]
+		if assertReparseEqual(t, content, newContent) {
+			incremental++
+		}
+		if t.Failed() {
+			t.Logf("old content: %q", content)
+			return
+		}
+
+		// keep document valid mostly, so following edits are more likely to be incremental
+		if _, errs := (&PEGParser{}).Parse("test.thrift", []byte(newContent)); len(errs) == 0 || r.Intn(4) == 0 {
+			content = newContent
+		}
+	}
+	t.Logf("%d edits are parsed incrementally", incremental)
+}
+
+// largeDoc returns a generated document with n structs
+func largeDoc(n int) string {
+	buf := strings.Builder{}
+	buf.WriteString("include \"base.thrift\"\n\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, "// Struct%d is generated\nstruct Struct%d {\n", i, i)
+		for j := 1; j <= 10; j++ {
+			fmt.Fprintf(&buf, "    %d: optional list<string> field%d (api.query = \"field%d\")\n", j, j, j)
+		}
+		buf.WriteString("}\n\n")
+	}
+	return buf.String()
+}
+
+func BenchmarkReparseFirstLine(b *testing.B) {
+	oldContent := []byte(largeDoc(1000))
+	content := bytes.Replace(oldContent, []byte("base.thrift"), []byte("base2.thrift"), 1)
+	psr := &PEGParser{}
+
+	b.Run("Reparse", func(b *testing.B) {
+		oldDoc, oldErrs := psr.Parse("test.thrift", oldContent)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, _, ok := psr.reparse("test.thrift", content, oldDoc, oldContent, oldErrs, false); !ok {
+				b.Fatal("not parsed incrementally")
+			}
+		}
+	})
+	b.Run("ReparseInPlace", func(b *testing.B) {
+		doc, errs := psr.Parse("test.thrift", oldContent)
+		b.ResetTimer()
+		// document is changed in place, so it's edited back and forth
+		from, to := oldContent, content
+		for i := 0; i < b.N; i++ {
+			var ok bool
+			if doc, errs, ok = psr.reparse("test.thrift", to, doc, from, errs, true); !ok {
+				b.Fatal("not parsed incrementally")
+			}
+			from, to = to, from
+		}
+	})
+	b.Run("Parse", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			psr.Parse("test.thrift", content)
+		}
+	})
+}
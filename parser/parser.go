@@ -1,5 +1,10 @@
 package parser
 
+import (
+	"os"
+	"path/filepath"
+)
+
 type IncludeCall func(include string) (filename string, content []byte, err error)
 
 type Parser interface {
@@ -9,6 +14,10 @@ type Parser interface {
 
 // PEGParser use PEG as a parser implementation
 type PEGParser struct {
+	// IncludeDirs are searched in order for included files which aren't found relative to the including file,
+	// like `thrift -I`
+	IncludeDirs []string
+
 	parsed map[string]struct{}
 }
 
@@ -38,10 +47,42 @@ func (p *PEGParser) Parse(filename string, content []byte) (*Document, []error)
 	return doc.(*Document), nil
 }
 
+// ParseRecursively parses filename and files it includes. Included files are read by call, or read from disk
+// with IncludeDirs if call is nil
 func (p *PEGParser) ParseRecursively(filename string, content []byte, maxDepth int, call IncludeCall) []*ParseResult {
 	return p.parseRecursively(filename, content, 0, maxDepth, call)
 }
 
+// readInclude reads file included by filename from disk
+func (p *PEGParser) readInclude(filename string, include string) (string, []byte, error) {
+	path := filepath.Join(filepath.Dir(filename), include)
+	if !fileExists(path) {
+		if found, ok := SearchIncludeDirs(include, p.IncludeDirs); ok {
+			path = found
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	return path, content, err
+}
+
+// SearchIncludeDirs returns the first existing file of include in includeDirs
+func SearchIncludeDirs(include string, includeDirs []string) (string, bool) {
+	for _, dir := range includeDirs {
+		path := filepath.Join(dir, include)
+		if fileExists(path) {
+			return path, true
+		}
+	}
+
+	return "", false
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
 func (p *PEGParser) parseRecursively(filename string, content []byte, curDepth int, maxDepth int, call IncludeCall) []*ParseResult {
 	if curDepth > maxDepth && maxDepth > 0 {
 		return nil
@@ -60,9 +101,16 @@ func (p *PEGParser) parseRecursively(filename string, content []byte, curDepth i
 			if include.Path == nil || include.Path.ChildrenBadNode() {
 				continue
 			}
-			f, c, err := call(include.Path.Value.Text)
-			if err == nil {
-				errs = append(errs, err)
+			var f string
+			var c []byte
+			var err error
+			if call != nil {
+				f, c, err = call(include.Path.Value.Text)
+			} else {
+				f, c, err = p.readInclude(filename, include.Path.Value.Text)
+			}
+			if err != nil {
+				continue
 			}
 			if _, ok := p.parsed[f]; ok {
 				continue
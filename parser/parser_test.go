@@ -2,6 +2,8 @@ package parser
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -51,3 +53,29 @@ service APIService {
 		}
 	}
 }
+
+func Test_ParseRecursivelyIncludeDirs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"idl/service.thrift": "include \"base.thrift\"\ninclude \"missing.thrift\"\n",
+		"common/base.thrift": "include \"enum.thrift\"\nstruct User {}\n",
+		"common/enum.thrift": "enum Status {}\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	parser := &PEGParser{IncludeDirs: []string{filepath.Join(dir, "common")}}
+	filename := filepath.Join(dir, "idl/service.thrift")
+	parseResult := parser.ParseRecursively(filename, []byte(files["idl/service.thrift"]), 10, nil)
+
+	// missing.thrift isn't found
+	if assert.Len(t, parseResult, 3) {
+		for _, res := range parseResult {
+			assert.Empty(t, res.Errors)
+		}
+		assert.Len(t, parseResult[1].Doc.Structs, 1)
+		assert.Len(t, parseResult[2].Doc.Enums, 1)
+	}
+}